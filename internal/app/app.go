@@ -15,9 +15,11 @@ import (
 	"sentinel2-uploader/internal/config"
 	"sentinel2-uploader/internal/evelogs"
 	"sentinel2-uploader/internal/logging"
+	"sentinel2-uploader/internal/outbox"
 	"sentinel2-uploader/internal/pbrealtime"
 	"sentinel2-uploader/internal/runctx"
 	"sentinel2-uploader/internal/runstatus"
+	"sentinel2-uploader/internal/submitpool"
 )
 
 const heartbeatInterval = 30 * time.Second
@@ -29,6 +31,17 @@ type UploaderApp struct {
 	hooks              Callbacks
 	status             runtimeStatusState
 	lastAPISuccessUnix atomic.Int64
+	outbox             *outbox.Journal
+	outboxKick         chan struct{}
+	offsets            *evelogs.OffsetStore
+	submitPool         atomic.Pointer[submitpool.Pool]
+	filters            atomic.Pointer[evelogs.FilterSet]
+	lastFilterHits     atomic.Uint64
+	metrics            appMetrics
+	// instanceID is loaded on the first submit to a server that accepts
+	// reporter metadata.
+	instanceIDOnce sync.Once
+	instanceID     string
 }
 
 type connectionEventKind string
@@ -65,6 +78,8 @@ func newRealtimeEpochEvent(kind connectionEventKind, epoch uint64) connectionEve
 type Callbacks struct {
 	OnChannelsUpdate func([]client.ChannelConfig)
 	OnStatusChange   func(string)
+	// OnServerMessage shows a message the server pushed for the pilot.
+	OnServerMessage func(string)
 }
 
 func New(opts config.Options, client *client.SentinelClient, logger *logging.Logger, hooks Callbacks) *UploaderApp {
@@ -74,7 +89,13 @@ func New(opts config.Options, client *client.SentinelClient, logger *logging.Log
 	if logger == nil {
 		panic("app.New: logger must not be nil")
 	}
-	return &UploaderApp{opts: opts, client: client, logger: logger, hooks: hooks}
+	return &UploaderApp{
+		opts:       opts,
+		client:     client,
+		logger:     logger,
+		hooks:      hooks,
+		outboxKick: make(chan struct{}, 1),
+	}
 }
 
 func (a *UploaderApp) Run() error {
@@ -116,6 +137,9 @@ func (a *UploaderApp) RunContext(ctx context.Context) error {
 
 	a.logger.Info("uploader app starting",
 		logging.Field("log_dir", a.opts.LogDir),
+		logging.Field("extra_log_dirs", strings.Join(a.opts.ExtraLogDirs, ", ")),
+		logging.Field("character_filter", a.opts.CharacterFilter.Mode),
+		logging.Field("filtered_characters", len(a.opts.CharacterFilter.Characters)),
 		logging.Field("log_file", a.opts.LogFile),
 	)
 
@@ -123,6 +147,11 @@ func (a *UploaderApp) RunContext(ctx context.Context) error {
 		return err
 	}
 
+	a.openOutbox()
+	defer a.closeOutbox()
+	a.openOffsets()
+	defer a.flushOffsets()
+
 	session, err := a.client.FetchRealtimeSession(runCtx)
 	if err != nil {
 		if client.IsUnauthorized(err) {
@@ -136,6 +165,14 @@ func (a *UploaderApp) RunContext(ctx context.Context) error {
 	sessionState := sessionState{}
 	sessionState.setSessionToken(session.Token)
 
+	submitPool := a.newSubmitPool(&sessionState, stopForAuth)
+	submitPool.Start(runCtx)
+	a.submitPool.Store(submitPool)
+	defer func() {
+		runCancel()
+		a.stopSubmitPool(submitPool)
+	}()
+
 	channels, err := a.client.FetchChannels(runCtx, session.Token)
 	if err != nil {
 		return fmt.Errorf("failed to fetch channels: %w", err)
@@ -148,14 +185,18 @@ func (a *UploaderApp) RunContext(ctx context.Context) error {
 	a.notifyChannels(channels)
 
 	monitor := evelogs.NewMonitor(evelogs.MonitorOptions{
-		LogDir:   a.opts.LogDir,
-		LogFile:  a.opts.LogFile,
-		Channels: channels,
+		LogDirs:    a.opts.LogRoots(),
+		LogFile:    a.opts.LogFile,
+		Channels:   channels,
+		Characters: a.opts.CharacterFilter,
+		Filters:    a.buildFilterSet(a.client.ServerFilterRules()),
+		Redactor:   a.buildRedactor(),
+		Offsets:    a.offsets,
 	}, a.logger, evelogs.MonitorCallbacks{
 		OnReport: func(event evelogs.ReportEvent) error {
-			return a.withSessionRetry(runCtx, &sessionState, func(token string) error {
-				return a.client.Submit(runCtx, client.SubmitPayload{Text: event.Line, ChannelID: event.Channel.ID}, token)
-			}, stopForAuth)
+			// Submission runs on per-channel workers so tailing never waits on the network.
+			a.enqueueReport(event)
+			return nil
 		},
 		OnError: func(err error) {
 			a.logger.Warn("log monitor callback error", logging.Field("error", err))
@@ -165,14 +206,17 @@ func (a *UploaderApp) RunContext(ctx context.Context) error {
 			a.notifyChannels(event.Channels)
 		},
 	})
+	a.metrics.monitor.Store(monitor)
 	if err := monitor.Prepare(); err != nil {
 		return err
 	}
 
+	commands := a.builtinCommands(&sessionState, stopForAuth)
 	connected := make(chan struct{}, 1)
 	configUpdates := a.client.StartChannelConfigSync(runCtx, channels, client.SyncHooks{
 		OnConnected: func(topic string, session pbrealtime.Session, epoch uint64) {
 			sessionState.setConnectedSession(session.Token)
+			a.metrics.realtimeConnects.Add(1)
 			a.logger.Info("realtime epoch connected",
 				logging.Field("epoch", epoch),
 				logging.Field("topic", topic),
@@ -223,6 +267,12 @@ func (a *UploaderApp) RunContext(ctx context.Context) error {
 			runCancel()
 		},
 		OnAuthFailure: stopForAuth,
+		OnFilterRules: func(rules []config.FilterRule) {
+			monitor.SetFilters(a.buildFilterSet(rules))
+		},
+		OnCommand: func(cmd client.Command) {
+			a.handleCommand(runCtx, commands, cmd)
+		},
 		ShouldContinueAfterReconnectExhausted: func(lastErr error, maxElapsed time.Duration) bool {
 			lastSuccessUnix := a.lastAPISuccessUnix.Load()
 			if lastSuccessUnix <= 0 {
@@ -262,6 +312,7 @@ func (a *UploaderApp) RunContext(ctx context.Context) error {
 	}
 
 	go a.runHeartbeatLoop(runCtx, &sessionState, stopForAuth)
+	go a.runOutboxLoop(runCtx, &sessionState, stopForAuth)
 
 	runErr := monitor.RunContext(runCtx, monitorUpdates)
 	if reason := getStopReason(); reason != nil {
@@ -467,6 +518,7 @@ func (a *UploaderApp) withSessionRetry(ctx context.Context, state *sessionState,
 
 func (a *UploaderApp) markConnectionHealthy() {
 	a.lastAPISuccessUnix.Store(time.Now().Unix())
+	a.kickOutbox()
 	key := a.status.key()
 	if key == runstatus.KeyDisconnected || key == runstatus.KeyDisconnectedAuth {
 		return
@@ -502,6 +554,8 @@ func (a *UploaderApp) runHeartbeatLoop(ctx context.Context, state *sessionState,
 			if !send() {
 				return
 			}
+			a.logSubmitBackpressure()
+			a.logFilterStats()
 		}
 	}
 }
@@ -525,6 +579,13 @@ func (a *UploaderApp) validateLogDirectory() error {
 	if !info.IsDir() {
 		return fmt.Errorf("log path is not a directory")
 	}
+	// Extra roots are best effort: a missing secondary install should not
+	// stop uploads from the primary one.
+	for _, dir := range config.NormalizeLogDirs(a.opts.ExtraLogDirs) {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			a.logger.Warn("extra log directory is not accessible; skipping", logging.Field("directory", dir))
+		}
+	}
 	return nil
 }
 
@@ -545,6 +606,7 @@ func (a *UploaderApp) forwardChannelUpdates(ctx context.Context, source <-chan [
 }
 
 func (a *UploaderApp) notifyChannels(channels []client.ChannelConfig) {
+	a.metrics.setChannels(channels)
 	if a.hooks.OnChannelsUpdate == nil {
 		return
 	}
@@ -572,3 +634,25 @@ func (a *UploaderApp) applyConnectionEvent(event connectionEvent) {
 	)
 	a.notifyStatus(next)
 }
+
+// FetchChannels authenticates and returns the channels configured on the
+// server without starting the uploader.
+func (a *UploaderApp) FetchChannels(ctx context.Context) ([]client.ChannelConfig, error) {
+	_, channels, err := a.fetchSessionChannels(ctx)
+	return channels, err
+}
+
+func (a *UploaderApp) fetchSessionChannels(ctx context.Context) (string, []client.ChannelConfig, error) {
+	session, err := a.client.FetchRealtimeSession(ctx)
+	if err != nil {
+		if client.IsUnauthorized(err) {
+			return "", nil, fmt.Errorf("%w: %w", ErrAuthenticationFailed, err)
+		}
+		return "", nil, err
+	}
+	channels, err := a.client.FetchChannels(ctx, session.Token)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to fetch channels: %w", err)
+	}
+	return session.Token, channels, nil
+}
@@ -5,13 +5,19 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"sentinel2-uploader/internal/client"
 	"sentinel2-uploader/internal/config"
+	"sentinel2-uploader/internal/evelogs"
 	"sentinel2-uploader/internal/logging"
+	"sentinel2-uploader/internal/mockserver"
+	"sentinel2-uploader/internal/outbox"
 	"sentinel2-uploader/internal/runstatus"
 )
 
@@ -20,7 +26,7 @@ func TestWithSessionRetry_NonRealtimeUnauthorizedFallsBackToLongLivedSession(t *
 	var longLivedCalls atomic.Int32
 	var heartbeatCalls atomic.Int32
 
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	app := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
 		case "/api/uploader/heartbeat":
 			heartbeatCalls.Add(1)
@@ -54,22 +60,11 @@ func TestWithSessionRetry_NonRealtimeUnauthorizedFallsBackToLongLivedSession(t *
 			http.NotFound(w, r)
 			return
 		}
-	}))
-	defer server.Close()
-
-	endpoints, err := config.BuildEndpoints(server.URL)
-	if err != nil {
-		t.Fatalf("BuildEndpoints() error = %v", err)
-	}
-	logger := logging.New(false)
-	logger.SetTerminalOutputEnabled(false)
-
-	statuses := make([]string, 0, 2)
-	app := New(config.Options{}, client.New(server.Client(), "long-lived", endpoints, logger), logger, Callbacks{
-		OnStatusChange: func(status string) {
-			statuses = append(statuses, status)
-		},
 	})
+	statuses := make([]string, 0, 2)
+	app.hooks.OnStatusChange = func(status string) {
+		statuses = append(statuses, status)
+	}
 
 	state := &sessionState{}
 	state.setSessionToken("short-old")
@@ -111,7 +106,7 @@ func TestWithSessionRetry_LongLivedUnauthorizedTriggersAuthFailureAndDisconnect(
 	var longLivedCalls atomic.Int32
 	var heartbeatCalls atomic.Int32
 
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	app := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
 		case "/api/uploader/heartbeat":
 			heartbeatCalls.Add(1)
@@ -129,22 +124,11 @@ func TestWithSessionRetry_LongLivedUnauthorizedTriggersAuthFailureAndDisconnect(
 			http.NotFound(w, r)
 			return
 		}
-	}))
-	defer server.Close()
-
-	endpoints, err := config.BuildEndpoints(server.URL)
-	if err != nil {
-		t.Fatalf("BuildEndpoints() error = %v", err)
-	}
-	logger := logging.New(false)
-	logger.SetTerminalOutputEnabled(false)
-
-	statuses := make([]string, 0, 2)
-	app := New(config.Options{}, client.New(server.Client(), "long-lived", endpoints, logger), logger, Callbacks{
-		OnStatusChange: func(status string) {
-			statuses = append(statuses, status)
-		},
 	})
+	statuses := make([]string, 0, 2)
+	app.hooks.OnStatusChange = func(status string) {
+		statuses = append(statuses, status)
+	}
 
 	state := &sessionState{}
 	state.setConnectedSession("short-old")
@@ -293,3 +277,280 @@ func TestRuntimeStatusState_ReconnectExhaustedBecomesDisconnected(t *testing.T)
 		t.Fatalf("status key = %q, want %q", got, runstatus.KeyDisconnected)
 	}
 }
+
+func TestSubmitReport_QueuesFailedSubmitAndDrainsInOrder(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	var mu sync.Mutex
+	received := []string{}
+
+	app := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/uploader/submit" {
+			http.NotFound(w, r)
+			return
+		}
+		if failing.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		var payload client.SubmitPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		mu.Lock()
+		received = append(received, payload.Text)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	})
+	journal := app.outbox
+
+	state := &sessionState{}
+	state.setSessionToken("short-ok")
+	event := func(text string) evelogs.ReportEvent {
+		return evelogs.ReportEvent{
+			Line:      text,
+			Channel:   client.ChannelConfig{ID: "intel", Name: "Intel"},
+			Timestamp: time.Now().UTC(),
+		}
+	}
+
+	if err := app.submitReport(context.Background(), state, event("first"), nil); err != nil {
+		t.Fatalf("submitReport(first) error = %v, want queued", err)
+	}
+	failing.Store(false)
+	if err := app.submitReport(context.Background(), state, event("second"), nil); err != nil {
+		t.Fatalf("submitReport(second) error = %v", err)
+	}
+	if got := journal.Len(); got != 2 {
+		t.Fatalf("outbox pending = %d, want 2 (second queued behind first)", got)
+	}
+
+	app.drainOutbox(context.Background(), state, nil)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 2 || received[0] != "first" || received[1] != "second" {
+		t.Fatalf("received = %v, want [first second]", received)
+	}
+	if got := journal.Len(); got != 0 {
+		t.Fatalf("outbox pending after drain = %d, want 0", got)
+	}
+}
+
+func TestSubmitReport_DropsRejectedReportInsteadOfQueueing(t *testing.T) {
+	app := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unparseable report", http.StatusUnprocessableEntity)
+	})
+	journal := app.outbox
+
+	state := &sessionState{}
+	state.setSessionToken("short-ok")
+	event := evelogs.ReportEvent{
+		Line:      "[ 2026.02.14 12:00:00 ] Pilot > ???",
+		Channel:   client.ChannelConfig{ID: "intel", Name: "Intel"},
+		Timestamp: time.Now().UTC(),
+	}
+	if err := app.submitReport(context.Background(), state, event, nil); !client.IsRejected(err) {
+		t.Fatalf("submitReport() error = %v, want a rejection", err)
+	}
+	if got := journal.Len(); got != 0 {
+		t.Fatalf("outbox pending = %d, want the rejected report dropped", got)
+	}
+}
+
+func TestSubmitReports_QueuesFailedBatchItemsUnlessRejected(t *testing.T) {
+	app := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/uploader/submit/batch" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"results":[{"ok":true},{"ok":false,"error":"busy","status":503},` +
+			`{"ok":false,"error":"unparseable report","status":422},{"ok":false,"error":"try later"}]}`))
+	})
+	journal := app.outbox
+
+	state := &sessionState{}
+	state.setSessionToken("short-ok")
+	var events []evelogs.ReportEvent
+	for _, text := range []string{"accepted", "busy", "rejected", "unknown"} {
+		events = append(events, evelogs.ReportEvent{
+			Line:      text,
+			Channel:   client.ChannelConfig{ID: "intel", Name: "Intel"},
+			Timestamp: time.Now().UTC(),
+		})
+	}
+	app.submitReports(context.Background(), state, events, nil)
+
+	var queued []string
+	if _, err := journal.Drain(time.Now(), func(entry outbox.Entry) error {
+		queued = append(queued, entry.Text)
+		return nil
+	}); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	if len(queued) != 2 || queued[0] != "busy" || queued[1] != "unknown" {
+		t.Fatalf("queued = %v, want [busy unknown]", queued)
+	}
+}
+
+func TestNewSubmitPayload_AttachesIntelOnlyWhenRecognised(t *testing.T) {
+	payload := newSubmitPayload("[ 2026.02.14 12:00:00 ] Pilot > Amamake gate 5x Sabre", "intel")
+	if payload.Intel == nil {
+		t.Fatalf("Intel = nil, want parsed intel")
+	}
+	if got := payload.Intel.Gates; len(got) != 1 || got[0] != "Amamake" {
+		t.Fatalf("Intel.Gates = %v, want [Amamake]", got)
+	}
+	if got := payload.Intel.Ships; len(got) != 1 || got[0] != (client.ShipCount{Type: "Sabre", Count: 5}) {
+		t.Fatalf("Intel.Ships = %v, want 5x Sabre", got)
+	}
+
+	if payload := newSubmitPayload("[ 2026.02.14 12:00:00 ] Pilot > o7", "intel"); payload.Intel != nil {
+		t.Fatalf("Intel = %#v, want nil for chatter", payload.Intel)
+	}
+	encoded, err := json.Marshal(newSubmitPayload("[ 2026.02.14 12:00:00 ] Pilot > o7", "intel"))
+	if err != nil || strings.Contains(string(encoded), `"intel":`) {
+		t.Fatalf("Marshal() = %s, %v; want no intel object", encoded, err)
+	}
+}
+
+func TestReportPayload_SendsReporterMetadataOnlyWhenAdvertised(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("AppData", t.TempDir())
+	reportTime := time.Date(2026, 2, 14, 12, 0, 0, 0, time.UTC)
+	line := "[ 2026.02.14 12:00:00 ] Pilot > red on gate"
+	event := evelogs.ReportEvent{
+		Line:           line,
+		Channel:        client.ChannelConfig{ID: "intel", Name: "Intel"},
+		CharacterID:    "90000001",
+		Timestamp:      reportTime,
+		ReporterSystem: "HED-GP",
+	}
+	newApp := func(capabilities []string) *UploaderApp {
+		server := mockserver.New(mockserver.Options{
+			UploaderToken: "meta-token",
+			Channels:      []client.ChannelConfig{event.Channel},
+			Capabilities:  capabilities,
+		})
+		t.Cleanup(server.Close)
+		logger := logging.New(false)
+		logger.SetTerminalOutputEnabled(false)
+		endpoints, err := config.BuildEndpoints(server.URL)
+		if err != nil {
+			t.Fatalf("BuildEndpoints() error = %v", err)
+		}
+		opts := config.Options{BaseURL: server.URL, Token: "meta-token"}
+		app := New(opts, client.New(&http.Client{Timeout: 5 * time.Second}, opts.Token, endpoints, logger), logger, Callbacks{})
+		if _, err := app.FetchChannels(context.Background()); err != nil {
+			t.Fatalf("FetchChannels() error = %v", err)
+		}
+		return app
+	}
+
+	encoded, err := json.Marshal(newApp(nil).reportPayload(event))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(encoded, &fields); err != nil || len(fields) != 2 || fields["channel_id"] != "intel" {
+		t.Fatalf("payload for old server = %s, want only text and channel_id", encoded)
+	}
+
+	app := newApp([]string{client.CapabilityReporterMetadata})
+	payload := app.reportPayload(event)
+	if payload.ReporterCharacterID != "90000001" || payload.ReporterSystem != "HED-GP" || payload.ReportTime != "2026-02-14T12:00:00Z" {
+		t.Fatalf("payload = %+v, want reporter metadata", payload)
+	}
+	if payload.UploaderInstanceID == "" {
+		t.Fatal("UploaderInstanceID is empty")
+	}
+	queued := app.outboxPayload(outbox.Entry{
+		ChannelID:      "intel",
+		Text:           line,
+		CharacterID:    event.CharacterID,
+		ReporterSystem: event.ReporterSystem,
+		ReportTime:     reportTime,
+	})
+	if queued.ReporterCharacterID != payload.ReporterCharacterID || queued.ReporterSystem != payload.ReporterSystem ||
+		queued.ReportTime != payload.ReportTime || queued.UploaderInstanceID != payload.UploaderInstanceID {
+		t.Fatalf("outbox payload = %+v, want the same metadata as %+v", queued, payload)
+	}
+	if queued.CharacterID != event.CharacterID || queued.IdempotencyKey() != payload.IdempotencyKey() {
+		t.Fatalf("outbox idempotency key = %q, want %q", queued.IdempotencyKey(), payload.IdempotencyKey())
+	}
+}
+
+func TestCommandRegistry_AllowlistGatesDispatch(t *testing.T) {
+	uploaded := make(chan diagnosticsBundle, 1)
+	app := newTestApp(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/uploader/diagnostics" || r.Header.Get("Authorization") != "Bearer short-ok" {
+			http.NotFound(w, r)
+			return
+		}
+		var bundle diagnosticsBundle
+		if err := json.NewDecoder(r.Body).Decode(&bundle); err != nil {
+			t.Errorf("decode bundle: %v", err)
+		}
+		uploaded <- bundle
+		w.WriteHeader(http.StatusNoContent)
+	})
+	app.opts.RemoteCommands = []string{"Message", "diagnostics"}
+	var messages []string
+	app.hooks.OnServerMessage = func(text string) { messages = append(messages, text) }
+	state := &sessionState{}
+	state.setConnectedSession("short-ok")
+	registry := app.builtinCommands(state, nil)
+	ctx := context.Background()
+
+	if err := registry.dispatch(ctx, client.Command{Name: config.RemoteCommandMessage, Args: json.RawMessage(`{"text":"fleet up"}`)}); err != nil {
+		t.Fatalf("dispatch(message) error = %v", err)
+	}
+	if len(messages) != 1 || messages[0] != "fleet up" {
+		t.Fatalf("messages = %v, want [fleet up]", messages)
+	}
+	if err := registry.dispatch(ctx, client.Command{ID: "cmd-7", Name: config.RemoteCommandDiagnostics}); err != nil {
+		t.Fatalf("dispatch(diagnostics) error = %v", err)
+	}
+	if bundle := <-uploaded; bundle.CommandID != "cmd-7" || bundle.OS == "" {
+		t.Fatalf("bundle = %+v, want command_id cmd-7 and os", bundle)
+	}
+	if err := registry.dispatch(ctx, client.Command{Name: config.RemoteCommandLogLevel, Args: json.RawMessage(`{"level":"debug"}`)}); err != errCommandNotAllowed {
+		t.Fatalf("dispatch(log_level) error = %v, want errCommandNotAllowed", err)
+	}
+	if app.logger.DebugEnabled() {
+		t.Fatal("debug enabled by a command outside the allowlist")
+	}
+	if err := registry.dispatch(ctx, client.Command{Name: "self_destruct"}); err != errCommandUnknown {
+		t.Fatalf("dispatch(unknown) error = %v, want errCommandUnknown", err)
+	}
+
+	app.opts.RemoteCommands = []string{config.RemoteCommandsNone}
+	registry = app.builtinCommands(state, nil)
+	if err := registry.dispatch(ctx, client.Command{Name: config.RemoteCommandMessage, Args: json.RawMessage(`{"text":"x"}`)}); err != errCommandNotAllowed {
+		t.Fatalf("dispatch(message) with none error = %v, want errCommandNotAllowed", err)
+	}
+}
+
+// newTestApp returns an app whose client sends the long-lived token
+// "long-lived" to handler and whose outbox lives in a temporary directory.
+// Tests set the options and hooks they vary on the result.
+func newTestApp(t *testing.T, handler http.HandlerFunc) *UploaderApp {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	endpoints, err := config.BuildEndpoints(server.URL)
+	if err != nil {
+		t.Fatalf("BuildEndpoints() error = %v", err)
+	}
+	logger := logging.New(false)
+	logger.SetTerminalOutputEnabled(false)
+
+	app := New(config.Options{}, client.New(server.Client(), "long-lived", endpoints, logger), logger, Callbacks{})
+	journal, err := outbox.Open(filepath.Join(t.TempDir(), "outbox.jsonl"), time.Hour)
+	if err != nil {
+		t.Fatalf("outbox.Open() error = %v", err)
+	}
+	app.outbox = journal
+	t.Cleanup(app.closeOutbox)
+	return app
+}
@@ -0,0 +1,196 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	goruntime "runtime"
+	"strings"
+	"time"
+
+	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/config"
+	"sentinel2-uploader/internal/logging"
+)
+
+// commandHandler carries out one server-pushed command.
+type commandHandler func(ctx context.Context, cmd client.Command) error
+
+// commandRegistry maps command names to handlers and enforces the pilot's
+// allowlist before dispatch.
+type commandRegistry struct {
+	allow    []string
+	handlers map[string]commandHandler
+}
+
+func newCommandRegistry(allow []string) *commandRegistry {
+	return &commandRegistry{
+		allow:    config.NormalizeRemoteCommands(allow),
+		handlers: make(map[string]commandHandler),
+	}
+}
+
+func (r *commandRegistry) register(name string, handler commandHandler) {
+	r.handlers[name] = handler
+}
+
+var (
+	errCommandUnknown    = errors.New("unknown remote command")
+	errCommandNotAllowed = errors.New("remote command not allowed by settings")
+)
+
+// dispatch runs the handler for cmd if the allowlist permits it.
+func (r *commandRegistry) dispatch(ctx context.Context, cmd client.Command) error {
+	handler, ok := r.handlers[cmd.Name]
+	if !ok {
+		return errCommandUnknown
+	}
+	if !config.RemoteCommandAllowed(r.allow, cmd.Name) {
+		return errCommandNotAllowed
+	}
+	return handler(ctx, cmd)
+}
+
+// builtinCommands returns a registry holding the remote commands the
+// uploader understands, gated by the configured allowlist.
+func (a *UploaderApp) builtinCommands(state *sessionState, onAuthFailure func(error)) *commandRegistry {
+	registry := newCommandRegistry(a.opts.RemoteCommands)
+	registry.register(config.RemoteCommandResubscribe, func(context.Context, client.Command) error {
+		if !a.client.Resubscribe() {
+			return errors.New("no realtime session to resubscribe")
+		}
+		return nil
+	})
+	registry.register(config.RemoteCommandDiagnostics, func(ctx context.Context, cmd client.Command) error {
+		bundle := a.diagnosticsBundle(cmd.ID)
+		return a.withSessionRetry(ctx, state, func(token string) error {
+			return a.client.UploadDiagnostics(ctx, bundle, token)
+		}, onAuthFailure)
+	})
+	registry.register(config.RemoteCommandLogLevel, func(_ context.Context, cmd client.Command) error {
+		args := struct {
+			Level string `json:"level"`
+		}{}
+		if err := decodeCommandArgs(cmd, &args); err != nil {
+			return err
+		}
+		switch strings.ToLower(strings.TrimSpace(args.Level)) {
+		case "debug":
+			a.logger.SetDebugEnabled(true)
+		case "info":
+			a.logger.SetDebugEnabled(false)
+		default:
+			return fmt.Errorf("unsupported log level %q", args.Level)
+		}
+		return nil
+	})
+	registry.register(config.RemoteCommandMessage, func(_ context.Context, cmd client.Command) error {
+		args := struct {
+			Text string `json:"text"`
+		}{}
+		if err := decodeCommandArgs(cmd, &args); err != nil {
+			return err
+		}
+		text := strings.TrimSpace(args.Text)
+		if text == "" {
+			return errors.New("message text is empty")
+		}
+		a.logger.Info("message from server", logging.Field("text", text))
+		if a.hooks.OnServerMessage != nil {
+			a.hooks.OnServerMessage(text)
+		}
+		return nil
+	})
+	return registry
+}
+
+func decodeCommandArgs(cmd client.Command, out any) error {
+	if len(cmd.Args) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(cmd.Args, out); err != nil {
+		return fmt.Errorf("invalid %s args: %w", cmd.Name, err)
+	}
+	return nil
+}
+
+// handleCommand dispatches cmd off the realtime stream goroutine.
+func (a *UploaderApp) handleCommand(ctx context.Context, registry *commandRegistry, cmd client.Command) {
+	go func() {
+		err := registry.dispatch(ctx, cmd)
+		switch {
+		case err == nil:
+			a.logger.Info("remote command applied",
+				logging.Field("id", cmd.ID),
+				logging.Field("command", cmd.Name),
+			)
+		case errors.Is(err, errCommandNotAllowed):
+			a.logger.Info("ignoring remote command: not allowed by settings",
+				logging.Field("id", cmd.ID),
+				logging.Field("command", cmd.Name),
+			)
+		case ctx.Err() != nil:
+			// Shutting down; the failure is expected.
+		default:
+			a.logger.Warn("remote command failed",
+				logging.Field("id", cmd.ID),
+				logging.Field("command", cmd.Name),
+				logging.Field("error", err),
+			)
+		}
+	}()
+}
+
+// diagnosticsBundle is the runtime snapshot uploaded for the diagnostics
+// command. It carries no token and no chat content.
+type diagnosticsBundle struct {
+	CommandID         string    `json:"command_id,omitempty"`
+	GeneratedAt       time.Time `json:"generated_at"`
+	OS                string    `json:"os"`
+	Arch              string    `json:"arch"`
+	GoVersion         string    `json:"go_version"`
+	Status            string    `json:"status"`
+	LastAPISuccess    time.Time `json:"last_api_success,omitzero"`
+	LogDirs           int       `json:"log_dirs"`
+	CharacterFilter   string    `json:"character_filter,omitempty"`
+	RealtimeTransport string    `json:"realtime_transport,omitempty"`
+	Debug             bool      `json:"debug"`
+	SubmitDepth       int       `json:"submit_depth"`
+	SubmitEnqueued    uint64    `json:"submit_enqueued"`
+	SubmitSubmitted   uint64    `json:"submit_submitted"`
+	SubmitOverflowed  uint64    `json:"submit_overflowed"`
+	OutboxPending     int       `json:"outbox_pending"`
+	FilterRules       int       `json:"filter_rules"`
+	FilterDryRun      bool      `json:"filter_dry_run"`
+}
+
+func (a *UploaderApp) diagnosticsBundle(commandID string) diagnosticsBundle {
+	submit := a.SubmitStats()
+	filters := a.FilterStats()
+	bundle := diagnosticsBundle{
+		CommandID:         commandID,
+		GeneratedAt:       time.Now().UTC(),
+		OS:                goruntime.GOOS,
+		Arch:              goruntime.GOARCH,
+		GoVersion:         goruntime.Version(),
+		Status:            a.status.key(),
+		LogDirs:           len(a.opts.LogRoots()),
+		CharacterFilter:   a.opts.CharacterFilter.Mode,
+		RealtimeTransport: a.opts.RealtimeTransport,
+		Debug:             a.logger.DebugEnabled(),
+		SubmitDepth:       submit.Depth,
+		SubmitEnqueued:    submit.Enqueued,
+		SubmitSubmitted:   submit.Submitted,
+		SubmitOverflowed:  submit.Overflowed,
+		FilterRules:       len(filters.Rules),
+		FilterDryRun:      filters.DryRun,
+	}
+	if a.outbox != nil {
+		bundle.OutboxPending = a.outbox.Len()
+	}
+	if unix := a.lastAPISuccessUnix.Load(); unix > 0 {
+		bundle.LastAPISuccess = time.Unix(unix, 0).UTC()
+	}
+	return bundle
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"log/slog"
+	"strings"
+
+	"sentinel2-uploader/internal/config"
+	"sentinel2-uploader/internal/evelogs"
+	"sentinel2-uploader/internal/logging"
+)
+
+// buildFilterSet combines the locally configured filter rules with any the
+// server pushed. Local rules come first so they are evaluated first.
+func (a *UploaderApp) buildFilterSet(server []config.FilterRule) *evelogs.FilterSet {
+	rules := make([]evelogs.FilterRule, 0, len(a.opts.FilterRules)+len(server))
+	rules = append(rules, monitorFilterRules(a.opts.FilterRules)...)
+	rules = append(rules, monitorFilterRules(server)...)
+	set, err := evelogs.NewFilterSet(rules, a.opts.FilterDryRun)
+	if err != nil {
+		a.logger.Warn("ignoring invalid filter rules", logging.Field("error", err))
+	}
+	if set.Len() > 0 {
+		a.logger.Info("filter rules loaded",
+			logging.Field("local", len(a.opts.FilterRules)),
+			logging.Field("server", len(server)),
+			logging.Field("active", set.Len()),
+			logging.Field("dry_run", set.DryRun()),
+		)
+	}
+	a.filters.Store(set)
+	a.lastFilterHits.Store(0)
+	return set
+}
+
+func monitorFilterRules(rules []config.FilterRule) []evelogs.FilterRule {
+	out := make([]evelogs.FilterRule, 0, len(rules))
+	for _, rule := range rules {
+		action := evelogs.FilterExclude
+		if strings.EqualFold(strings.TrimSpace(rule.Action), config.FilterActionInclude) {
+			action = evelogs.FilterInclude
+		}
+		out = append(out, evelogs.FilterRule{
+			Name:     strings.TrimSpace(rule.Name),
+			Action:   action,
+			Author:   rule.Author,
+			Message:  rule.Message,
+			Channels: rule.Channels,
+		})
+	}
+	return out
+}
+
+// FilterStats reports how many lines each filter rule matched. In dry-run
+// mode these are the lines the rules would have dropped.
+func (a *UploaderApp) FilterStats() evelogs.FilterStats {
+	return a.filters.Load().Stats()
+}
+
+// logFilterStats logs per-rule counters whenever they moved since the last
+// call.
+func (a *UploaderApp) logFilterStats() {
+	stats := a.FilterStats()
+	total := stats.NoInclude
+	for _, rule := range stats.Rules {
+		total += rule.Hits
+	}
+	if total == a.lastFilterHits.Swap(total) {
+		return
+	}
+	fields := []slog.Attr{
+		logging.Field("dry_run", stats.DryRun),
+		logging.Field("no_include_match", stats.NoInclude),
+	}
+	for _, rule := range stats.Rules {
+		fields = append(fields, logging.Field(rule.Action.String()+":"+rule.Name, rule.Hits))
+	}
+	a.logger.Info("filter rule counters", fields...)
+}
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/evelogs"
+	"sentinel2-uploader/internal/intel"
+)
+
+func newSubmitPayload(line string, channelID string) client.SubmitPayload {
+	return client.SubmitPayload{Text: line, ChannelID: channelID, Intel: reportIntel(line)}
+}
+
+// reportIntel extracts structured intel from the message part of line, or
+// returns nil when the line is not a report or nothing was recognised.
+func reportIntel(line string) *client.ReportIntel {
+	report, ok := evelogs.ParseReportLine(line)
+	if !ok {
+		return nil
+	}
+	parsed := intel.Parse(report.Message)
+	if parsed.Empty() {
+		return nil
+	}
+	ships := make([]client.ShipCount, 0, len(parsed.Ships))
+	for _, ship := range parsed.Ships {
+		ships = append(ships, client.ShipCount{Type: ship.Type, Count: ship.Count})
+	}
+	return &client.ReportIntel{
+		Systems:  parsed.Systems,
+		Ships:    ships,
+		Pilots:   parsed.Pilots,
+		Clear:    parsed.Clear,
+		NoVisual: parsed.NoVisual,
+		Gates:    parsed.Gates,
+	}
+}
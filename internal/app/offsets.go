@@ -0,0 +1,42 @@
+package app
+
+import (
+	"time"
+
+	"sentinel2-uploader/internal/evelogs"
+	"sentinel2-uploader/internal/logging"
+)
+
+// openOffsets loads saved tailer offsets so the monitor can pick up where the
+// previous run stopped. A negative ResumeMaxAge turns resuming off.
+func (a *UploaderApp) openOffsets() {
+	a.offsets = nil
+	if a.opts.ResumeMaxAge < 0 {
+		return
+	}
+	path, err := evelogs.DefaultOffsetStorePath()
+	if err != nil {
+		a.logger.Warn("log resume disabled: cache directory unavailable", logging.Field("error", err))
+		return
+	}
+	store, err := evelogs.OpenOffsetStore(path, a.opts.ResumeMaxAge)
+	if store == nil {
+		a.logger.Warn("log resume disabled: failed to open offset state",
+			logging.Field("path", path),
+			logging.Field("error", err),
+		)
+		return
+	}
+	if err != nil {
+		a.logger.Warn("log offset state reset", logging.Field("error", err))
+	}
+	a.offsets = store
+}
+
+// flushOffsets saves offsets settled after the monitor stopped, while the
+// submit pool drained.
+func (a *UploaderApp) flushOffsets() {
+	if err := a.offsets.Flush(time.Now()); err != nil {
+		a.logger.Warn("failed to save log offsets", logging.Field("path", a.offsets.Path()), logging.Field("error", err))
+	}
+}
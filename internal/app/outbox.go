@@ -0,0 +1,158 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/evelogs"
+	"sentinel2-uploader/internal/logging"
+	"sentinel2-uploader/internal/outbox"
+)
+
+func (a *UploaderApp) openOutbox() {
+	path, err := outbox.DefaultPath()
+	if err != nil {
+		a.logger.Warn("report outbox disabled: cache directory unavailable", logging.Field("error", err))
+		return
+	}
+	journal, err := outbox.Open(path, a.opts.OutboxMaxAge)
+	if err != nil {
+		a.logger.Warn("report outbox disabled: failed to open journal",
+			logging.Field("path", path),
+			logging.Field("error", err),
+		)
+		return
+	}
+	a.outbox = journal
+	if pending := journal.Len(); pending > 0 {
+		a.logger.Info("report outbox has pending reports from a previous run",
+			logging.Field("path", path),
+			logging.Field("pending", pending),
+		)
+	}
+}
+
+func (a *UploaderApp) closeOutbox() {
+	if a.outbox == nil {
+		return
+	}
+	if err := a.outbox.Close(); err != nil {
+		a.logger.Debug("failed to close report outbox", logging.Field("error", err))
+	}
+}
+
+func (a *UploaderApp) submitReport(ctx context.Context, state *sessionState, event evelogs.ReportEvent, onAuthFailure func(error)) error {
+	if a.outbox != nil && a.outbox.Len() > 0 {
+		// Queue behind reports still waiting for delivery so the server
+		// receives intel in the order it was written.
+		a.kickOutbox()
+		return a.queueInOutbox(event, nil)
+	}
+	err := a.submitPayload(ctx, state, a.reportPayload(event), onAuthFailure)
+	if err == nil || client.IsRejected(err) {
+		// A report the server refused would fail again and hold up every
+		// report queued behind it.
+		event.Settle()
+		return err
+	}
+	if a.outbox == nil {
+		return err
+	}
+	return a.queueInOutbox(event, err)
+}
+
+func (a *UploaderApp) queueInOutbox(event evelogs.ReportEvent, submitErr error) error {
+	entry, err := a.outbox.Append(outbox.Entry{
+		ChannelID:      event.Channel.ID,
+		Text:           event.Line,
+		CharacterID:    event.CharacterID,
+		ReporterSystem: event.ReporterSystem,
+		ReportTime:     event.Timestamp,
+	})
+	if err != nil {
+		a.logger.Warn("failed to queue report in outbox", logging.Field("error", err))
+		if submitErr != nil {
+			return submitErr
+		}
+		return err
+	}
+	event.Settle()
+	if submitErr != nil {
+		a.logger.Warn("report submit failed; queued in outbox",
+			logging.Field("channel_id", entry.ChannelID),
+			logging.Field("seq", entry.Seq),
+			logging.Field("error", submitErr),
+		)
+	} else {
+		a.logger.Debug("report queued behind pending outbox entries",
+			logging.Field("channel_id", entry.ChannelID),
+			logging.Field("seq", entry.Seq),
+		)
+	}
+	return nil
+}
+
+func (a *UploaderApp) kickOutbox() {
+	select {
+	case a.outboxKick <- struct{}{}:
+	default:
+	}
+}
+
+func (a *UploaderApp) runOutboxLoop(ctx context.Context, state *sessionState, onAuthFailure func(error)) {
+	if a.outbox == nil {
+		return
+	}
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	a.drainOutbox(ctx, state, onAuthFailure)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-a.outboxKick:
+			a.drainOutbox(ctx, state, onAuthFailure)
+		case <-ticker.C:
+			a.drainOutbox(ctx, state, onAuthFailure)
+		}
+	}
+}
+
+func (a *UploaderApp) drainOutbox(ctx context.Context, state *sessionState, onAuthFailure func(error)) {
+	if a.outbox == nil || a.outbox.Len() == 0 || ctx.Err() != nil {
+		return
+	}
+	result, err := a.outbox.Drain(time.Now(), func(entry outbox.Entry) error {
+		err := a.submitPayload(ctx, state, a.outboxPayload(entry), onAuthFailure)
+		if client.IsRejected(err) {
+			a.logger.Warn("queued report rejected by server; dropped",
+				logging.Field("channel_id", entry.ChannelID),
+				logging.Field("seq", entry.Seq),
+				logging.Field("error", err),
+			)
+			return fmt.Errorf("%w: %w", outbox.ErrRejected, err)
+		}
+		return err
+	})
+	if result.Delivered > 0 || result.Expired > 0 || result.Rejected > 0 {
+		a.logger.Info("report outbox drained",
+			logging.Field("delivered", result.Delivered),
+			logging.Field("expired", result.Expired),
+			logging.Field("rejected", result.Rejected),
+			logging.Field("remaining", result.Remaining),
+		)
+	}
+	if err != nil && ctx.Err() == nil {
+		a.logger.Debug("report outbox drain paused", logging.Field("error", err), logging.Field("remaining", result.Remaining))
+	}
+}
+
+func (a *UploaderApp) outboxPayload(entry outbox.Entry) client.SubmitPayload {
+	payload := newSubmitPayload(entry.Text, entry.ChannelID)
+	payload.CharacterID = entry.CharacterID
+	a.addReporterMetadata(&payload, entry.CharacterID, entry.ReporterSystem, entry.ReportTime)
+	return payload
+}
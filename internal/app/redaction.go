@@ -0,0 +1,45 @@
+package app
+
+import (
+	"sentinel2-uploader/internal/config"
+	"sentinel2-uploader/internal/evelogs"
+	"sentinel2-uploader/internal/logging"
+)
+
+// buildRedactor assembles the built-in URL and email rules, when enabled,
+// followed by the custom rules from settings.
+func (a *UploaderApp) buildRedactor() *evelogs.Redactor {
+	rules := make([]evelogs.RedactionRule, 0, len(a.opts.RedactionRules)+2)
+	if a.opts.RedactURLs {
+		rules = append(rules, evelogs.URLRedactionRule())
+	}
+	if a.opts.RedactEmails {
+		rules = append(rules, evelogs.EmailRedactionRule())
+	}
+	rules = append(rules, monitorRedactionRules(a.opts.RedactionRules)...)
+	redactor, err := evelogs.NewRedactor(rules)
+	if err != nil {
+		a.logger.Warn("ignoring invalid redaction rules", logging.Field("error", err))
+	}
+	if redactor.Len() > 0 {
+		a.logger.Info("redaction enabled",
+			logging.Field("urls", a.opts.RedactURLs),
+			logging.Field("emails", a.opts.RedactEmails),
+			logging.Field("custom", len(a.opts.RedactionRules)),
+			logging.Field("active", redactor.Len()),
+		)
+	}
+	return redactor
+}
+
+func monitorRedactionRules(rules []config.RedactionRule) []evelogs.RedactionRule {
+	out := make([]evelogs.RedactionRule, 0, len(rules))
+	for _, rule := range rules {
+		out = append(out, evelogs.RedactionRule{
+			Name:        rule.Name,
+			Pattern:     rule.Pattern,
+			Placeholder: rule.Placeholder,
+		})
+	}
+	return out
+}
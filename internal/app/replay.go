@@ -0,0 +1,113 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"sentinel2-uploader/internal/evelogs"
+	"sentinel2-uploader/internal/logging"
+)
+
+const defaultReplayRate = 5.0
+
+// ReplayOptions configures a backfill of historical chat logs.
+type ReplayOptions struct {
+	// Paths are chat log files or directories of them.
+	Paths []string
+	// From and To bound report times, inclusive; zero leaves a side open.
+	From time.Time
+	To   time.Time
+	// DryRun only collects the reports.
+	DryRun bool
+	// Rate caps submissions per second (default 5).
+	Rate float64
+}
+
+type ReplayResult struct {
+	// Reports are the collected reports in submit order.
+	Reports   []evelogs.ReportEvent
+	Submitted int
+	Failed    int
+}
+
+// Replay submits the reports found in historical chat logs, oldest first and
+// paced by opts.Rate. Reports keep their original lines, and with them their
+// original timestamps. Failed submits are counted and skipped; an
+// authentication failure aborts the replay.
+func (a *UploaderApp) Replay(ctx context.Context, opts ReplayOptions) (ReplayResult, error) {
+	var result ReplayResult
+	token, channels, err := a.fetchSessionChannels(ctx)
+	if err != nil {
+		return result, err
+	}
+	if len(channels) == 0 {
+		return result, fmt.Errorf("no channels configured")
+	}
+
+	events, err := evelogs.CollectReplay(evelogs.ReplayOptions{
+		Paths:      opts.Paths,
+		From:       opts.From,
+		To:         opts.To,
+		Channels:   channels,
+		Characters: a.opts.CharacterFilter,
+		Filters:    a.buildFilterSet(a.client.ServerFilterRules()),
+		Redactor:   a.buildRedactor(),
+	}, a.logger)
+	if err != nil {
+		return result, err
+	}
+	result.Reports = events
+	a.logger.Info("replay collected reports",
+		logging.Field("reports", len(events)),
+		logging.Field("dry_run", opts.DryRun),
+	)
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	rate := opts.Rate
+	if rate <= 0 {
+		rate = defaultReplayRate
+	}
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+	defer ticker.Stop()
+
+	state := sessionState{}
+	state.setSessionToken(token)
+	var authErr error
+	onAuthFailure := func(err error) { authErr = err }
+	for i, event := range events {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return result, ctx.Err()
+			case <-ticker.C:
+			}
+		}
+		err := a.submitPayload(ctx, &state, a.reportPayload(event), onAuthFailure)
+		if authErr != nil {
+			return result, fmt.Errorf("%w: %w", ErrAuthenticationFailed, authErr)
+		}
+		if err != nil {
+			if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+			result.Failed++
+			a.logger.Warn("replay submit failed",
+				logging.Field("channel_id", event.Channel.ID),
+				logging.Field("report_time", event.Timestamp),
+				logging.Field("error", err),
+			)
+			continue
+		}
+		result.Submitted++
+	}
+	a.logger.Info("replay finished",
+		logging.Field("submitted", result.Submitted),
+		logging.Field("failed", result.Failed),
+	)
+	return result, nil
+}
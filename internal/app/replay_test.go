@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/config"
+	"sentinel2-uploader/internal/evelogs/chatlogtest"
+	"sentinel2-uploader/internal/logging"
+	"sentinel2-uploader/internal/mockserver"
+)
+
+func TestReplay_SubmitsInOrderOrPrintsOnDryRun(t *testing.T) {
+	server := mockserver.New(mockserver.Options{
+		UploaderToken: "replay-token",
+		Channels:      []client.ChannelConfig{{ID: "intel", Name: "Intel"}},
+	})
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	started := time.Date(2026, 2, 16, 19, 0, 0, 0, time.UTC)
+	log, err := chatlogtest.Open(dir, chatlogtest.Session{Channel: "Intel", ChannelID: "-100", Listener: "Pilot", CharacterID: "90000001"}, started)
+	if err != nil {
+		t.Fatalf("open log: %v", err)
+	}
+	var want []string
+	for i, message := range []string{"1DQ1-A 5x Sabre", "HED-GP +20", "Y-2ANO clr"} {
+		at := started.Add(time.Duration(i+1) * time.Minute)
+		if err := log.Say(at, "Scout", message); err != nil {
+			t.Fatalf("say: %v", err)
+		}
+		want = append(want, submitted("intel", chatlogtest.Line(at, "Scout", message)))
+	}
+
+	logger := logging.New(false)
+	logger.SetTerminalOutputEnabled(false)
+	endpoints, err := config.BuildEndpoints(server.URL)
+	if err != nil {
+		t.Fatalf("BuildEndpoints() error = %v", err)
+	}
+	opts := config.Options{BaseURL: server.URL, Token: "replay-token"}
+	app := New(opts, client.New(&http.Client{Timeout: 5 * time.Second}, opts.Token, endpoints, logger), logger, Callbacks{})
+
+	result, err := app.Replay(context.Background(), ReplayOptions{Paths: []string{dir}, DryRun: true})
+	if err != nil {
+		t.Fatalf("Replay(dry run) error = %v", err)
+	}
+	if len(result.Reports) != 3 || len(server.Submissions()) != 0 {
+		t.Fatalf("dry run: reports = %d, submissions = %d; want 3 and none", len(result.Reports), len(server.Submissions()))
+	}
+	var collected []string
+	for _, event := range result.Reports {
+		collected = append(collected, submitted(event.Channel.ID, event.Line))
+	}
+	if !slices.Equal(collected, want) {
+		t.Fatalf("dry run reports:\n got: %q\nwant: %q", collected, want)
+	}
+
+	begin := time.Now()
+	result, err = app.Replay(context.Background(), ReplayOptions{Paths: []string{log.Path()}, Rate: 20})
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if result.Submitted != 3 || result.Failed != 0 {
+		t.Fatalf("result = %+v, want 3 submitted", result)
+	}
+	if elapsed := time.Since(begin); elapsed < 2*50*time.Millisecond {
+		t.Fatalf("replay took %s, want pacing of 50ms between submits", elapsed)
+	}
+	if got := submissionKeys(server.Submissions()); !slices.Equal(got, want) {
+		t.Fatalf("submissions:\n got: %q\nwant: %q", got, want)
+	}
+}
@@ -0,0 +1,277 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/config"
+	"sentinel2-uploader/internal/evelogs/chatlogtest"
+	"sentinel2-uploader/internal/logging"
+	"sentinel2-uploader/internal/mockserver"
+	"sentinel2-uploader/internal/runstatus"
+)
+
+// scenarioSettle is how long a scenario waits for fsnotify to deliver a new
+// log file before the fake client writes to it, and how long it watches for
+// stray submissions at the end.
+const scenarioSettle = 300 * time.Millisecond
+
+// scenario runs UploaderApp against the mock server and a temp directory of
+// synthetic UTF-16 chat logs.
+type scenario struct {
+	t      *testing.T
+	dir    string
+	server *mockserver.Server
+	app    *UploaderApp
+	logs   map[string]*chatlogtest.Log
+	want   []string
+	now    time.Time
+}
+
+type scenarioStep struct {
+	name string
+	// after is the pause before the step, on top of waiting for the
+	// previous step's submissions.
+	after time.Duration
+	// do drives the fake client and returns the submissions the step must
+	// cause, as built by submitted.
+	do func(s *scenario) []string
+}
+
+func newScenario(t *testing.T, channels []client.ChannelConfig) *scenario {
+	t.Helper()
+	// Keep the outbox and offset state out of the real cache directory.
+	cache := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cache)
+	t.Setenv("HOME", cache)
+	t.Setenv("LocalAppData", cache)
+
+	server := mockserver.New(mockserver.Options{
+		UploaderToken: "scenario-token",
+		Channels:      channels,
+		RetryDelay:    10 * time.Millisecond,
+	})
+	t.Cleanup(server.Close)
+	return &scenario{
+		t:      t,
+		dir:    t.TempDir(),
+		server: server,
+		logs:   make(map[string]*chatlogtest.Log),
+		now:    time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+// open starts a log for a character in a channel. key names it for later
+// steps.
+func (s *scenario) open(key string, session chatlogtest.Session, started time.Time) {
+	s.t.Helper()
+	log, err := chatlogtest.Open(s.dir, session, started)
+	if err != nil {
+		s.t.Fatalf("open %s: %v", key, err)
+	}
+	s.logs[key] = log
+}
+
+// say appends a line to a log and returns it as the monitor will submit it.
+func (s *scenario) say(key string, author string, message string) string {
+	s.t.Helper()
+	s.now = s.now.Add(time.Second)
+	if err := s.logs[key].Say(s.now, author, message); err != nil {
+		s.t.Fatalf("write %s: %v", key, err)
+	}
+	return chatlogtest.Line(s.now, author, message)
+}
+
+// start runs the uploader until the test ends and waits for it to connect.
+func (s *scenario) start() {
+	s.t.Helper()
+	logger := logging.New(false)
+	logger.SetTerminalOutputEnabled(false)
+	endpoints, err := config.BuildEndpoints(s.server.URL)
+	if err != nil {
+		s.t.Fatalf("BuildEndpoints() error = %v", err)
+	}
+	opts := config.Options{
+		BaseURL:      s.server.URL,
+		Token:        "scenario-token",
+		LogDir:       s.dir,
+		ResumeMaxAge: -1,
+	}
+	connected := make(chan struct{}, 1)
+	app := New(opts, client.New(&http.Client{Timeout: 5 * time.Second}, opts.Token, endpoints, logger), logger, Callbacks{
+		OnStatusChange: func(status string) {
+			if runstatus.Key(status) == runstatus.KeyConnected {
+				select {
+				case connected <- struct{}{}:
+				default:
+				}
+			}
+		},
+	})
+
+	s.app = app
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- app.RunContext(ctx) }()
+	s.t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			s.t.Errorf("RunContext() error = %v", err)
+		}
+	})
+
+	select {
+	case <-connected:
+	case err := <-done:
+		s.t.Fatalf("RunContext() exited before connecting: %v", err)
+	case <-time.After(10 * time.Second):
+		s.t.Fatal("uploader did not connect")
+	}
+}
+
+// run plays steps in order. After each step it waits for the submissions
+// it should cause, and at the end checks nothing else arrived.
+func (s *scenario) run(steps ...scenarioStep) {
+	s.t.Helper()
+	for _, step := range steps {
+		if step.after > 0 {
+			time.Sleep(step.after)
+		}
+		s.want = append(s.want, step.do(s)...)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		got, err := s.server.WaitForSubmissions(ctx, len(s.want))
+		cancel()
+		if err != nil {
+			s.t.Fatalf("step %q: got %d submissions, want %d:\n got: %q\nwant: %q", step.name, len(got), len(s.want), submissionKeys(got), s.want)
+		}
+	}
+	time.Sleep(scenarioSettle)
+	if got := submissionKeys(s.server.Submissions()); !slices.Equal(got, s.want) {
+		s.t.Fatalf("submissions:\n got: %q\nwant: %q", got, s.want)
+	}
+}
+
+// submitted is the key run compares submissions by.
+func submitted(channelID string, line string) string {
+	return channelID + "|" + line
+}
+
+func submissionKeys(payloads []client.SubmitPayload) []string {
+	keys := make([]string, len(payloads))
+	for i, payload := range payloads {
+		keys[i] = submitted(payload.ChannelID, payload.Text)
+	}
+	return keys
+}
+
+func TestScenario_ChatLogsToSubmissions(t *testing.T) {
+	intel := client.ChannelConfig{ID: "intel", Name: "Intel"}
+	staging := client.ChannelConfig{ID: "staging", Name: "Staging"}
+	s := newScenario(t, []client.ChannelConfig{intel})
+
+	pilotA := chatlogtest.Session{Channel: "Intel", ChannelID: "-100", Listener: "Pilot A", CharacterID: "90000001"}
+	pilotB := chatlogtest.Session{Channel: "Intel", ChannelID: "-100", Listener: "Pilot B", CharacterID: "90000002"}
+	stagingA := chatlogtest.Session{Channel: "Staging", ChannelID: "-200", Listener: "Pilot A", CharacterID: "90000001"}
+	sessionStart := s.now.Add(-time.Hour)
+	s.open("intel-a", pilotA, sessionStart)
+	s.open("intel-b", pilotB, sessionStart)
+	s.open("staging-a", stagingA, sessionStart)
+
+	// History older than the startup lookback is not uploaded; a report from
+	// just before startup is.
+	s.now = s.now.Add(-30 * time.Minute)
+	s.say("intel-a", "Scout", "1DQ1-A gate 3x Sabre")
+	s.say("staging-a", "FC", "form up")
+	s.now = s.now.Add(30*time.Minute - 10*time.Second)
+	recent := s.say("intel-b", "Scout", "NOL-M9 nv")
+	s.now = s.now.Add(10 * time.Second)
+
+	s.start()
+
+	var shared, undock string
+	s.run(
+		scenarioStep{
+			name: "recent lines are sent at startup",
+			do: func(s *scenario) []string {
+				return []string{submitted(intel.ID, recent)}
+			},
+		},
+		scenarioStep{
+			name: "report from one character",
+			do: func(s *scenario) []string {
+				shared = s.say("intel-a", "Scout", "HED-GP +5")
+				return []string{submitted(intel.ID, shared)}
+			},
+		},
+		scenarioStep{
+			name: "second character logging the same line is deduplicated",
+			do: func(s *scenario) []string {
+				if err := s.logs["intel-b"].Append(shared + "\r\n"); err != nil {
+					t.Fatalf("append: %v", err)
+				}
+				return nil
+			},
+		},
+		scenarioStep{
+			name: "system and blank messages are ignored",
+			do: func(s *scenario) []string {
+				s.say("intel-a", "EVE System", "Channel MOTD: stay safe")
+				s.say("intel-b", "Scout", " ")
+				return nil
+			},
+		},
+		scenarioStep{
+			name: "unconfigured channel is not uploaded",
+			do: func(s *scenario) []string {
+				undock = s.say("staging-a", "FC", "undock")
+				return nil
+			},
+		},
+		scenarioStep{
+			name: "downtime rotation switches to the new file",
+			do: func(s *scenario) []string {
+				if err := s.logs["intel-a"].Rotate(chatlogtest.NextDowntime(s.now)); err != nil {
+					t.Fatalf("rotate: %v", err)
+				}
+				time.Sleep(scenarioSettle)
+				return []string{submitted(intel.ID, s.say("intel-a", "Scout", "Y-2ANO clr"))}
+			},
+		},
+		scenarioStep{
+			name: "channel added over realtime catches up only the lookback window",
+			do: func(s *scenario) []string {
+				s.server.SetChannels([]client.ChannelConfig{intel, staging})
+				time.Sleep(scenarioSettle)
+				return []string{
+					submitted(staging.ID, undock),
+					submitted(staging.ID, s.say("staging-a", "FC", "align to HED-GP")),
+				}
+			},
+		},
+		scenarioStep{
+			name: "channel removed over realtime stops uploading",
+			do: func(s *scenario) []string {
+				s.server.SetChannels([]client.ChannelConfig{staging})
+				time.Sleep(scenarioSettle)
+				s.say("intel-b", "Scout", "HED-GP clr")
+				return []string{submitted(staging.ID, s.say("staging-a", "FC", "warp"))}
+			},
+		},
+	)
+
+	metrics := s.app.Metrics()
+	if want := uint64(len(s.server.Submissions())); metrics.SubmitsOK != want || metrics.SubmitsFailed != 0 {
+		t.Fatalf("submit metrics = %d ok, %d failed; want %d ok", metrics.SubmitsOK, metrics.SubmitsFailed, want)
+	}
+	if metrics.DedupHits != 1 || metrics.LinesParsed == 0 || metrics.Reconnects != 0 {
+		t.Fatalf("metrics = %+v, want one dedup hit and no reconnects", metrics)
+	}
+	if status := s.app.Status(); runstatus.Key(status.Status) != runstatus.KeyConnected || status.RealtimeEpoch == 0 || status.LastAPISuccess.IsZero() {
+		t.Fatalf("status = %+v", status)
+	}
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/evelogs"
+)
+
+// Status is a point-in-time view of a running uploader.
+type Status struct {
+	Status         string
+	RealtimeEpoch  uint64
+	LastAPISuccess time.Time
+	Channels       []client.ChannelConfig
+	LogDirs        []string
+}
+
+// Metrics are counters since the uploader started.
+type Metrics struct {
+	SubmitsOK     uint64
+	SubmitsFailed uint64
+	// Reconnects counts realtime connections after the first one.
+	Reconnects  uint64
+	LinesParsed uint64
+	DedupHits   uint64
+}
+
+type appMetrics struct {
+	submitsOK        atomic.Uint64
+	submitsFailed    atomic.Uint64
+	realtimeConnects atomic.Uint64
+	monitor          atomic.Pointer[evelogs.Monitor]
+
+	mu       sync.Mutex
+	channels []client.ChannelConfig
+}
+
+func (m *appMetrics) countSubmit(err error) {
+	if err != nil {
+		m.submitsFailed.Add(1)
+		return
+	}
+	m.submitsOK.Add(1)
+}
+
+func (m *appMetrics) setChannels(channels []client.ChannelConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.channels = append([]client.ChannelConfig(nil), channels...)
+}
+
+// Status reports the connection state, known channels and log roots.
+func (a *UploaderApp) Status() Status {
+	a.status.mu.Lock()
+	status := Status{
+		Status:        a.status.current,
+		RealtimeEpoch: a.status.realtimeEpoch,
+	}
+	a.status.mu.Unlock()
+	if unix := a.lastAPISuccessUnix.Load(); unix > 0 {
+		status.LastAPISuccess = time.Unix(unix, 0)
+	}
+	a.metrics.mu.Lock()
+	status.Channels = append([]client.ChannelConfig(nil), a.metrics.channels...)
+	a.metrics.mu.Unlock()
+	status.LogDirs = a.opts.LogRoots()
+	return status
+}
+
+// Metrics reports the submit, realtime and log monitor counters.
+func (a *UploaderApp) Metrics() Metrics {
+	metrics := Metrics{
+		SubmitsOK:     a.metrics.submitsOK.Load(),
+		SubmitsFailed: a.metrics.submitsFailed.Load(),
+	}
+	if connects := a.metrics.realtimeConnects.Load(); connects > 1 {
+		metrics.Reconnects = connects - 1
+	}
+	if monitor := a.metrics.monitor.Load(); monitor != nil {
+		stats := monitor.Stats()
+		metrics.LinesParsed = stats.LinesParsed
+		metrics.DedupHits = stats.DedupHits
+	}
+	return metrics
+}
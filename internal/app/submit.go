@@ -0,0 +1,190 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/config"
+	"sentinel2-uploader/internal/evelogs"
+	"sentinel2-uploader/internal/logging"
+	"sentinel2-uploader/internal/submitpool"
+)
+
+const (
+	submitQueueSize      = 128
+	submitCoalesceWindow = 250 * time.Millisecond
+	submitMaxBatchSize   = 50
+)
+
+func (a *UploaderApp) newSubmitPool(state *sessionState, onAuthFailure func(error)) *submitpool.Pool {
+	return submitpool.New(submitpool.Options{
+		QueueSize:      submitQueueSize,
+		CoalesceWindow: submitCoalesceWindow,
+		MaxBatchSize:   submitMaxBatchSize,
+	}, func(ctx context.Context, _ string, batch []evelogs.ReportEvent) {
+		a.submitReports(ctx, state, batch, onAuthFailure)
+	}, a.logger)
+}
+
+// enqueueReport hands a report to its channel's submit worker. It never
+// blocks: when the worker is saturated or stopped, the report goes straight
+// to the outbox instead.
+func (a *UploaderApp) enqueueReport(event evelogs.ReportEvent) {
+	pool := a.submitPool.Load()
+	if pool == nil {
+		a.queueOrDrop(event, submitpool.ErrStopped)
+		return
+	}
+	if err := pool.Enqueue(event); err != nil {
+		a.queueOrDrop(event, err)
+	}
+}
+
+// stopSubmitPool waits for in-flight batches and moves anything still queued
+// into the outbox. The pool context must already be canceled.
+func (a *UploaderApp) stopSubmitPool(pool *submitpool.Pool) {
+	pool.Wait()
+	for _, event := range pool.Drain() {
+		a.queueOrDrop(event, context.Canceled)
+	}
+}
+
+// SubmitStats reports per-channel submit queue depth and throughput.
+func (a *UploaderApp) SubmitStats() submitpool.Stats {
+	pool := a.submitPool.Load()
+	if pool == nil {
+		return submitpool.Stats{}
+	}
+	return pool.Stats()
+}
+
+func (a *UploaderApp) logSubmitBackpressure() {
+	stats := a.SubmitStats()
+	if stats.Depth == 0 && stats.Overflowed == 0 {
+		return
+	}
+	a.logger.Debug("submit queue backpressure",
+		logging.Field("depth", stats.Depth),
+		logging.Field("enqueued", stats.Enqueued),
+		logging.Field("submitted", stats.Submitted),
+		logging.Field("overflowed", stats.Overflowed),
+	)
+}
+
+func (a *UploaderApp) submitReports(ctx context.Context, state *sessionState, events []evelogs.ReportEvent, onAuthFailure func(error)) {
+	if len(events) == 1 || !a.client.BatchSubmitSupported() || (a.outbox != nil && a.outbox.Len() > 0) {
+		a.submitEach(ctx, state, events, onAuthFailure)
+		return
+	}
+
+	payloads := make([]client.SubmitPayload, 0, len(events))
+	for _, event := range events {
+		payloads = append(payloads, a.reportPayload(event))
+	}
+	var results []client.SubmitResult
+	err := a.withSessionRetry(ctx, state, func(token string) error {
+		var batchErr error
+		results, batchErr = a.client.SubmitBatch(ctx, payloads, token)
+		return batchErr
+	}, onAuthFailure)
+	if errors.Is(err, client.ErrBatchSubmitUnsupported) || client.IsRejected(err) {
+		// A rejected batch may hold a single bad report; single submits
+		// deliver the rest.
+		a.submitEach(ctx, state, events, onAuthFailure)
+		return
+	}
+	if err != nil {
+		a.metrics.submitsFailed.Add(uint64(len(events)))
+		for _, event := range events {
+			a.queueOrDrop(event, err)
+		}
+		return
+	}
+	for i, result := range results {
+		err := result.Err()
+		a.metrics.countSubmit(err)
+		if err == nil {
+			events[i].Settle()
+			continue
+		}
+		a.logger.Warn("report failed in batch submit",
+			logging.Field("channel_id", events[i].Channel.ID),
+			logging.Field("error", err),
+		)
+		a.queueOrDrop(events[i], err)
+	}
+}
+
+func (a *UploaderApp) submitEach(ctx context.Context, state *sessionState, events []evelogs.ReportEvent, onAuthFailure func(error)) {
+	for _, event := range events {
+		if err := a.submitReport(ctx, state, event, onAuthFailure); err != nil {
+			a.logger.Warn("report submit failed",
+				logging.Field("channel_id", event.Channel.ID),
+				logging.Field("error", err),
+			)
+		}
+	}
+}
+
+func (a *UploaderApp) reportPayload(event evelogs.ReportEvent) client.SubmitPayload {
+	payload := newSubmitPayload(event.Line, event.Channel.ID)
+	payload.CharacterID = event.CharacterID
+	a.addReporterMetadata(&payload, event.CharacterID, event.ReporterSystem, event.Timestamp)
+	return payload
+}
+
+// addReporterMetadata fills the reporter fields when the server advertised
+// that it accepts them; older servers get the payload they always did.
+func (a *UploaderApp) addReporterMetadata(payload *client.SubmitPayload, characterID string, system string, reportTime time.Time) {
+	if !a.client.HasCapability(client.CapabilityReporterMetadata) {
+		return
+	}
+	payload.ReporterCharacterID = characterID
+	payload.ReporterSystem = system
+	if !reportTime.IsZero() {
+		payload.ReportTime = reportTime.UTC().Format(time.RFC3339)
+	}
+	payload.UploaderInstanceID = a.loadInstanceID()
+}
+
+func (a *UploaderApp) loadInstanceID() string {
+	a.instanceIDOnce.Do(func() {
+		id, err := config.LoadOrCreateInstanceID()
+		if err != nil {
+			a.logger.Warn("uploader instance ID unavailable", logging.Field("error", err))
+			return
+		}
+		a.instanceID = id
+	})
+	return a.instanceID
+}
+
+// submitPayload submits one report and counts the outcome.
+func (a *UploaderApp) submitPayload(ctx context.Context, state *sessionState, payload client.SubmitPayload, onAuthFailure func(error)) error {
+	err := a.withSessionRetry(ctx, state, func(token string) error {
+		return a.client.Submit(ctx, payload, token)
+	}, onAuthFailure)
+	a.metrics.countSubmit(err)
+	return err
+}
+
+func (a *UploaderApp) queueOrDrop(event evelogs.ReportEvent, cause error) {
+	if client.IsRejected(cause) {
+		a.logger.Warn("report dropped: rejected by server",
+			logging.Field("channel_id", event.Channel.ID),
+			logging.Field("error", cause),
+		)
+		event.Settle()
+		return
+	}
+	if a.outbox == nil {
+		a.logger.Warn("report dropped: submit failed and outbox is unavailable",
+			logging.Field("channel_id", event.Channel.ID),
+			logging.Field("error", cause),
+		)
+		return
+	}
+	_ = a.queueInOutbox(event, cause)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"compress/bzip2"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// header is written above the names in the generated file.
+const header = `# Solar system names recognised in intel reports, one per line.
+# Generated by internal/buildtools/sdesystems from the mapSolarSystems table
+# of the EVE Static Data Export (CSV dump at
+# https://www.fuzzwork.co.uk/dump/latest/mapSolarSystems.csv.bz2).
+# Regenerate with "task generate:systems" instead of editing by hand.
+`
+
+func main() {
+	inPath := flag.String("in", "", "input mapSolarSystems CSV path (.csv or .csv.bz2)")
+	outPath := flag.String("out", "", "output systems list path")
+	flag.Parse()
+
+	if *inPath == "" || *outPath == "" {
+		fmt.Fprintln(os.Stderr, "usage: sdesystems -in <mapSolarSystems.csv[.bz2]> -out <systems.txt>")
+		os.Exit(2)
+	}
+
+	names, err := readSystemNames(*inPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read systems: %v\n", err)
+		os.Exit(1)
+	}
+	if len(names) == 0 {
+		fmt.Fprintln(os.Stderr, "read systems: no solar systems found")
+		os.Exit(1)
+	}
+
+	out := header + "\n" + strings.Join(names, "\n") + "\n"
+	if err := os.WriteFile(*outPath, []byte(out), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "write systems: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// readSystemNames returns the sorted, unique solarSystemName column.
+func readSystemNames(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var src io.Reader = file
+	if strings.HasSuffix(path, ".bz2") {
+		src = bzip2.NewReader(file)
+	}
+	reader := csv.NewReader(src)
+	columns, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	nameColumn := slices.Index(columns, "solarSystemName")
+	if nameColumn < 0 {
+		return nil, fmt.Errorf("no solarSystemName column in %s", path)
+	}
+
+	seen := map[string]struct{}{}
+	var names []string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSpace(record[nameColumn])
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/config"
+	"sentinel2-uploader/internal/runtime"
+)
+
+// Channels lists the channels configured on the server.
+func Channels(ctx context.Context, opts config.Options, jsonOut bool, stdout io.Writer, stderr io.Writer) int {
+	opts = withSavedSettings(opts)
+	channels, err := runtime.FetchChannels(ctx, opts, newLogger(opts))
+	if err != nil {
+		fmt.Fprintln(stderr, "failed to fetch channels:", err)
+		return ExitFailure
+	}
+	if jsonOut {
+		if channels == nil {
+			channels = []client.ChannelConfig{}
+		}
+		if err := writeJSON(stdout, channels); err != nil {
+			return ExitFailure
+		}
+		return ExitOK
+	}
+	table := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tNAME\tEVE CHANNEL")
+	for _, channel := range channels {
+		fmt.Fprintf(table, "%s\t%s\t%s\n", channel.ID, channel.Name, channel.EVEChannelID)
+	}
+	if err := table.Flush(); err != nil {
+		return ExitFailure
+	}
+	return ExitOK
+}
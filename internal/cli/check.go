@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"sentinel2-uploader/internal/config"
+	"sentinel2-uploader/internal/runtime"
+	"sentinel2-uploader/internal/ui/headless/health"
+)
+
+type checkReport struct {
+	OK          bool           `json:"ok"`
+	BaseURL     string         `json:"base_url"`
+	ServerError string         `json:"server_error,omitempty"`
+	LogDirs     []string       `json:"log_dirs"`
+	LogError    string         `json:"log_error,omitempty"`
+	Channels    []channelCheck `json:"channels"`
+}
+
+type channelCheck struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Health string `json:"health"`
+	Detail string `json:"detail"`
+}
+
+// Check validates the server URL and token, fetches the channels and reports
+// per-channel log health. It fails when the server or the log directory is
+// unusable; channels without recent logs are only reported.
+func Check(ctx context.Context, opts config.Options, jsonOut bool, stdout io.Writer) int {
+	opts = withSavedSettings(opts)
+	report := checkReport{
+		BaseURL:  strings.TrimSpace(opts.BaseURL),
+		LogDirs:  opts.LogRoots(),
+		Channels: []channelCheck{},
+	}
+	channels, err := runtime.FetchChannels(ctx, opts, newLogger(opts))
+	report.ServerError = errorText(err)
+
+	rows, detail := health.Compute(report.LogDirs, channels, nil, time.Now())
+	report.LogError = detail
+	for i, row := range rows {
+		report.Channels = append(report.Channels, channelCheck{
+			ID:     channels[i].ID,
+			Name:   row.Name,
+			Health: row.Kind.String(),
+			Detail: row.Reason,
+		})
+	}
+	report.OK = report.ServerError == "" && report.LogError == ""
+
+	if jsonOut {
+		if err := writeJSON(stdout, report); err != nil {
+			return ExitFailure
+		}
+	} else {
+		writeCheckText(stdout, report)
+	}
+	if !report.OK {
+		return ExitFailure
+	}
+	return ExitOK
+}
+
+func writeCheckText(w io.Writer, report checkReport) {
+	if report.ServerError != "" {
+		fmt.Fprintf(w, "server:   FAIL %s: %s\n", report.BaseURL, report.ServerError)
+	} else {
+		fmt.Fprintf(w, "server:   ok %s (%d channels)\n", report.BaseURL, len(report.Channels))
+	}
+	if report.LogError != "" {
+		fmt.Fprintf(w, "log dirs: FAIL %s\n", report.LogError)
+	} else {
+		fmt.Fprintf(w, "log dirs: ok %s\n", strings.Join(report.LogDirs, ", "))
+	}
+	if len(report.Channels) == 0 {
+		return
+	}
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "\nCHANNEL\tHEALTH\tDETAIL")
+	for _, channel := range report.Channels {
+		fmt.Fprintf(table, "%s\t%s\t%s\n", channel.Name, channel.Health, channel.Detail)
+	}
+	_ = table.Flush()
+}
@@ -0,0 +1,60 @@
+// Package cli implements the non-interactive modes: the check, channels,
+// config get/set and replay subcommands and the daemon behind --daemon and
+// run --json. Each writes human-readable text or JSON to its writers and
+// returns the process exit code.
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+
+	"sentinel2-uploader/internal/config"
+	"sentinel2-uploader/internal/logging"
+)
+
+const (
+	ExitOK      = 0
+	ExitFailure = 1
+)
+
+// withSavedSettings fills options not given on the command line from the
+// saved settings, as the UIs do on startup.
+func withSavedSettings(opts config.Options) config.Options {
+	if saved, err := config.LoadSettings(); err == nil {
+		opts = config.MergeOptionsWithSettings(opts, saved)
+	}
+	return opts
+}
+
+// newLogger returns a logger that stays off the terminal unless debug output
+// was asked for, so command output is not interleaved with log lines.
+func newLogger(opts config.Options) *logging.Logger {
+	logger := logging.New(opts.Debug)
+	logger.SetTerminalOutputEnabled(opts.Debug)
+	return logger
+}
+
+func writeJSON(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// loadSettings reads the saved settings, treating a missing file as empty.
+func loadSettings() (config.UploaderSettings, error) {
+	settings, err := config.LoadSettings()
+	if errors.Is(err, os.ErrNotExist) {
+		return config.UploaderSettings{}, nil
+	}
+	return settings, err
+}
+
+// errorText is err's message, or empty for nil, for JSON output.
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
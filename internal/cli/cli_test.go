@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/config"
+	"sentinel2-uploader/internal/evelogs/chatlogtest"
+	"sentinel2-uploader/internal/mockserver"
+)
+
+const testToken = "cli-token"
+
+// isolateSettings points the settings file at an empty temp directory.
+func isolateSettings(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("AppData", dir)
+	t.Setenv("HOME", dir)
+}
+
+func newTestServer(t *testing.T) *mockserver.Server {
+	t.Helper()
+	server := mockserver.New(mockserver.Options{
+		UploaderToken: testToken,
+		Channels: []client.ChannelConfig{
+			{ID: "intel", Name: "Intel"},
+			{ID: "corp", Name: "Corp"},
+		},
+	})
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestChannels_JSON(t *testing.T) {
+	isolateSettings(t)
+	server := newTestServer(t)
+
+	var stdout, stderr bytes.Buffer
+	code := Channels(context.Background(), config.Options{BaseURL: server.URL, Token: testToken}, true, &stdout, &stderr)
+	if code != ExitOK {
+		t.Fatalf("Channels() = %d, stderr = %s", code, stderr.String())
+	}
+	var channels []client.ChannelConfig
+	if err := json.Unmarshal(stdout.Bytes(), &channels); err != nil {
+		t.Fatalf("decode %s: %v", stdout.String(), err)
+	}
+	if len(channels) != 2 || channels[0].ID != "corp" || channels[1].Name != "Intel" {
+		t.Fatalf("channels = %+v", channels)
+	}
+
+	stdout.Reset()
+	code = Channels(context.Background(), config.Options{BaseURL: server.URL, Token: "wrong"}, true, &stdout, &stderr)
+	if code != ExitFailure || stdout.Len() != 0 {
+		t.Fatalf("Channels(bad token) = %d, stdout = %q", code, stdout.String())
+	}
+}
+
+func TestCheck_ReportsServerAndChannelHealth(t *testing.T) {
+	isolateSettings(t)
+	server := newTestServer(t)
+	logDir := t.TempDir()
+	log, err := chatlogtest.Open(logDir, chatlogtest.Session{Channel: "Intel", ChannelID: "-100", Listener: "Pilot", CharacterID: "90000001"}, time.Now())
+	if err != nil {
+		t.Fatalf("open log: %v", err)
+	}
+	if err := log.Say(time.Now(), "Scout", "NOL-M9 nv"); err != nil {
+		t.Fatalf("say: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	code := Check(context.Background(), config.Options{BaseURL: server.URL, Token: testToken, LogDir: logDir}, true, &stdout)
+	if code != ExitOK {
+		t.Fatalf("Check() = %d, output = %s", code, stdout.String())
+	}
+	var report checkReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("decode %s: %v", stdout.String(), err)
+	}
+	if !report.OK || len(report.Channels) != 2 {
+		t.Fatalf("report = %+v", report)
+	}
+	if report.Channels[0].ID != "corp" || report.Channels[0].Health != "missing" || report.Channels[1].Health != "active" {
+		t.Fatalf("channels = %+v", report.Channels)
+	}
+
+	stdout.Reset()
+	code = Check(context.Background(), config.Options{BaseURL: server.URL, Token: "wrong", LogDir: logDir}, false, &stdout)
+	if code != ExitFailure || !strings.Contains(stdout.String(), "server:   FAIL") {
+		t.Fatalf("Check(bad token) = %d, output = %s", code, stdout.String())
+	}
+}
+
+func TestConfigSetAndGet(t *testing.T) {
+	isolateSettings(t)
+
+	var stdout, stderr bytes.Buffer
+	if code := ConfigSet("base_url", "https://intel.example.com", false, &stdout, &stderr); code != ExitOK {
+		t.Fatalf("ConfigSet() = %d, stderr = %s", code, stderr.String())
+	}
+	if got := stdout.String(); got != "https://intel.example.com\n" {
+		t.Fatalf("ConfigSet() output = %q", got)
+	}
+	saved, err := config.LoadSettings()
+	if err != nil || saved.BaseURL != "https://intel.example.com" {
+		t.Fatalf("saved settings = %+v, %v", saved, err)
+	}
+
+	stdout.Reset()
+	if code := ConfigGet("", false, true, &stdout, &stderr); code != ExitOK {
+		t.Fatalf("ConfigGet() = %d, stderr = %s", code, stderr.String())
+	}
+	var all config.UploaderSettings
+	if err := json.Unmarshal(stdout.Bytes(), &all); err != nil || all.BaseURL != saved.BaseURL {
+		t.Fatalf("ConfigGet(--json) = %s, %v", stdout.String(), err)
+	}
+
+	stdout.Reset()
+	if code := ConfigSet("token", "secret-token", false, &stdout, &stderr); code != ExitOK || stdout.String() != "secret-token\n" {
+		t.Fatalf("ConfigSet(token) = %d, output = %q", code, stdout.String())
+	}
+	for _, tc := range []struct {
+		key         string
+		showSecrets bool
+		jsonOut     bool
+		want        bool
+	}{
+		{key: "", jsonOut: false, want: false},
+		{key: "", jsonOut: true, want: false},
+		{key: "", showSecrets: true, want: true},
+		{key: "token", want: true},
+	} {
+		stdout.Reset()
+		if code := ConfigGet(tc.key, tc.showSecrets, tc.jsonOut, &stdout, &stderr); code != ExitOK {
+			t.Fatalf("ConfigGet(%q) = %d, stderr = %s", tc.key, code, stderr.String())
+		}
+		if got := strings.Contains(stdout.String(), "secret-token"); got != tc.want {
+			t.Fatalf("ConfigGet(%q, showSecrets=%v, json=%v) printed the token = %v, want %v:\n%s", tc.key, tc.showSecrets, tc.jsonOut, got, tc.want, stdout.String())
+		}
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := ConfigSet("auto_connect", "sometimes", false, &stdout, &stderr); code != ExitFailure || stderr.Len() == 0 {
+		t.Fatalf("ConfigSet(invalid) = %d, stderr = %q", code, stderr.String())
+	}
+}
+
+func TestReplay_JSONDryRun(t *testing.T) {
+	isolateSettings(t)
+	server := newTestServer(t)
+	logDir := t.TempDir()
+	at := time.Date(2026, 2, 16, 19, 30, 0, 0, time.UTC)
+	log, err := chatlogtest.Open(logDir, chatlogtest.Session{Channel: "Intel", ChannelID: "-100", Listener: "Pilot", CharacterID: "90000001"}, at)
+	if err != nil {
+		t.Fatalf("open log: %v", err)
+	}
+	if err := log.Say(at, "Scout", "NOL-M9 nv"); err != nil {
+		t.Fatalf("say: %v", err)
+	}
+
+	replay := config.ReplayOptions{DryRun: true}
+	replay.JSON = true
+	replay.Args.Paths = []string{logDir}
+	var stdout, stderr bytes.Buffer
+	code := Replay(context.Background(), config.Options{BaseURL: server.URL, Token: testToken}, replay, &stdout, &stderr)
+	if code != ExitOK {
+		t.Fatalf("Replay() = %d, output = %s %s", code, stdout.String(), stderr.String())
+	}
+	var report replayReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("decode %s: %v", stdout.String(), err)
+	}
+	if report.Reports != 1 || len(report.Lines) != 1 || report.Lines[0].Line != chatlogtest.Line(at, "Scout", "NOL-M9 nv") {
+		t.Fatalf("report = %+v", report)
+	}
+	if len(server.Submissions()) != 0 {
+		t.Fatalf("dry run submitted %d reports", len(server.Submissions()))
+	}
+}
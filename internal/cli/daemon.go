@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"sentinel2-uploader/internal/app"
+	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/config"
+	"sentinel2-uploader/internal/logging"
+	"sentinel2-uploader/internal/runtime"
+)
+
+// Exit codes of the daemon, so supervisors can tell a bad token, which
+// restarting will not fix, from a server that stayed unreachable.
+const (
+	ExitConfig             = 2
+	ExitAuthFailed         = 3
+	ExitReconnectExhausted = 4
+)
+
+const daemonStopTimeout = 10 * time.Second
+
+type DaemonOptions struct {
+	Stdout io.Writer
+	// Stderr receives warnings and errors. Nil sends them to Stdout.
+	Stderr io.Writer
+	// Reload reloads the saved settings and restarts the uploader with them.
+	Reload <-chan os.Signal
+}
+
+// jsonEvents writes log events, and the status and channel updates shaped
+// like them, as JSON lines.
+type jsonEvents struct {
+	mu     sync.Mutex
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func (e *jsonEvents) write(event logging.Event) {
+	line, err := logging.FormatEventJSON(event)
+	if err != nil {
+		return
+	}
+	out := e.stdout
+	if event.Level >= slog.LevelWarn {
+		out = e.stderr
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, _ = out.Write(append(line, '\n'))
+}
+
+func (e *jsonEvents) info(message string, fields map[string]any) {
+	e.write(logging.Event{Time: time.Now(), Level: slog.LevelInfo, Message: message, Fields: fields})
+}
+
+// Daemon runs the uploader without a UI until ctx ends, connecting at once.
+// Every log event, plus "status", "channels" and "server message" events,
+// is written as a JSON line. It returns ExitAuthFailed or
+// ExitReconnectExhausted when the uploader gives up on its own.
+func Daemon(ctx context.Context, opts config.Options, daemon DaemonOptions) int {
+	cliOpts := opts
+	opts = withSavedSettings(cliOpts)
+	if daemon.Stderr == nil {
+		daemon.Stderr = daemon.Stdout
+	}
+	logger := logging.New(opts.Debug)
+	logger.SetTerminalOutputEnabled(false)
+	defer logger.Close()
+	events := &jsonEvents{stdout: daemon.Stdout, stderr: daemon.Stderr}
+	unsubscribe := logger.Subscribe(events.write)
+	defer unsubscribe()
+
+	exits := make(chan error, 1)
+	hooks := runtime.StartHooks{
+		OnStatus: func(status string) {
+			events.info("status", map[string]any{"status": status})
+		},
+		OnChannelsUpdate: func(channels []client.ChannelConfig) {
+			events.info("channels", map[string]any{"channels": channels})
+		},
+		OnServerMessage: func(text string) {
+			events.info("server message", map[string]any{"text": text})
+		},
+		OnExit: func(err error) {
+			exits <- err
+		},
+	}
+	controller := runtime.NewController(ctx)
+	if err := controller.Start(opts, logger, hooks); err != nil {
+		logger.Error("uploader failed to start", logging.Field("error", err))
+		return ExitConfig
+	}
+	logger.Info("daemon started", logging.Field("pid", os.Getpid()))
+
+	for {
+		select {
+		case <-ctx.Done():
+			if !controller.StopAndWait(daemonStopTimeout) {
+				logger.Warn("uploader did not stop in time")
+			}
+			return ExitOK
+		case err := <-exits:
+			if ctx.Err() != nil {
+				return ExitOK
+			}
+			return daemonExitCode(err)
+		case <-daemon.Reload:
+			next := withSavedSettings(cliOpts)
+			if err := config.ValidateRequired(next); err != nil {
+				logger.Warn("ignoring settings reload: invalid settings", logging.Field("error", err))
+				continue
+			}
+			logger.Info("reloading settings")
+			if !controller.StopAndWait(daemonStopTimeout) {
+				logger.Warn("uploader did not stop in time")
+			}
+			// The stopped run reports its exit; it is not the daemon's.
+			select {
+			case <-exits:
+			default:
+			}
+			logger.SetDebugEnabled(next.Debug)
+			if err := controller.Start(next, logger, hooks); err != nil {
+				logger.Error("uploader failed to restart", logging.Field("error", err))
+				return ExitConfig
+			}
+			logger.Info("settings reloaded")
+		}
+	}
+}
+
+func daemonExitCode(err error) int {
+	switch {
+	case err == nil || errors.Is(err, context.Canceled):
+		return ExitOK
+	case errors.Is(err, app.ErrAuthenticationFailed):
+		return ExitAuthFailed
+	case errors.Is(err, app.ErrRealtimeReconnectExhausted):
+		return ExitReconnectExhausted
+	default:
+		return ExitFailure
+	}
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"sentinel2-uploader/internal/app"
+	"sentinel2-uploader/internal/config"
+)
+
+// syncBuffer is a bytes.Buffer safe for the daemon's concurrent writers.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// waitForOutput polls out until it contains want n times.
+func waitForOutput(t *testing.T, out *syncBuffer, want string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for strings.Count(out.String(), want) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d× %s in:\n%s", n, want, out.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDaemon_ReloadsOnSignalAndStopsCleanly(t *testing.T) {
+	isolateSettings(t)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	server := newTestServer(t)
+	opts := config.Options{BaseURL: server.URL, Token: testToken, LogDir: t.TempDir(), ResumeMaxAge: -1}
+
+	var stdout, stderr syncBuffer
+	reload := make(chan os.Signal, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan int, 1)
+	go func() {
+		done <- Daemon(ctx, opts, DaemonOptions{Stdout: &stdout, Stderr: &stderr, Reload: reload})
+	}()
+
+	connected := fmt.Sprintf(`"status":%q`, "Connected")
+	waitForOutput(t, &stdout, connected, 1)
+	if err := config.SaveSettings(config.UploaderSettings{Debug: true}); err != nil {
+		t.Fatalf("SaveSettings() error = %v", err)
+	}
+	reload <- syscall.SIGHUP
+	waitForOutput(t, &stdout, `"message":"settings reloaded"`, 1)
+	waitForOutput(t, &stdout, connected, 2)
+
+	cancel()
+	select {
+	case code := <-done:
+		if code != ExitOK {
+			t.Fatalf("Daemon() = %d after cancel, want %d", code, ExitOK)
+		}
+	case <-time.After(15 * time.Second):
+		t.Fatal("Daemon() did not return after cancel")
+	}
+	if strings.Contains(stdout.String(), `"level":"WARN"`) {
+		t.Fatalf("warnings on stdout:\n%s", stdout.String())
+	}
+}
+
+func TestDaemon_ExitsWithAuthFailureCode(t *testing.T) {
+	isolateSettings(t)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	server := newTestServer(t)
+	opts := config.Options{BaseURL: server.URL, Token: "wrong", LogDir: t.TempDir()}
+
+	var out syncBuffer
+	if code := Daemon(context.Background(), opts, DaemonOptions{Stdout: &out}); code != ExitAuthFailed {
+		t.Fatalf("Daemon() = %d, want %d; output:\n%s", code, ExitAuthFailed, out.String())
+	}
+	if code := Daemon(context.Background(), config.Options{}, DaemonOptions{Stdout: &out}); code != ExitConfig {
+		t.Fatalf("Daemon(no settings) = %d, want %d", code, ExitConfig)
+	}
+}
+
+func TestDaemonExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, ExitOK},
+		{context.Canceled, ExitOK},
+		{fmt.Errorf("%w: %w", app.ErrAuthenticationFailed, errors.New("401")), ExitAuthFailed},
+		{fmt.Errorf("%w: %w", app.ErrRealtimeReconnectExhausted, errors.New("eof")), ExitReconnectExhausted},
+		{app.ErrStartupRealtimeConnect, ExitFailure},
+	}
+	for _, tt := range tests {
+		if got := daemonExitCode(tt.err); got != tt.want {
+			t.Fatalf("daemonExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
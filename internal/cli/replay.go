@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"sentinel2-uploader/internal/app"
+	"sentinel2-uploader/internal/config"
+	"sentinel2-uploader/internal/runtime"
+)
+
+type replayReport struct {
+	DryRun    bool           `json:"dry_run"`
+	Reports   int            `json:"reports"`
+	Submitted int            `json:"submitted"`
+	Failed    int            `json:"failed"`
+	Error     string         `json:"error,omitempty"`
+	Lines     []replayedLine `json:"lines,omitempty"`
+}
+
+type replayedLine struct {
+	ChannelID string `json:"channel_id"`
+	Channel   string `json:"channel"`
+	Line      string `json:"line"`
+}
+
+// Replay backfills historical chat logs. It does not need the instance lock,
+// so logs can be replayed while the uploader is running. A text dry run
+// prints one "channel<TAB>line" row per report; a JSON one lists them.
+func Replay(ctx context.Context, opts config.Options, replay config.ReplayOptions, stdout io.Writer, stderr io.Writer) int {
+	opts = withSavedSettings(opts)
+	logger := newLogger(opts)
+	defer logger.Close()
+
+	result, err := runtime.Replay(ctx, opts, app.ReplayOptions{
+		Paths:  replay.Args.Paths,
+		From:   replay.From.Time,
+		To:     replay.To.Time,
+		DryRun: replay.DryRun,
+		Rate:   replay.Rate,
+	}, logger)
+
+	if replay.JSON {
+		report := replayReport{
+			DryRun:    replay.DryRun,
+			Reports:   len(result.Reports),
+			Submitted: result.Submitted,
+			Failed:    result.Failed,
+			Error:     errorText(err),
+		}
+		if replay.DryRun {
+			for _, event := range result.Reports {
+				report.Lines = append(report.Lines, replayedLine{ChannelID: event.Channel.ID, Channel: event.Channel.Name, Line: event.Line})
+			}
+		}
+		if writeErr := writeJSON(stdout, report); writeErr != nil {
+			return ExitFailure
+		}
+	} else if err != nil {
+		fmt.Fprintln(stderr, "replay failed:", err)
+	} else if replay.DryRun {
+		for _, event := range result.Reports {
+			fmt.Fprintf(stdout, "%s\t%s\n", event.Channel.Name, event.Line)
+		}
+		fmt.Fprintf(stderr, "%d reports would be submitted\n", len(result.Reports))
+	} else {
+		fmt.Fprintf(stderr, "submitted %d of %d reports (%d failed)\n", result.Submitted, len(result.Reports), result.Failed)
+	}
+	if err != nil || result.Failed > 0 {
+		return ExitFailure
+	}
+	return ExitOK
+}
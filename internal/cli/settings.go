@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"sentinel2-uploader/internal/config"
+)
+
+// ConfigGet prints one saved setting, or all of them when key is empty.
+// Text output prints strings bare and other values as JSON. Printing all
+// settings masks the token unless showSecrets is set; asking for it by name
+// prints it.
+func ConfigGet(key string, showSecrets bool, jsonOut bool, stdout io.Writer, stderr io.Writer) int {
+	settings, err := loadSettings()
+	if err != nil {
+		fmt.Fprintln(stderr, "failed to load settings:", err)
+		return ExitFailure
+	}
+	if key == "" {
+		if !showSecrets {
+			settings = settings.MaskSecrets()
+		}
+		if jsonOut {
+			if err := writeJSON(stdout, settings); err != nil {
+				return ExitFailure
+			}
+			return ExitOK
+		}
+		for _, name := range config.SettingKeys() {
+			value, _ := settings.Get(name)
+			fmt.Fprintf(stdout, "%s=%s\n", name, settingText(value))
+		}
+		return ExitOK
+	}
+
+	value, err := settings.Get(key)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitFailure
+	}
+	if jsonOut {
+		fmt.Fprintln(stdout, string(value))
+	} else {
+		fmt.Fprintln(stdout, settingText(value))
+	}
+	return ExitOK
+}
+
+// ConfigSet changes one saved setting and prints its new value.
+func ConfigSet(key string, value string, jsonOut bool, stdout io.Writer, stderr io.Writer) int {
+	settings, err := loadSettings()
+	if err != nil {
+		fmt.Fprintln(stderr, "failed to load settings:", err)
+		return ExitFailure
+	}
+	if err := settings.Set(key, value); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitFailure
+	}
+	if err := config.SaveSettings(settings); err != nil {
+		fmt.Fprintln(stderr, "failed to save settings:", err)
+		return ExitFailure
+	}
+	return ConfigGet(key, true, jsonOut, stdout, stderr)
+}
+
+func settingText(value json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(value, &text); err == nil {
+		return text
+	}
+	return string(value)
+}
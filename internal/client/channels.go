@@ -38,7 +38,7 @@ func (c *SentinelClient) FetchChannels(ctx context.Context, sessionToken string)
 			logging.Field("content_type", resp.Header.Get("Content-Type")),
 			logging.Field("response", body),
 		)
-		return nil, &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+		return nil, statusError(resp)
 	}
 
 	var cfg uploaderConfigResponse
@@ -53,11 +53,14 @@ func (c *SentinelClient) FetchChannels(ctx context.Context, sessionToken string)
 		return nil, err
 	}
 
+	c.storeServerFilterRules(cfg.FilterRules)
+	c.storeServerCapabilities(cfg.Capabilities)
+
 	out := []ChannelConfig{}
 	for _, channel := range cfg.Channels {
 		trimmed := strings.TrimSpace(channel.Name)
 		if trimmed != "" {
-			out = append(out, ChannelConfig{ID: strings.TrimSpace(channel.ID), Name: trimmed})
+			out = append(out, ChannelConfig{ID: strings.TrimSpace(channel.ID), Name: trimmed, EVEChannelID: channel.EVEChannelID})
 		}
 	}
 	normalized := normalizeChannels(out)
@@ -81,7 +84,7 @@ func normalizeChannels(channels []ChannelConfig) []ChannelConfig {
 			continue
 		}
 		seen[key] = struct{}{}
-		normalized = append(normalized, ChannelConfig{ID: id, Name: name})
+		normalized = append(normalized, ChannelConfig{ID: id, Name: name, EVEChannelID: strings.TrimSpace(channel.EVEChannelID)})
 	}
 	slices.SortFunc(normalized, func(a, b ChannelConfig) int {
 		if a.Name < b.Name {
@@ -106,7 +109,7 @@ func channelsEqual(a []ChannelConfig, b []ChannelConfig) bool {
 		return false
 	}
 	for i := range a {
-		if a[i].ID != b[i].ID || a[i].Name != b[i].Name {
+		if a[i] != b[i] {
 			return false
 		}
 	}
@@ -124,3 +124,39 @@ func TestFetchChannels_HTTPErrorAndInvalidJSON(t *testing.T) {
 		t.Fatalf("FetchChannels() expected error on invalid JSON")
 	}
 }
+
+func TestFetchChannels_StoresServerFilterRules(t *testing.T) {
+	payloads := []string{
+		`{"channels":[{"id":"1","name":"Alpha"}]}`,
+		`{"channels":[{"id":"1","name":"Alpha"}],"filter_rules":[{"name":"motd","action":"exclude","message":"^Channel MOTD"}]}`,
+	}
+	call := 0
+	httpClient := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			body := payloads[call]
+			call++
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Status:     "200 OK",
+				Header:     make(http.Header),
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Request:    r,
+			}, nil
+		}),
+	}
+	c := New(httpClient, "token-123", config.APIEndpoints{ConfigURL: "https://example.test/uploader/config"}, logging.New(false))
+
+	if _, err := c.FetchChannels(context.Background(), "session-123"); err != nil {
+		t.Fatalf("FetchChannels() error = %v", err)
+	}
+	if rules := c.ServerFilterRules(); rules != nil {
+		t.Fatalf("ServerFilterRules() = %#v, want nil before the server sends any", rules)
+	}
+	if _, err := c.FetchChannels(context.Background(), "session-123"); err != nil {
+		t.Fatalf("FetchChannels() error = %v", err)
+	}
+	want := []config.FilterRule{{Name: "motd", Action: "exclude", Message: "^Channel MOTD"}}
+	if got := c.ServerFilterRules(); !config.FilterRulesEqual(got, want) {
+		t.Fatalf("ServerFilterRules() = %#v, want %#v", got, want)
+	}
+}
@@ -1,11 +1,17 @@
 package client
 
 import (
+	"context"
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"sentinel2-uploader/internal/config"
 	"sentinel2-uploader/internal/logging"
+	"sentinel2-uploader/internal/pbrealtime"
 )
 
 const (
@@ -13,18 +19,110 @@ const (
 	reconnectDelay      = 5 * time.Second
 	reconnectMaxDelay   = 30 * time.Second
 	reconnectMaxElapsed = 1 * time.Minute
+	// maxRateLimitedReconnectWindows bounds the reconnect windows started
+	// in a row because the last one ended on a 429.
+	maxRateLimitedReconnectWindows = 3
+
+	// A realtime stream that misses this many keepalives in a row is torn
+	// down and reconnected.
+	realtimeKeepaliveInterval = 30 * time.Second
+	realtimeIdleKeepalives    = 3
 )
 
 type SentinelClient struct {
+	// http pauses endpoints the server rate limits; see rateLimiter.
 	http      *http.Client
 	token     string
 	endpoints config.APIEndpoints
 	logger    *logging.Logger
+
+	realtimeTransport pbrealtime.Transport
+	// resubscribe ends the running realtime session; nil between sessions.
+	resubscribeMu sync.Mutex
+	resubscribe   context.CancelCauseFunc
+
+	batchUnsupported   atomic.Bool
+	serverFilterRules  atomic.Pointer[[]config.FilterRule]
+	serverCapabilities atomic.Pointer[[]string]
 }
 
+const (
+	// CapabilityReporterMetadata is advertised by servers that accept the
+	// reporter fields of SubmitPayload.
+	CapabilityReporterMetadata = "reporter_metadata"
+	// CapabilityRealtimeWebSocket is advertised by servers that serve the
+	// realtime stream over WebSocket too. The auto transport only tries
+	// WebSocket on those.
+	CapabilityRealtimeWebSocket = "realtime_websocket"
+)
+
 func New(httpClient *http.Client, token string, endpoints config.APIEndpoints, logger *logging.Logger) *SentinelClient {
 	if logger == nil {
 		panic("client.New: logger must not be nil")
 	}
-	return &SentinelClient{http: httpClient, token: token, endpoints: endpoints, logger: logger}
+	return &SentinelClient{http: newRateLimitedClient(httpClient, logger), token: token, endpoints: endpoints, logger: logger}
+}
+
+// UseRealtimeTransport selects how the realtime config stream is carried:
+// config.RealtimeTransportSSE, config.RealtimeTransportWebSocket, or auto
+// (the default) which starts on SSE and switches after repeated failures
+// when the server advertises CapabilityRealtimeWebSocket.
+// It must be called before StartChannelConfigSync.
+func (c *SentinelClient) UseRealtimeTransport(mode string) {
+	sse := pbrealtime.SSETransport{HTTP: c.http, URL: c.endpoints.RealtimeURL, Logger: c.logger}
+	ws := pbrealtime.WebSocketTransport{HTTP: c.http, URL: c.endpoints.RealtimeWebSocketURL, Logger: c.logger}
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case config.RealtimeTransportSSE:
+		c.realtimeTransport = sse
+	case config.RealtimeTransportWebSocket:
+		c.realtimeTransport = ws
+	default:
+		c.realtimeTransport = &pbrealtime.AutoTransport{
+			SSE:       sse,
+			WebSocket: ws,
+			WebSocketSupported: func() bool {
+				return c.HasCapability(CapabilityRealtimeWebSocket)
+			},
+			Logger: c.logger,
+		}
+	}
+}
+
+// BatchSubmitSupported reports whether SubmitBatch may be used. It turns false
+// once the server answers the batch endpoint with 404 or 405.
+func (c *SentinelClient) BatchSubmitSupported() bool {
+	return c.endpoints.SubmitBatchURL != "" && !c.batchUnsupported.Load()
+}
+
+// ServerFilterRules returns the filter rules from the latest config payload
+// that carried any, or nil when the server never sent them.
+func (c *SentinelClient) ServerFilterRules() []config.FilterRule {
+	rules := c.serverFilterRules.Load()
+	if rules == nil {
+		return nil
+	}
+	return *rules
+}
+
+// HasCapability reports whether the latest config payload that listed
+// capabilities included name.
+func (c *SentinelClient) HasCapability(name string) bool {
+	capabilities := c.serverCapabilities.Load()
+	return capabilities != nil && slices.Contains(*capabilities, name)
+}
+
+func (c *SentinelClient) storeServerCapabilities(capabilities *[]string) {
+	if capabilities != nil {
+		c.serverCapabilities.Store(capabilities)
+	}
+}
+
+// storeServerFilterRules records rules from a config payload and reports
+// whether they differ from the previous ones.
+func (c *SentinelClient) storeServerFilterRules(rules *[]config.FilterRule) bool {
+	if rules == nil {
+		return false
+	}
+	previous := c.serverFilterRules.Swap(rules)
+	return previous == nil || !config.FilterRulesEqual(*previous, *rules)
 }
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"sentinel2-uploader/internal/logging"
+)
+
+// UploadDiagnostics posts a diagnostics bundle requested by the server.
+// bundle is marshalled as JSON.
+func (c *SentinelClient) UploadDiagnostics(ctx context.Context, bundle any, sessionToken string) error {
+	token := strings.TrimSpace(sessionToken)
+	if token == "" {
+		return &HTTPStatusError{StatusCode: http.StatusUnauthorized, Status: "missing uploader realtime session token"}
+	}
+	body, err := json.Marshal(bundle)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoints.DiagnosticsURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	c.logger.Debugf("POST %s -> %s", c.endpoints.DiagnosticsURL, resp.Status)
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		c.logger.Warn("diagnostics upload rejected",
+			logging.Field("status", resp.Status),
+			logging.Field("response", logging.FormatHTTPPayload(data)),
+		)
+		return statusError(resp)
+	}
+	c.logger.Debug("diagnostics upload accepted", logging.Field("bytes", len(body)))
+	return nil
+}
@@ -2,9 +2,17 @@ package client
 
 import (
 	"errors"
+	"net/http"
+	"time"
+
 	"sentinel2-uploader/internal/pbrealtime"
 )
 
+var ErrBatchSubmitUnsupported = errors.New("batch submit not supported by server")
+
+// ErrResubscribeRequested ends a realtime session torn down by Resubscribe.
+var ErrResubscribeRequested = errors.New("realtime resubscribe requested")
+
 type HTTPStatusError struct {
 	StatusCode int
 	Status     string
@@ -27,3 +35,38 @@ func IsUnauthorized(err error) bool {
 	}
 	return pbrealtime.IsUnauthorized(err)
 }
+
+// IsRejected reports whether the server refused the request itself with a
+// 4xx, so sending it again cannot succeed. Session failures (401/403),
+// timeouts (408) and rate limits (429) are not rejections.
+func IsRejected(err error) bool {
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) || IsUnauthorized(err) {
+		return false
+	}
+	code := statusErr.StatusCode
+	return code >= 400 && code < 500 && code != http.StatusRequestTimeout && code != http.StatusTooManyRequests
+}
+
+// RateLimitError is a 429 answer, or a request held back because its
+// endpoint is paused after one.
+type RateLimitError = pbrealtime.RateLimitError
+
+// RetryAfter reports whether err is a rate limit and returns the delay the
+// server advised.
+func RetryAfter(err error) (time.Duration, bool) {
+	return pbrealtime.RetryAfter(err)
+}
+
+// statusError is the error for a failed response. It differs from the
+// realtime client's only in answering other failures with this package's
+// HTTPStatusError.
+func statusError(resp *http.Response) error {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return &RateLimitError{
+			Status:     resp.Status,
+			RetryAfter: pbrealtime.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+	return &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+}
@@ -12,3 +12,12 @@ func TestIsUnauthorized_PBRealtimeStatusError(t *testing.T) {
 		t.Fatalf("IsUnauthorized(%v) = false, want true", err)
 	}
 }
+
+func TestIsExpectedRealtimeReconnect_Resubscribe(t *testing.T) {
+	if !isExpectedRealtimeReconnect(ErrResubscribeRequested) {
+		t.Fatal("isExpectedRealtimeReconnect(ErrResubscribeRequested) = false, want true")
+	}
+	if (&SentinelClient{}).Resubscribe() {
+		t.Fatal("Resubscribe() = true without a running session")
+	}
+}
@@ -33,7 +33,7 @@ func (c *SentinelClient) Heartbeat(ctx context.Context, sessionToken string) err
 			logging.Field("status", resp.Status),
 			logging.Field("response", logging.FormatHTTPPayload(data)),
 		)
-		return &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+		return statusError(resp)
 	}
 
 	c.logger.Debug("heartbeat accepted")
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"sentinel2-uploader/internal/logging"
+	"sentinel2-uploader/internal/pbrealtime"
+)
+
+const (
+	// defaultRateLimitPause applies to 429 answers without Retry-After.
+	defaultRateLimitPause = 5 * time.Second
+	// maxRateLimitPause caps Retry-After so one bad header cannot stall the
+	// uploader for long.
+	maxRateLimitPause = 5 * time.Minute
+)
+
+// rateLimiter is the HTTP transport under every SentinelClient request,
+// realtime ones included. A 429 answer pauses its endpoint, by method and
+// path, for the advised delay. Later requests to a paused endpoint wait the
+// pause out, or fail at once with a *RateLimitError when their context
+// would end first.
+type rateLimiter struct {
+	next   http.RoundTripper
+	logger *logging.Logger
+	paused *endpointPauses
+}
+
+// endpointPauses is shared by a rateLimiter and its copies over other
+// transports.
+type endpointPauses struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+}
+
+func newRateLimitedClient(httpClient *http.Client, logger *logging.Logger) *http.Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	limited := *httpClient
+	next := limited.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	limited.Transport = &rateLimiter{next: next, logger: logger, paused: &endpointPauses{until: make(map[string]time.Time)}}
+	return &limited
+}
+
+var _ pbrealtime.RoundTripperWrapper = (*rateLimiter)(nil)
+
+// Next and WithNext let the WebSocket upgrade run over an HTTP/1.1-only
+// transport while keeping the same endpoint pauses.
+func (l *rateLimiter) Next() http.RoundTripper {
+	return l.next
+}
+
+func (l *rateLimiter) WithNext(next http.RoundTripper) http.RoundTripper {
+	return &rateLimiter{next: next, logger: l.logger, paused: l.paused}
+}
+
+func (l *rateLimiter) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := req.Method + " " + req.URL.Path
+	if err := l.wait(req.Context(), endpoint); err != nil {
+		return nil, err
+	}
+	resp, err := l.next.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		l.pause(endpoint, pbrealtime.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()))
+	}
+	return resp, err
+}
+
+func (l *rateLimiter) wait(ctx context.Context, endpoint string) error {
+	l.paused.mu.Lock()
+	until := l.paused.until[endpoint]
+	l.paused.mu.Unlock()
+	remaining := time.Until(until)
+	if remaining <= 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(until) {
+		return &RateLimitError{Status: "rate limited: endpoint paused", RetryAfter: remaining}
+	}
+	l.logger.Debug("waiting for rate limit pause",
+		logging.Field("endpoint", endpoint),
+		logging.Field("remaining", remaining.String()),
+	)
+	timer := time.NewTimer(remaining)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (l *rateLimiter) pause(endpoint string, delay time.Duration) {
+	if delay <= 0 {
+		delay = defaultRateLimitPause
+	}
+	delay = min(delay, maxRateLimitPause)
+	until := time.Now().Add(delay)
+	l.paused.mu.Lock()
+	if until.After(l.paused.until[endpoint]) {
+		l.paused.until[endpoint] = until
+	}
+	l.paused.mu.Unlock()
+	l.logger.Warn("server rate limited endpoint; pausing it",
+		logging.Field("endpoint", endpoint),
+		logging.Field("retry_after", delay.String()),
+	)
+}
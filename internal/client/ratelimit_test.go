@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+
+	"sentinel2-uploader/internal/config"
+	"sentinel2-uploader/internal/logging"
+	"sentinel2-uploader/internal/pbrealtime"
+)
+
+func TestRateLimiter_PausesOnlyTheLimitedEndpoint(t *testing.T) {
+	var submits, heartbeats atomic.Int32
+	httpClient := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			resp := &http.Response{
+				StatusCode: http.StatusNoContent,
+				Status:     "204 No Content",
+				Header:     make(http.Header),
+				Body:       io.NopCloser(strings.NewReader("")),
+				Request:    r,
+			}
+			if strings.HasSuffix(r.URL.Path, "/submit") {
+				submits.Add(1)
+				resp.StatusCode = http.StatusTooManyRequests
+				resp.Status = "429 Too Many Requests"
+				resp.Header.Set("Retry-After", "120")
+			} else {
+				heartbeats.Add(1)
+			}
+			return resp, nil
+		}),
+	}
+	logger := logging.New(false)
+	logger.SetTerminalOutputEnabled(false)
+	c := New(httpClient, "token-123", config.APIEndpoints{
+		SubmitURL:    "https://example.test/uploader/submit",
+		HeartbeatURL: "https://example.test/uploader/heartbeat",
+	}, logger)
+
+	payload := SubmitPayload{ChannelID: "abc", Text: "report text"}
+	err := c.Submit(context.Background(), payload, "session-123")
+	if delay, limited := RetryAfter(err); !limited || delay != 2*time.Minute {
+		t.Fatalf("RetryAfter(%v) = %s, %t; want 2m0s, true", err, delay, limited)
+	}
+	var statusErr *pbrealtime.HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Submit() error = %v, want a 429 status error", err)
+	}
+
+	// The endpoint is paused past the deadline, so the request fails
+	// without reaching the server.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err = c.Submit(ctx, payload, "session-123")
+	if _, limited := RetryAfter(err); !limited || submits.Load() != 1 {
+		t.Fatalf("Submit() while paused error = %v after %d requests, want a rate limit and 1 request", err, submits.Load())
+	}
+
+	if err := c.Heartbeat(ctx, "session-123"); err != nil || heartbeats.Load() != 1 {
+		t.Fatalf("Heartbeat() error = %v after %d requests, want it unaffected", err, heartbeats.Load())
+	}
+}
+
+func TestRetryAfter_PBRealtimeRateLimitError(t *testing.T) {
+	err := &pbrealtime.RateLimitError{Status: "429 Too Many Requests", RetryAfter: 3 * time.Second}
+	if delay, limited := RetryAfter(err); !limited || delay != 3*time.Second {
+		t.Fatalf("RetryAfter(%v) = %s, %t; want 3s, true", err, delay, limited)
+	}
+	if _, limited := RetryAfter(&HTTPStatusError{StatusCode: http.StatusServiceUnavailable}); limited {
+		t.Fatal("RetryAfter(503) reported a rate limit")
+	}
+}
+
+func TestReconnectWindows_BoundsRateLimitedWindows(t *testing.T) {
+	limited := &RateLimitError{Status: "429 Too Many Requests", RetryAfter: time.Second}
+	windows := &reconnectWindows{}
+	for i := range maxRateLimitedReconnectWindows {
+		if wait, extend := windows.rateLimited(limited); !extend || wait != reconnectDelay {
+			t.Fatalf("rateLimited() window %d = %s, %t; want %s, true", i+1, wait, extend, reconnectDelay)
+		}
+	}
+	if _, extend := windows.rateLimited(limited); extend {
+		t.Fatal("rateLimited() extended past the cap")
+	}
+	windows.connected()
+	if _, extend := windows.rateLimited(limited); !extend {
+		t.Fatal("rateLimited() after a connection did not extend")
+	}
+	if _, extend := windows.rateLimited(errors.New("eof")); extend {
+		t.Fatal("rateLimited() extended a window that was not rate limited")
+	}
+}
+
+func TestAdvisedBackOff_WaitsAtLeastRetryAfter(t *testing.T) {
+	exp := backoff.NewExponentialBackOff()
+	exp.InitialInterval = reconnectDelay
+	exp.MaxInterval = reconnectMaxDelay
+	exp.RandomizationFactor = 0
+	exp.Reset()
+	b := &advisedBackOff{exp: exp, cursor: &pbrealtime.StreamCursor{}, logger: logging.New(false)}
+	b.rateLimited(45 * time.Second)
+	if got := b.NextBackOff(); got != 45*time.Second {
+		t.Fatalf("NextBackOff() after 429 = %s, want 45s", got)
+	}
+	if got := b.NextBackOff(); got >= 45*time.Second {
+		t.Fatalf("NextBackOff() later = %s, want the advice used once", got)
+	}
+}
@@ -10,11 +10,15 @@ import (
 
 	"github.com/cenkalti/backoff/v5"
 
+	"sentinel2-uploader/internal/config"
 	"sentinel2-uploader/internal/logging"
 	"sentinel2-uploader/internal/pbrealtime"
 )
 
-const realtimeKeepaliveTopic = "realtime.keepalive"
+const (
+	realtimeKeepaliveTopic = "realtime.keepalive"
+	realtimeCommandTopic   = "uploader.command"
+)
 
 type SyncHooks struct {
 	OnConnected                           func(string, pbrealtime.Session, uint64)
@@ -22,6 +26,12 @@ type SyncHooks struct {
 	OnStopped                             func(error)
 	OnAuthFailure                         func(error)
 	ShouldContinueAfterReconnectExhausted func(lastErr error, maxElapsed time.Duration) bool
+	// OnFilterRules fires when a realtime config payload carries filter
+	// rules that differ from the last ones seen.
+	OnFilterRules func([]config.FilterRule)
+	// OnCommand receives commands pushed on the command topic. It runs on
+	// the stream goroutine and must not block.
+	OnCommand func(Command)
 }
 
 func (c *SentinelClient) FetchRealtimeSession(ctx context.Context) (pbrealtime.Session, error) {
@@ -82,6 +92,12 @@ func (c *SentinelClient) StartChannelConfigSync(ctx context.Context, initial []C
 		retry.InitialInterval = reconnectDelay
 		retry.MaxInterval = reconnectMaxDelay
 		retry.Reset()
+		// The cursor carries Last-Event-ID and the server's retry advice
+		// from one session to the next.
+		cursor := &pbrealtime.StreamCursor{}
+		reconnectBackOff := &advisedBackOff{exp: retry, cursor: cursor, logger: c.logger}
+
+		windows := &reconnectWindows{}
 
 		useInitialSession := initialSession != nil
 		var sessionEpoch uint64
@@ -102,11 +118,12 @@ func (c *SentinelClient) StartChannelConfigSync(ctx context.Context, initial []C
 				runHooks.OnConnected = func(topic string, session pbrealtime.Session, _ uint64) {
 					prefetchedToken = session.Token
 					attemptConnected = true
+					windows.connected()
 					if hooks.OnConnected != nil {
 						hooks.OnConnected(topic, session, attemptEpoch)
 					}
 				}
-				err := c.runRealtimeConfigSession(ctx, pushUpdate, runHooks, prefetched, attemptEpoch)
+				err := c.runRealtimeConfigSession(ctx, pushUpdate, runHooks, prefetched, cursor, attemptEpoch)
 				if err == nil {
 					return struct{}{}, nil
 				}
@@ -136,13 +153,33 @@ func (c *SentinelClient) StartChannelConfigSync(ctx context.Context, initial []C
 					return struct{}{}, err
 				}
 
+				if delay, limited := RetryAfter(err); limited {
+					// Other endpoints may still work, but a fallback fetch
+					// would only add to the load the server pushed back on.
+					reconnectBackOff.rateLimited(delay)
+					c.logger.Warn("realtime channel sync rate limited",
+						logging.Field("error", err),
+						logging.Field("retry_after", delay.String()))
+					return struct{}{}, err
+				}
+
+				if pbrealtime.IsIdle(err) {
+					if attemptConnected {
+						retry.Reset()
+					}
+					// Updates may have been lost while the stream was silent.
+					c.logger.Warn("realtime channel sync stalled; reconnecting", logging.Field("error", err))
+					fallbackFetch()
+					return struct{}{}, err
+				}
+
 				c.logger.Warn("realtime channel sync disconnected", logging.Field("error", err))
 
 				fallbackFetch()
 
 				return struct{}{}, err
 			},
-				backoff.WithBackOff(retry),
+				backoff.WithBackOff(reconnectBackOff),
 				backoff.WithMaxElapsedTime(reconnectMaxElapsed),
 				backoff.WithNotify(func(err error, next time.Duration) {
 					c.logger.Debug("retrying realtime channel sync",
@@ -153,6 +190,21 @@ func (c *SentinelClient) StartChannelConfigSync(ctx context.Context, initial []C
 			if retryErr == nil || errors.Is(retryErr, context.Canceled) || errors.Is(retryErr, context.DeadlineExceeded) {
 				break
 			}
+			if wait, extend := windows.rateLimited(retryErr); extend {
+				// The server asked for patience, not for the uploader to give
+				// up: wait out the advice and start a new reconnect window.
+				c.logger.Warn("realtime reconnect window ended while rate limited; waiting",
+					logging.Field("error", retryErr),
+					logging.Field("wait", wait.String()),
+					logging.Field("window", windows.limited))
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+				case <-timer.C:
+				}
+				timer.Stop()
+				continue
+			}
 			if hooks.ShouldContinueAfterReconnectExhausted != nil &&
 				hooks.ShouldContinueAfterReconnectExhausted(retryErr, reconnectMaxElapsed) {
 				c.logger.Warn(
@@ -179,11 +231,95 @@ func (c *SentinelClient) StartChannelConfigSync(ctx context.Context, initial []C
 	return updates
 }
 
+// reconnectWindows counts the reconnect windows that ended on a rate limit
+// since the stream last connected. Such a window is followed by another
+// one, up to maxRateLimitedReconnectWindows in a row, so a server that keeps
+// answering 429 still ends the sync as exhausted.
+type reconnectWindows struct {
+	limited int
+}
+
+func (w *reconnectWindows) connected() {
+	w.limited = 0
+}
+
+// rateLimited reports whether a window that ended with err should be
+// followed by another one, and how long to wait before it starts.
+func (w *reconnectWindows) rateLimited(err error) (time.Duration, bool) {
+	delay, limited := RetryAfter(err)
+	if !limited || w.limited >= maxRateLimitedReconnectWindows {
+		return 0, false
+	}
+	w.limited++
+	return max(min(delay, maxRateLimitPause), reconnectDelay), true
+}
+
+// advisedBackOff is the exponential reconnect backoff, restarted from the
+// server-advised delay (the SSE retry field) whenever that advice changes.
+// After a 429 the next delay is at least the server's Retry-After.
+type advisedBackOff struct {
+	exp       *backoff.ExponentialBackOff
+	cursor    *pbrealtime.StreamCursor
+	logger    *logging.Logger
+	applied   time.Duration
+	rateLimit time.Duration
+}
+
+// rateLimited stretches the next delay to the server's Retry-After, or to
+// the default pause when it gave none.
+func (b *advisedBackOff) rateLimited(retryAfter time.Duration) {
+	if retryAfter <= 0 {
+		retryAfter = defaultRateLimitPause
+	}
+	b.rateLimit = min(retryAfter, maxRateLimitPause)
+}
+
+func (b *advisedBackOff) NextBackOff() time.Duration {
+	if advised := b.cursor.RetryDelay(); advised > 0 && advised != b.applied {
+		b.applied = advised
+		b.exp.InitialInterval = advised
+		b.exp.MaxInterval = max(reconnectMaxDelay, advised)
+		b.exp.Reset()
+		b.logger.Debug("using server-advised realtime retry delay", logging.Field("retry", advised.String()))
+	}
+	next := b.exp.NextBackOff()
+	if b.rateLimit > 0 {
+		next = max(next, b.rateLimit)
+		b.rateLimit = 0
+	}
+	return next
+}
+
+func (b *advisedBackOff) Reset() {
+	b.exp.Reset()
+}
+
 func isExpectedRealtimeReconnect(err error) bool {
-	return errors.Is(err, io.EOF) || errors.Is(err, pbrealtime.ErrSessionRefreshDue)
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, pbrealtime.ErrSessionRefreshDue) ||
+		errors.Is(err, ErrResubscribeRequested)
+}
+
+// Resubscribe ends the running realtime session so channel config sync
+// reconnects and subscribes again. It reports false when no session is
+// running.
+func (c *SentinelClient) Resubscribe() bool {
+	c.resubscribeMu.Lock()
+	defer c.resubscribeMu.Unlock()
+	if c.resubscribe == nil {
+		return false
+	}
+	c.resubscribe(ErrResubscribeRequested)
+	return true
 }
 
-func (c *SentinelClient) runRealtimeConfigSession(ctx context.Context, onUpdate func([]ChannelConfig), hooks SyncHooks, prefetched *pbrealtime.Session, epoch uint64) error {
+func (c *SentinelClient) setResubscribe(cancel context.CancelCauseFunc) {
+	c.resubscribeMu.Lock()
+	defer c.resubscribeMu.Unlock()
+	c.resubscribe = cancel
+}
+
+func (c *SentinelClient) runRealtimeConfigSession(ctx context.Context, onUpdate func([]ChannelConfig), hooks SyncHooks, prefetched *pbrealtime.Session, cursor *pbrealtime.StreamCursor, epoch uint64) error {
 	// Acquire short-lived realtime credentials scoped to uploader subscriptions.
 	auth := pbrealtime.AuthClient{
 		HTTP:             c.http,
@@ -213,15 +349,26 @@ func (c *SentinelClient) runRealtimeConfigSession(ctx context.Context, onUpdate
 	stream := pbrealtime.StreamClient{
 		HTTP:        c.http,
 		RealtimeURL: c.endpoints.RealtimeURL,
+		Transport:   c.realtimeTransport,
+		Cursor:      cursor,
 		RefreshLead: realtimeRefreshLead,
-		ExtraTopics: []string{realtimeKeepaliveTopic},
-		Logger:      c.logger,
+		// Keepalives on the extra topic keep an idle stream from tripping
+		// the watchdog; silence means the connection is half-open.
+		KeepaliveInterval: realtimeKeepaliveInterval,
+		IdleKeepalives:    realtimeIdleKeepalives,
+		ExtraTopics:       []string{realtimeKeepaliveTopic, realtimeCommandTopic},
+		Logger:            c.logger,
 	}
 
+	sessionCtx, cancelSession := context.WithCancelCause(ctx)
+	defer cancelSession(nil)
+	c.setResubscribe(cancelSession)
+	defer c.setResubscribe(nil)
+
 	connected := false
-	// StreamClient owns PB_CONNECT + subscribe + SSE transport details.
+	// StreamClient owns PB_CONNECT + subscribe + transport details.
 	// This layer only handles uploader-specific payload decoding and update apply.
-	runErr := stream.RunSession(ctx, session, auth.Subscribe, pbrealtime.SessionHandlers{
+	runErr := stream.RunSession(sessionCtx, session, auth.Subscribe, pbrealtime.SessionHandlers{
 		OnConnected: func(topic string) {
 			c.logger.Info("realtime config stream connected", logging.Field("topic", topic))
 			connected = true
@@ -235,6 +382,11 @@ func (c *SentinelClient) runRealtimeConfigSession(ctx context.Context, onUpdate
 				c.logger.Warn("failed to decode realtime config payload", logging.Field("error", unmarshalErr))
 				return
 			}
+			c.storeServerCapabilities(cfg.Capabilities)
+			if c.storeServerFilterRules(cfg.FilterRules) && hooks.OnFilterRules != nil {
+				c.logger.Debug("received realtime filter rules", logging.Field("count", len(*cfg.FilterRules)))
+				hooks.OnFilterRules(*cfg.FilterRules)
+			}
 			channels := normalizeChannels(cfg.Channels)
 			if len(channels) == 0 {
 				c.logger.Warn("realtime config payload had no channels")
@@ -244,7 +396,11 @@ func (c *SentinelClient) runRealtimeConfigSession(ctx context.Context, onUpdate
 			onUpdate(channels)
 		},
 		OnUnhandled: func(event pbrealtime.Event) {
-			if strings.TrimSpace(event.Name) == realtimeKeepaliveTopic {
+			switch strings.TrimSpace(event.Name) {
+			case realtimeKeepaliveTopic:
+				return
+			case realtimeCommandTopic:
+				c.handleRealtimeCommand(event, hooks)
 				return
 			}
 			c.logger.Debug("ignoring realtime event",
@@ -253,12 +409,15 @@ func (c *SentinelClient) runRealtimeConfigSession(ctx context.Context, onUpdate
 			)
 		},
 	})
+	if ctx.Err() == nil && errors.Is(context.Cause(sessionCtx), ErrResubscribeRequested) {
+		runErr = ErrResubscribeRequested
+	}
 	if IsUnauthorized(runErr) {
 		c.logger.Warn("realtime stream unauthorized; attempting short session refresh", logging.Field("error", runErr))
 		refreshed, refreshErr := c.RefreshSession(ctx, session.Token)
 		if refreshErr == nil {
 			c.logger.Info("realtime short session refresh succeeded; reconnecting stream")
-			return c.runRealtimeConfigSession(ctx, onUpdate, hooks, &refreshed, epoch)
+			return c.runRealtimeConfigSession(ctx, onUpdate, hooks, &refreshed, cursor, epoch)
 		}
 		c.logger.Warn("realtime short session refresh failed", logging.Field("error", refreshErr))
 	}
@@ -267,3 +426,23 @@ func (c *SentinelClient) runRealtimeConfigSession(ctx context.Context, onUpdate
 	}
 	return runErr
 }
+
+func (c *SentinelClient) handleRealtimeCommand(event pbrealtime.Event, hooks SyncHooks) {
+	cmd := Command{}
+	if err := json.Unmarshal(event.Data, &cmd); err != nil {
+		c.logger.Warn("failed to decode realtime command", logging.Field("error", err))
+		return
+	}
+	cmd.Name = strings.ToLower(strings.TrimSpace(cmd.Name))
+	if cmd.Name == "" {
+		c.logger.Warn("realtime command had no name", logging.Field("id", cmd.ID))
+		return
+	}
+	c.logger.Debug("received realtime command",
+		logging.Field("id", cmd.ID),
+		logging.Field("command", cmd.Name),
+	)
+	if hooks.OnCommand != nil {
+		hooks.OnCommand(cmd)
+	}
+}
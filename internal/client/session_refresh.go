@@ -36,7 +36,7 @@ func (c *SentinelClient) RefreshSession(ctx context.Context, sessionToken string
 			logging.Field("status", resp.Status),
 			logging.Field("response", logging.FormatHTTPPayload(data)),
 		)
-		return pbrealtime.Session{}, &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+		return pbrealtime.Session{}, statusError(resp)
 	}
 
 	session := pbrealtime.Session{}
@@ -3,7 +3,10 @@ package client
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"strings"
@@ -11,6 +14,24 @@ import (
 	"sentinel2-uploader/internal/logging"
 )
 
+// IdempotencyKeyHeader carries SubmitPayload.IdempotencyKey on submits. Batch
+// submits send each report's key as its "idempotency_key" field instead.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotencyKey identifies a report by channel, character and line. The line
+// keeps its in-game timestamp, so the key is the same for every resend of one
+// report, across session refreshes and restarts, and servers can use it to
+// collapse duplicates.
+//
+// The key names one uploader's resends, not the line: the character is part
+// of it because each uploader only knows its own listener, so the same line
+// uploaded by pilots in the same channel gets a different key from each.
+// Collapsing those is left to the server, by channel and line.
+func (p SubmitPayload) IdempotencyKey() string {
+	sum := sha256.Sum256([]byte(p.ChannelID + "\x00" + p.CharacterID + "\x00" + p.Text))
+	return hex.EncodeToString(sum[:])
+}
+
 func (c *SentinelClient) Submit(ctx context.Context, payload SubmitPayload, sessionToken string) error {
 	token := strings.TrimSpace(sessionToken)
 	if token == "" {
@@ -31,6 +52,7 @@ func (c *SentinelClient) Submit(ctx context.Context, payload SubmitPayload, sess
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set(IdempotencyKeyHeader, payload.IdempotencyKey())
 
 	resp, err := c.http.Do(req)
 	if err != nil {
@@ -47,8 +69,105 @@ func (c *SentinelClient) Submit(ctx context.Context, payload SubmitPayload, sess
 			logging.Field("channel_id", payload.ChannelID),
 			logging.Field("response", formatted),
 		)
-		return &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+		return statusError(resp)
 	}
 	c.logger.Debug("report submit accepted", logging.Field("channel_id", payload.ChannelID))
 	return nil
 }
+
+// Err is the error a single submit of the report would have returned: nil
+// when it was accepted and a status error when the server gave the item a
+// status. Without one the failure is not taken for a rejection.
+func (r SubmitResult) Err() error {
+	if r.OK {
+		return nil
+	}
+	message := strings.TrimSpace(r.Error)
+	if message == "" {
+		message = "report failed in batch submit"
+	}
+	switch {
+	case r.Status == http.StatusTooManyRequests:
+		return &RateLimitError{Status: message}
+	case r.Status != 0:
+		return &HTTPStatusError{StatusCode: r.Status, Status: message}
+	default:
+		return errors.New(message)
+	}
+}
+
+// SubmitBatch submits several reports in one request and returns one result
+// per payload, in payload order. Servers without the batch endpoint answer
+// 404/405; that is reported as ErrBatchSubmitUnsupported and remembered so
+// callers can fall back to Submit.
+func (c *SentinelClient) SubmitBatch(ctx context.Context, payloads []SubmitPayload, sessionToken string) ([]SubmitResult, error) {
+	token := strings.TrimSpace(sessionToken)
+	if token == "" {
+		return nil, &HTTPStatusError{StatusCode: http.StatusUnauthorized, Status: "missing uploader realtime session token"}
+	}
+	if !c.BatchSubmitSupported() {
+		return nil, ErrBatchSubmitUnsupported
+	}
+	items := make([]submitBatchItem, len(payloads))
+	for i, payload := range payloads {
+		items[i] = submitBatchItem{SubmitPayload: payload, IdempotencyKey: payload.IdempotencyKey()}
+	}
+	body, err := json.Marshal(submitBatchRequest{Reports: items})
+	if err != nil {
+		return nil, err
+	}
+	c.logger.Debug("submitting report batch",
+		logging.Field("count", len(payloads)),
+		logging.Field("payload", logging.FormatHTTPPayload(body)),
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.endpoints.SubmitBatchURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	c.logger.Debugf("PUT %s -> %s", c.endpoints.SubmitBatchURL, resp.Status)
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed {
+		c.batchUnsupported.Store(true)
+		c.logger.Info("batch submit endpoint unavailable; using single submits", logging.Field("status", resp.Status))
+		return nil, ErrBatchSubmitUnsupported
+	}
+	if resp.StatusCode >= 400 {
+		c.logger.Warn("batch submit rejected",
+			logging.Field("status", resp.Status),
+			logging.Field("count", len(payloads)),
+			logging.Field("response", logging.FormatHTTPPayload(data)),
+		)
+		return nil, statusError(resp)
+	}
+
+	results := make([]SubmitResult, len(payloads))
+	for i := range results {
+		results[i].OK = true
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		c.logger.Debug("report batch accepted", logging.Field("count", len(payloads)))
+		return results, nil
+	}
+	var decoded submitBatchResponse
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		// The reports were accepted; an unreadable body must not cause a resend.
+		c.logger.Warn("invalid batch submit response JSON",
+			logging.Field("error", err),
+			logging.Field("response", logging.FormatHTTPPayload(data)),
+		)
+		return results, nil
+	}
+	copy(results, decoded.Results)
+	c.logger.Debug("report batch accepted", logging.Field("count", len(payloads)))
+	return results, nil
+}
@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"strings"
@@ -24,6 +25,9 @@ func TestSubmit_SetsHeadersAndPayload(t *testing.T) {
 			if got := r.Header.Get("Content-Type"); got != "application/json" {
 				t.Fatalf("Content-Type = %q, want application/json", got)
 			}
+			if got, want := r.Header.Get(IdempotencyKeyHeader), (SubmitPayload{ChannelID: "abc", Text: "report text"}).IdempotencyKey(); got != want {
+				t.Fatalf("%s = %q, want %q", IdempotencyKeyHeader, got, want)
+			}
 			var payload SubmitPayload
 			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 				t.Fatalf("decode payload: %v", err)
@@ -52,6 +56,32 @@ func TestSubmit_SetsHeadersAndPayload(t *testing.T) {
 	}
 }
 
+func TestSubmitPayload_IdempotencyKey(t *testing.T) {
+	line := "[ 2026.02.14 12:00:00 ] Pilot > HED-GP clr"
+	base := SubmitPayload{ChannelID: "c1", CharacterID: "9001", Text: line}
+	if base.IdempotencyKey() != base.IdempotencyKey() {
+		t.Fatal("IdempotencyKey() is not deterministic")
+	}
+	withMetadata := base
+	withMetadata.ReporterSystem = "HED-GP"
+	withMetadata.UploaderInstanceID = "instance"
+	if withMetadata.IdempotencyKey() != base.IdempotencyKey() {
+		t.Fatal("IdempotencyKey() changed with reporter metadata")
+	}
+	for name, other := range map[string]SubmitPayload{
+		"channel":   {ChannelID: "c2", CharacterID: "9001", Text: line},
+		"character": {ChannelID: "c1", CharacterID: "9002", Text: line},
+		"timestamp": {ChannelID: "c1", CharacterID: "9001", Text: "[ 2026.02.14 12:00:01 ] Pilot > HED-GP clr"},
+	} {
+		if other.IdempotencyKey() == base.IdempotencyKey() {
+			t.Fatalf("IdempotencyKey() ignores a different %s", name)
+		}
+	}
+	if encoded, _ := json.Marshal(base); strings.Contains(string(encoded), "9001") {
+		t.Fatalf("Marshal() = %s, want no character ID", encoded)
+	}
+}
+
 func TestSubmit_ReturnsErrorOnHTTPFailure(t *testing.T) {
 	httpClient := &http.Client{
 		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
@@ -75,3 +105,85 @@ func TestSubmit_ReturnsErrorOnHTTPFailure(t *testing.T) {
 		t.Fatalf("Submit() expected error for HTTP status >= 400")
 	}
 }
+
+func TestSubmitBatch_ReturnsPerItemResults(t *testing.T) {
+	httpClient := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			if got := r.URL.Path; got != "/uploader/submit/batch" {
+				t.Fatalf("path = %q, want /uploader/submit/batch", got)
+			}
+			var body submitBatchRequest
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Fatalf("decode payload: %v", err)
+			}
+			if len(body.Reports) != 2 || body.Reports[1].Text != "second" {
+				t.Fatalf("batch payload = %#v", body)
+			}
+			for _, report := range body.Reports {
+				if want := report.SubmitPayload.IdempotencyKey(); report.IdempotencyKey != want {
+					t.Fatalf("batch item %q idempotency_key = %q, want %q", report.Text, report.IdempotencyKey, want)
+				}
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Status:     "200 OK",
+				Header:     make(http.Header),
+				Body:       io.NopCloser(strings.NewReader(`{"results":[{"ok":true},{"ok":false,"error":"unknown channel"}]}`)),
+				Request:    r,
+			}, nil
+		}),
+	}
+
+	c := New(
+		httpClient,
+		"token-123",
+		config.APIEndpoints{SubmitBatchURL: "https://example.test/uploader/submit/batch"},
+		logging.New(false),
+	)
+	results, err := c.SubmitBatch(context.Background(), []SubmitPayload{
+		{ChannelID: "abc", Text: "first"},
+		{ChannelID: "missing", Text: "second"},
+	}, "session-123")
+	if err != nil {
+		t.Fatalf("SubmitBatch() error = %v", err)
+	}
+	if len(results) != 2 || !results[0].OK || results[1].OK || results[1].Error != "unknown channel" {
+		t.Fatalf("SubmitBatch() results = %#v", results)
+	}
+}
+
+func TestSubmitBatch_NotFoundDisablesBatching(t *testing.T) {
+	calls := 0
+	httpClient := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			return &http.Response{
+				StatusCode: http.StatusNotFound,
+				Status:     "404 Not Found",
+				Header:     make(http.Header),
+				Body:       io.NopCloser(strings.NewReader("")),
+				Request:    r,
+			}, nil
+		}),
+	}
+
+	c := New(
+		httpClient,
+		"token-123",
+		config.APIEndpoints{SubmitBatchURL: "https://example.test/uploader/submit/batch"},
+		logging.New(false),
+	)
+	payloads := []SubmitPayload{{ChannelID: "abc", Text: "first"}, {ChannelID: "abc", Text: "second"}}
+	if _, err := c.SubmitBatch(context.Background(), payloads, "session-123"); !errors.Is(err, ErrBatchSubmitUnsupported) {
+		t.Fatalf("SubmitBatch() error = %v, want ErrBatchSubmitUnsupported", err)
+	}
+	if c.BatchSubmitSupported() {
+		t.Fatalf("BatchSubmitSupported() = true after 404")
+	}
+	if _, err := c.SubmitBatch(context.Background(), payloads, "session-123"); !errors.Is(err, ErrBatchSubmitUnsupported) {
+		t.Fatalf("second SubmitBatch() error = %v, want ErrBatchSubmitUnsupported", err)
+	}
+	if calls != 1 {
+		t.Fatalf("batch endpoint calls = %d, want 1", calls)
+	}
+}
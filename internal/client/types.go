@@ -1,15 +1,91 @@
 package client
 
+import (
+	"encoding/json"
+
+	"sentinel2-uploader/internal/config"
+)
+
 type SubmitPayload struct {
 	Text      string `json:"text"`
 	ChannelID string `json:"channel_id"`
+	// Intel is the uploader's reading of Text. It is omitted when nothing was
+	// recognised; the server may use it instead of parsing Text again.
+	Intel *ReportIntel `json:"intel,omitempty"`
+	// CharacterID is the character whose log held Text. It only feeds
+	// IdempotencyKey and is never sent as a field.
+	CharacterID string `json:"-"`
+
+	// The reporter fields are only sent to servers advertising
+	// CapabilityReporterMetadata.
+
+	// ReporterSystem is the solar system the reporting character was in,
+	// taken from their Local chat log.
+	ReporterSystem      string `json:"reporter_system,omitempty"`
+	ReporterCharacterID string `json:"reporter_character_id,omitempty"`
+	// ReportTime is the time in the log line, in RFC3339.
+	ReportTime string `json:"report_time,omitempty"`
+	// UploaderInstanceID identifies this uploader install.
+	UploaderInstanceID string `json:"uploader_instance_id,omitempty"`
+}
+
+type ReportIntel struct {
+	Systems  []string    `json:"systems,omitempty"`
+	Ships    []ShipCount `json:"ships,omitempty"`
+	Pilots   int         `json:"pilots,omitempty"`
+	Clear    bool        `json:"clear,omitempty"`
+	NoVisual bool        `json:"no_visual,omitempty"`
+	Gates    []string    `json:"gates,omitempty"`
+}
+
+type ShipCount struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+type SubmitResult struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+	// Status is the HTTP status the report would have got on its own, when
+	// the server gives one.
+	Status int `json:"status,omitempty"`
+}
+
+type submitBatchRequest struct {
+	Reports []submitBatchItem `json:"reports"`
+}
+
+// submitBatchItem carries in the body the idempotency key a single submit
+// sends as IdempotencyKeyHeader.
+type submitBatchItem struct {
+	SubmitPayload
+	IdempotencyKey string `json:"idempotency_key"`
+}
+
+type submitBatchResponse struct {
+	Results []SubmitResult `json:"results"`
 }
 
 type ChannelConfig struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
+	// EVEChannelID is the in-game channel ID as written in chat log headers.
+	// When set, logs are matched by it instead of by channel name.
+	EVEChannelID string `json:"eve_channel_id,omitempty"`
 }
 
 type uploaderConfigResponse struct {
 	Channels []ChannelConfig `json:"channels"`
+	// FilterRules is nil when the server does not manage filter rules.
+	FilterRules *[]config.FilterRule `json:"filter_rules"`
+	// Capabilities is nil when the server does not advertise any.
+	Capabilities *[]string `json:"capabilities"`
+}
+
+// Command is an instruction the server pushes on the realtime command topic.
+// Args is decoded by the handler registered for Name.
+type Command struct {
+	ID   string          `json:"id"`
+	Name string          `json:"command"`
+	Args json.RawMessage `json:"args,omitempty"`
 }
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"slices"
+	"strings"
+)
+
+// Character filter modes. The zero value uploads from every character.
+const (
+	CharacterFilterOff   = ""
+	CharacterFilterAllow = "allow"
+	CharacterFilterDeny  = "deny"
+)
+
+// CharacterFilter limits which characters' chat logs are uploaded. In allow
+// mode only the listed characters upload; in deny mode everyone but them.
+type CharacterFilter struct {
+	Mode       string           `json:"mode,omitempty"`
+	Characters []CharacterEntry `json:"characters,omitempty"`
+}
+
+// CharacterEntry identifies a character by the ID EVE puts in chat log file
+// names, with a label so people can tell their alts apart.
+type CharacterEntry struct {
+	ID    string `json:"id"`
+	Label string `json:"label,omitempty"`
+}
+
+func (f CharacterFilter) Equal(other CharacterFilter) bool {
+	return f.Mode == other.Mode && slices.Equal(f.Characters, other.Characters)
+}
+
+// Enabled reports whether the filter restricts anything. Deny mode with an
+// empty list is a no-op; allow mode with an empty list blocks everyone.
+func (f CharacterFilter) Enabled() bool {
+	switch f.Mode {
+	case CharacterFilterAllow:
+		return true
+	case CharacterFilterDeny:
+		return len(f.Characters) > 0
+	default:
+		return false
+	}
+}
+
+// Allows reports whether the chat logs of the character with the given ID,
+// as found in log file names, should be uploaded.
+func (f CharacterFilter) Allows(characterID string) bool {
+	characterID = strings.TrimSpace(characterID)
+	listed := slices.ContainsFunc(f.Characters, func(entry CharacterEntry) bool {
+		return strings.TrimSpace(entry.ID) == characterID
+	})
+	switch NormalizeCharacterFilterMode(f.Mode) {
+	case CharacterFilterAllow:
+		return listed
+	case CharacterFilterDeny:
+		return !listed
+	default:
+		return true
+	}
+}
+
+// Describe returns the character's label with its ID, or just the ID.
+func (f CharacterFilter) Describe(characterID string) string {
+	for _, entry := range f.Characters {
+		if strings.TrimSpace(entry.ID) == characterID && strings.TrimSpace(entry.Label) != "" {
+			return strings.TrimSpace(entry.Label) + " (" + characterID + ")"
+		}
+	}
+	return characterID
+}
+
+// NormalizeCharacterFilterMode maps user input to a known mode, treating
+// anything unrecognized as off.
+func NormalizeCharacterFilterMode(mode string) string {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case CharacterFilterAllow:
+		return CharacterFilterAllow
+	case CharacterFilterDeny:
+		return CharacterFilterDeny
+	default:
+		return CharacterFilterOff
+	}
+}
+
+// NextCharacterFilterMode cycles off → allow → deny → off.
+func NextCharacterFilterMode(mode string) string {
+	switch NormalizeCharacterFilterMode(mode) {
+	case CharacterFilterOff:
+		return CharacterFilterAllow
+	case CharacterFilterAllow:
+		return CharacterFilterDeny
+	default:
+		return CharacterFilterOff
+	}
+}
+
+// ParseCharacterList parses "id=Label" entries separated by commas or new
+// lines, as typed into the settings forms. The label is optional. Later
+// duplicates of an ID are dropped.
+func ParseCharacterList(value string) []CharacterEntry {
+	fields := strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '\n' })
+	out := make([]CharacterEntry, 0, len(fields))
+	seen := map[string]struct{}{}
+	for _, field := range fields {
+		id, label, _ := strings.Cut(field, "=")
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, CharacterEntry{ID: id, Label: strings.TrimSpace(label)})
+	}
+	return out
+}
+
+// FormatCharacterList is the inverse of ParseCharacterList.
+func FormatCharacterList(entries []CharacterEntry) string {
+	parts := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Label == "" {
+			parts = append(parts, entry.ID)
+			continue
+		}
+		parts = append(parts, entry.ID+"="+entry.Label)
+	}
+	return strings.Join(parts, ", ")
+}
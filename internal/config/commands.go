@@ -0,0 +1,195 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	flags "github.com/jessevdk/go-flags"
+	"github.com/joho/godotenv"
+)
+
+// Subcommands. CommandConfigGet and CommandConfigSet are nested under
+// "config" and reported with both names.
+const (
+	CommandRun       = "run"
+	CommandCheck     = "check"
+	CommandChannels  = "channels"
+	CommandReplay    = "replay"
+	CommandConfigGet = "config get"
+	CommandConfigSet = "config set"
+)
+
+// CommandLine is the parsed command line. Command is CommandRun when no
+// subcommand was given.
+type CommandLine struct {
+	Options   Options
+	Command   string
+	Run       RunOptions
+	Check     CheckOptions
+	Channels  ChannelsOptions
+	Replay    ReplayOptions
+	ConfigGet ConfigGetOptions
+	ConfigSet ConfigSetOptions
+}
+
+// JSON reports whether the active subcommand asked for JSON output.
+func (c CommandLine) JSON() bool {
+	switch c.Command {
+	case CommandRun:
+		return c.Run.JSON
+	case CommandCheck:
+		return c.Check.JSON
+	case CommandChannels:
+		return c.Channels.JSON
+	case CommandReplay:
+		return c.Replay.JSON
+	case CommandConfigGet:
+		return c.ConfigGet.JSON
+	case CommandConfigSet:
+		return c.ConfigSet.JSON
+	}
+	return false
+}
+
+// OutputOptions is shared by every subcommand.
+type OutputOptions struct {
+	JSON bool `long:"json" description:"Print machine-readable JSON"`
+}
+
+// RunOptions are the flags of the run subcommand. With --json the uploader
+// runs as a daemon that prints every event to stdout.
+type RunOptions struct {
+	OutputOptions
+}
+
+type CheckOptions struct {
+	OutputOptions
+}
+
+type ChannelsOptions struct {
+	OutputOptions
+}
+
+type ConfigGetOptions struct {
+	OutputOptions
+	ShowSecrets bool `long:"show-secrets" description:"Print the token when printing all settings instead of masking it"`
+	Args        struct {
+		Key string `positional-arg-name:"KEY" description:"Setting to print; all settings when omitted"`
+	} `positional-args:"yes"`
+}
+
+type ConfigSetOptions struct {
+	OutputOptions
+	Args struct {
+		Key   string `positional-arg-name:"KEY" required:"yes" description:"Setting to change"`
+		Value string `positional-arg-name:"VALUE" required:"yes" description:"New value, as JSON or plain text"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+// ReplayOptions are the flags of the replay subcommand.
+type ReplayOptions struct {
+	OutputOptions
+	From   ReplayTime `long:"from" description:"Only replay reports at or after this time (UTC, e.g. 2026-02-16 19:30)"`
+	To     ReplayTime `long:"to" description:"Only replay reports at or before this time (UTC)"`
+	DryRun bool       `long:"dry-run" description:"Print the reports that would be sent instead of submitting them"`
+	Rate   float64    `long:"rate" default:"5" description:"Maximum reports submitted per second"`
+	Args   struct {
+		Paths []string `positional-arg-name:"PATH" required:"1" description:"Chat log file or directory of chat logs"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+// ReplayTime is a UTC time flag. EVE logs are written in UTC, so times
+// without a zone are read as UTC.
+type ReplayTime struct {
+	time.Time
+}
+
+var replayTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006.01.02 15:04:05",
+	"2006.01.02 15:04",
+	"2006-01-02",
+}
+
+func (t *ReplayTime) UnmarshalFlag(value string) error {
+	value = strings.TrimSpace(value)
+	for _, layout := range replayTimeLayouts {
+		parsed, err := time.ParseInLocation(layout, value, time.UTC)
+		if err == nil {
+			t.Time = parsed.UTC()
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid time %q: use RFC3339 or YYYY-MM-DD HH:MM[:SS] in UTC", value)
+}
+
+// ParseCommandLine parses the global options and an optional subcommand.
+func ParseCommandLine(defaultLogDirFn func() string) (CommandLine, error) {
+	return parseCommandLine(nil, defaultLogDirFn)
+}
+
+// parseCommandLine parses args, or os.Args when args is nil.
+func parseCommandLine(args []string, defaultLogDirFn func() string) (CommandLine, error) {
+	_ = godotenv.Load()
+	var line CommandLine
+	parser := flags.NewParser(&line.Options, flags.Default)
+	parser.SubcommandsOptional = true
+	commands := []struct {
+		name, short, long string
+		data              any
+	}{
+		{CommandRun, "Run the uploader (default)", "Runs the GUI or TUI as without a subcommand. With --json it runs without a UI and prints events as JSON lines.", &line.Run},
+		{CommandCheck, "Check the server, token and log directory", "Validates the base URL and token, fetches the configured channels and prints per-channel log health.", &line.Check},
+		{CommandChannels, "List the channels configured on the server", "", &line.Channels},
+		{CommandReplay, "Upload reports from historical chat logs", "Reads chat log files, or every channel log in a directory, and submits their reports oldest first with their original timestamps.", &line.Replay},
+	}
+	for _, command := range commands {
+		if _, err := parser.AddCommand(command.name, command.short, command.long, command.data); err != nil {
+			return CommandLine{}, err
+		}
+	}
+	configCommand, err := parser.AddCommand("config", "Read or change saved settings", "Reads or writes the saved uploader settings file.", &struct{}{})
+	if err != nil {
+		return CommandLine{}, err
+	}
+	if _, err := configCommand.AddCommand("get", "Print saved settings", "", &line.ConfigGet); err != nil {
+		return CommandLine{}, err
+	}
+	if _, err := configCommand.AddCommand("set", "Change a saved setting", "", &line.ConfigSet); err != nil {
+		return CommandLine{}, err
+	}
+
+	if args == nil {
+		args = os.Args[1:]
+	}
+	if _, err := parser.ParseArgs(args); err != nil {
+		return CommandLine{}, err
+	}
+	line.Command = CommandRun
+	for active := parser.Active; active != nil; active = active.Active {
+		if active == parser.Active {
+			line.Command = active.Name
+		} else {
+			line.Command += " " + active.Name
+		}
+	}
+	if line.Command == CommandReplay && !line.Replay.From.IsZero() && !line.Replay.To.IsZero() && line.Replay.To.Before(line.Replay.From.Time) {
+		return CommandLine{}, fmt.Errorf("--to must not be before --from")
+	}
+	if strings.EqualFold(strings.TrimSpace(line.Options.LogDir), LogDirAuto) {
+		dir, err := AutoLogDir()
+		if err != nil {
+			return CommandLine{}, err
+		}
+		line.Options.LogDir = dir
+	}
+	if line.Options.LogDir == "" && line.Options.LogFile == "" && defaultLogDirFn != nil {
+		line.Options.LogDir = defaultLogDirFn()
+	}
+	return line, nil
+}
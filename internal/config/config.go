@@ -2,79 +2,168 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"net"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
-
-	flags "github.com/jessevdk/go-flags"
-	"github.com/joho/godotenv"
+	"time"
 )
 
 type Options struct {
-	BaseURL     string `long:"base-url" env:"SENTINEL_BASE_URL" description:"Sentinel base URL (e.g. https://intel.example.com)"`
-	Token       string `long:"token" env:"SENTINEL_TOKEN" description:"Uploader token"`
-	Headless    bool   `long:"headless" env:"SENTINEL_HEADLESS" description:"Run uploader in headless mode (GUI builds only)"`
-	AutoConnect bool   `long:"auto-connect" env:"AUTO_CONNECT" description:"Auto-connect on startup when base URL and token are configured"`
-	ImGay       bool   `long:"imgay" description:"Enable rainbow border animation in headless TUI"`
-	LogFile     string `long:"log-file" env:"SENTINEL_LOG_FILE" description:"EVE chat log file to watch"`
-	LogDir      string `long:"log-dir" env:"SENTINEL_LOG_DIR" description:"Directory containing EVE chat logs"`
-	Debug       bool   `long:"debug" env:"SENTINEL_DEBUG" description:"Enable verbose debug output"`
+	BaseURL           string        `long:"base-url" env:"SENTINEL_BASE_URL" description:"Sentinel base URL (e.g. https://intel.example.com)"`
+	Token             string        `long:"token" env:"SENTINEL_TOKEN" description:"Uploader token"`
+	Headless          bool          `long:"headless" env:"SENTINEL_HEADLESS" description:"Run uploader in headless mode (GUI builds only)"`
+	Daemon            bool          `long:"daemon" env:"SENTINEL_DAEMON" description:"Run without a UI, connecting at once and logging JSON lines to stdout/stderr; SIGHUP reloads settings"`
+	AutoConnect       bool          `long:"auto-connect" env:"AUTO_CONNECT" description:"Auto-connect on startup when base URL and token are configured"`
+	ImGay             bool          `long:"imgay" description:"Enable rainbow border animation in headless TUI"`
+	LogFile           string        `long:"log-file" env:"SENTINEL_LOG_FILE" description:"EVE chat log file to watch"`
+	LogDir            string        `long:"log-dir" env:"SENTINEL_LOG_DIR" description:"Directory containing EVE chat logs; auto picks the most recently active one found in native, Steam Proton, Wine or Lutris installs"`
+	ExtraLogDirs      []string      `long:"extra-log-dir" env:"SENTINEL_EXTRA_LOG_DIRS" env-delim:"," description:"Additional directory containing EVE chat logs (repeatable)"`
+	Debug             bool          `long:"debug" env:"SENTINEL_DEBUG" description:"Enable verbose debug output"`
+	FilterDryRun      bool          `long:"filter-dry-run" env:"SENTINEL_FILTER_DRY_RUN" description:"Count lines filter rules would drop without dropping them"`
+	RedactURLs        bool          `long:"redact-urls" env:"SENTINEL_REDACT_URLS" description:"Replace URLs in report messages with a placeholder before upload"`
+	RedactEmails      bool          `long:"redact-emails" env:"SENTINEL_REDACT_EMAILS" description:"Replace email addresses in report messages with a placeholder before upload"`
+	RealtimeTransport string        `long:"realtime-transport" env:"SENTINEL_REALTIME_TRANSPORT" choice:"auto" choice:"sse" choice:"websocket" description:"Realtime transport; auto starts with SSE and switches to WebSocket after repeated stream failures on servers that offer it"`
+	ResumeMaxAge      time.Duration `long:"resume-max-age" env:"SENTINEL_RESUME_MAX_AGE" description:"Resume chat logs from offsets saved within this long (default 10m, negative disables)"`
+	OutboxMaxAge      time.Duration `long:"outbox-max-age" env:"SENTINEL_OUTBOX_MAX_AGE" description:"Drop reports waiting in the outbox once they are older than this (default 10m)"`
+	MockServer        string        `long:"mock-server" optional:"yes" optional-value:"127.0.0.1:0" description:"Development: serve a local mock Sentinel backend on this address and connect to it"`
+	StatusAddr        string        `long:"status-addr" env:"SENTINEL_STATUS_ADDR" description:"Serve /healthz, /status and /metrics on this loopback address (e.g. 127.0.0.1:9464)"`
+	RemoteCommands    []string      `long:"remote-command" env:"SENTINEL_REMOTE_COMMANDS" env-delim:"," description:"Server command to act on: resubscribe, diagnostics, log_level or message (repeatable; default resubscribe and message, none disables)"`
+
+	// CharacterFilter, FilterRules and RedactionRules are only configurable
+	// through saved settings.
+	CharacterFilter CharacterFilter `no-flag:"true"`
+	FilterRules     []FilterRule    `no-flag:"true"`
+	RedactionRules  []RedactionRule `no-flag:"true"`
 }
 
+const (
+	RealtimeTransportAuto      = "auto"
+	RealtimeTransportSSE       = "sse"
+	RealtimeTransportWebSocket = "websocket"
+)
+
 type APIEndpoints struct {
 	BaseURL           string
 	ConfigURL         string
 	HeartbeatURL      string
 	SessionRefreshURL string
 	SubmitURL         string
+	SubmitBatchURL    string
+	DiagnosticsURL    string
 	RealtimeTokenURL  string
 	RealtimeURL       string
+	// RealtimeWebSocketURL keeps the http(s) scheme; the client upgrades it.
+	RealtimeWebSocketURL string
 }
 
 const (
 	realtimeTokenPath  = "/uploader/realtime/token"
 	realtimeEventsURL  = "/realtime"
+	realtimeSocketPath = "/realtime/ws"
 	heartbeatPath      = "/uploader/heartbeat"
 	sessionRefreshPath = "/uploader/session/refresh"
+	submitBatchPath    = "/uploader/submit/batch"
+	diagnosticsPath    = "/uploader/diagnostics"
 )
 
-func ParseOptions(defaultLogDirFn func() string) (Options, error) {
-	_ = godotenv.Load()
-	opts := Options{}
-	if _, err := flags.Parse(&opts); err != nil {
-		return Options{}, err
+func ValidateRequired(opts Options) error {
+	if err := ValidateServer(opts); err != nil {
+		return err
 	}
-	if opts.LogDir == "" && opts.LogFile == "" && defaultLogDirFn != nil {
-		opts.LogDir = defaultLogDirFn()
+	if strings.TrimSpace(opts.LogFile) == "" && strings.TrimSpace(opts.LogDir) == "" {
+		return errors.New("set either log file or log directory")
 	}
-	return opts, nil
+	return ValidateStatusAddr(opts.StatusAddr)
 }
 
-func ValidateRequired(opts Options) error {
+// ValidateStatusAddr accepts an empty address or a host:port on a loopback
+// interface. The status endpoint is unauthenticated, so it is never exposed
+// on other interfaces.
+func ValidateStatusAddr(addr string) error {
+	addr = strings.TrimSpace(addr)
+	if addr == "" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid status address: %w", err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("status address %q must be on a loopback interface", addr)
+	}
+	return nil
+}
+
+// ValidateServer checks the settings needed to talk to the server at all.
+func ValidateServer(opts Options) error {
 	if strings.TrimSpace(opts.BaseURL) == "" {
 		return errors.New("base URL is required")
 	}
 	if strings.TrimSpace(opts.Token) == "" {
 		return errors.New("uploader token is required")
 	}
-	if strings.TrimSpace(opts.LogFile) == "" && strings.TrimSpace(opts.LogDir) == "" {
-		return errors.New("set either log file or log directory")
-	}
 	return nil
 }
 
+// LogRoots returns the primary log directory followed by any extra roots,
+// trimmed and with duplicates removed.
+func (o Options) LogRoots() []string {
+	return NormalizeLogDirs(append([]string{o.LogDir}, o.ExtraLogDirs...))
+}
+
+// NormalizeLogDirs trims and cleans dirs, dropping blanks and duplicates while
+// keeping the original order.
+func NormalizeLogDirs(dirs []string) []string {
+	out := make([]string, 0, len(dirs))
+	seen := make(map[string]struct{}, len(dirs))
+	for _, dir := range dirs {
+		dir = strings.TrimSpace(dir)
+		if dir == "" {
+			continue
+		}
+		dir = filepath.Clean(dir)
+		if _, ok := seen[dir]; ok {
+			continue
+		}
+		seen[dir] = struct{}{}
+		out = append(out, dir)
+	}
+	return out
+}
+
+// SplitLogDirs parses a list of directories joined with the OS path list
+// separator, as typed into the settings forms.
+func SplitLogDirs(value string) []string {
+	return NormalizeLogDirs(filepath.SplitList(value))
+}
+
+// JoinLogDirs is the inverse of SplitLogDirs.
+func JoinLogDirs(dirs []string) string {
+	return strings.Join(NormalizeLogDirs(dirs), string(os.PathListSeparator))
+}
+
 func BuildEndpoints(rawBaseURL string) (APIEndpoints, error) {
 	apiBaseURL, err := buildAPIBaseURL(rawBaseURL)
 	if err != nil {
 		return APIEndpoints{}, err
 	}
 	return APIEndpoints{
-		BaseURL:           apiBaseURL,
-		ConfigURL:         apiBaseURL + "/uploader/config",
-		HeartbeatURL:      apiBaseURL + heartbeatPath,
-		SessionRefreshURL: apiBaseURL + sessionRefreshPath,
-		SubmitURL:         apiBaseURL + "/uploader/submit",
-		RealtimeTokenURL:  apiBaseURL + realtimeTokenPath,
-		RealtimeURL:       apiBaseURL + realtimeEventsURL,
+		BaseURL:              apiBaseURL,
+		ConfigURL:            apiBaseURL + "/uploader/config",
+		HeartbeatURL:         apiBaseURL + heartbeatPath,
+		SessionRefreshURL:    apiBaseURL + sessionRefreshPath,
+		SubmitURL:            apiBaseURL + "/uploader/submit",
+		SubmitBatchURL:       apiBaseURL + submitBatchPath,
+		DiagnosticsURL:       apiBaseURL + diagnosticsPath,
+		RealtimeTokenURL:     apiBaseURL + realtimeTokenPath,
+		RealtimeURL:          apiBaseURL + realtimeEventsURL,
+		RealtimeWebSocketURL: apiBaseURL + realtimeSocketPath,
 	}, nil
 }
 
@@ -1,6 +1,10 @@
 package config
 
-import "testing"
+import (
+	"slices"
+	"testing"
+	"time"
+)
 
 func TestBuildEndpoints_NormalizeAPIBaseURL(t *testing.T) {
 	tests := []struct {
@@ -35,6 +39,15 @@ func TestBuildEndpoints_NormalizeAPIBaseURL(t *testing.T) {
 			if endpoints.SessionRefreshURL != tt.want+"/uploader/session/refresh" {
 				t.Fatalf("SessionRefreshURL = %q", endpoints.SessionRefreshURL)
 			}
+			if endpoints.SubmitBatchURL != tt.want+"/uploader/submit/batch" {
+				t.Fatalf("SubmitBatchURL = %q", endpoints.SubmitBatchURL)
+			}
+			if endpoints.DiagnosticsURL != tt.want+"/uploader/diagnostics" {
+				t.Fatalf("DiagnosticsURL = %q", endpoints.DiagnosticsURL)
+			}
+			if endpoints.RealtimeWebSocketURL != tt.want+"/realtime/ws" {
+				t.Fatalf("RealtimeWebSocketURL = %q", endpoints.RealtimeWebSocketURL)
+			}
 		})
 	}
 }
@@ -53,3 +66,102 @@ func TestBuildEndpoints_InvalidScheme(t *testing.T) {
 		})
 	}
 }
+
+func TestRemoteCommandAllowed(t *testing.T) {
+	tests := []struct {
+		allow []string
+		name  string
+		want  bool
+	}{
+		{nil, RemoteCommandResubscribe, true},
+		{nil, RemoteCommandMessage, true},
+		{nil, RemoteCommandDiagnostics, false},
+		{nil, RemoteCommandLogLevel, false},
+		{[]string{"log_level"}, RemoteCommandLogLevel, true},
+		{[]string{" Message "}, RemoteCommandMessage, true},
+		{[]string{"message"}, RemoteCommandDiagnostics, false},
+		{[]string{"message", "none"}, RemoteCommandMessage, false},
+	}
+	for _, tt := range tests {
+		if got := RemoteCommandAllowed(tt.allow, tt.name); got != tt.want {
+			t.Fatalf("RemoteCommandAllowed(%q, %q) = %v, want %v", tt.allow, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRemoteCommandsFromSelection_KeepsAnEmptySelectionOff(t *testing.T) {
+	allow := RemoteCommandsFromSelection(nil)
+	if AllowedRemoteCommands(allow) != nil {
+		t.Fatalf("empty selection allows %q", AllowedRemoteCommands(allow))
+	}
+	allow = ParseRemoteCommandList(FormatRemoteCommandList(RemoteCommandsFromSelection([]string{"Diagnostics", "message"})))
+	if !slices.Equal(allow, []string{RemoteCommandDiagnostics, RemoteCommandMessage}) {
+		t.Fatalf("allowlist = %q", allow)
+	}
+}
+
+func TestValidateStatusAddr_RequiresLoopback(t *testing.T) {
+	for _, addr := range []string{"", "127.0.0.1:9464", "localhost:0", "[::1]:9464"} {
+		if err := ValidateStatusAddr(addr); err != nil {
+			t.Fatalf("ValidateStatusAddr(%q) error = %v", addr, err)
+		}
+	}
+	for _, addr := range []string{":9464", "0.0.0.0:9464", "192.168.1.5:9464", "example.com:80", "127.0.0.1"} {
+		if err := ValidateStatusAddr(addr); err == nil {
+			t.Fatalf("ValidateStatusAddr(%q) error = nil, want error", addr)
+		}
+	}
+}
+
+func TestReplayTime_UnmarshalFlagReadsUTC(t *testing.T) {
+	want := time.Date(2026, 2, 16, 19, 30, 0, 0, time.UTC)
+	for _, value := range []string{"2026-02-16 19:30", "2026.02.16 19:30:00", "2026-02-16T21:30:00+02:00"} {
+		var got ReplayTime
+		if err := got.UnmarshalFlag(value); err != nil {
+			t.Fatalf("UnmarshalFlag(%q) error = %v", value, err)
+		}
+		if !got.Equal(want) {
+			t.Fatalf("UnmarshalFlag(%q) = %s, want %s", value, got.Time, want)
+		}
+	}
+	var bad ReplayTime
+	if err := bad.UnmarshalFlag("yesterday"); err == nil {
+		t.Fatal("UnmarshalFlag(yesterday) error = nil, want error")
+	}
+}
+
+func TestParseCommandLine_Subcommands(t *testing.T) {
+	tests := []struct {
+		args    []string
+		command string
+		json    bool
+	}{
+		{args: []string{}, command: CommandRun},
+		{args: []string{"--headless", "run"}, command: CommandRun},
+		{args: []string{"check", "--json"}, command: CommandCheck, json: true},
+		{args: []string{"--token", "tok", "channels"}, command: CommandChannels},
+		{args: []string{"config", "get", "base_url", "--json"}, command: CommandConfigGet, json: true},
+		{args: []string{"config", "set", "auto_connect", "true"}, command: CommandConfigSet},
+		{args: []string{"replay", "--dry-run", "/tmp/logs"}, command: CommandReplay},
+	}
+	for _, tt := range tests {
+		line, err := parseCommandLine(tt.args, nil)
+		if err != nil {
+			t.Fatalf("parseCommandLine(%q) error = %v", tt.args, err)
+		}
+		if line.Command != tt.command || line.JSON() != tt.json {
+			t.Fatalf("parseCommandLine(%q) = %q json=%v, want %q json=%v", tt.args, line.Command, line.JSON(), tt.command, tt.json)
+		}
+	}
+
+	line, err := parseCommandLine([]string{"config", "set", "base_url", "https://intel.example.com"}, nil)
+	if err != nil {
+		t.Fatalf("parseCommandLine(config set) error = %v", err)
+	}
+	if line.ConfigSet.Args.Key != "base_url" || line.ConfigSet.Args.Value != "https://intel.example.com" {
+		t.Fatalf("config set args = %+v", line.ConfigSet.Args)
+	}
+	if _, err := parseCommandLine([]string{"replay", "--from", "2026-02-16 20:00", "--to", "2026-02-16 19:00", "/tmp/logs"}, nil); err == nil {
+		t.Fatal("parseCommandLine(replay --to before --from) error = nil, want error")
+	}
+}
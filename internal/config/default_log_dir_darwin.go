@@ -2,15 +2,10 @@
 
 package config
 
-import (
-	"os"
-	"path/filepath"
-)
+import "path/filepath"
 
-func DefaultLogDir() string {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return ""
+func logDirProbes(home string) []logDirProbe {
+	return []logDirProbe{
+		{"Native", filepath.Join(home, "Library", "Application Support", "EVE Online", "p_drive", "User", "My Documents", "EVE", "logs", "Chatlogs")},
 	}
-	return filepath.Join(home, "Library", "Application Support", "EVE Online", "p_drive", "User", "My Documents", "EVE", "logs", "Chatlogs")
 }
@@ -2,15 +2,8 @@
 
 package config
 
-import (
-	"os"
-	"path/filepath"
-)
+import "os"
 
-func DefaultLogDir() string {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return ""
-	}
-	return filepath.Join(home, "Documents", "EVE", "logs", "Chatlogs")
+func logDirProbes(home string) []logDirProbe {
+	return unixLogDirProbes(home, os.Getenv)
 }
@@ -2,15 +2,11 @@
 
 package config
 
-import (
-	"os"
-	"path/filepath"
-)
+import "path/filepath"
 
-func DefaultLogDir() string {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return ""
+func logDirProbes(home string) []logDirProbe {
+	return []logDirProbe{
+		{"Native", filepath.Join(home, "Documents", "EVE", "logs", "Chatlogs")},
+		{"OneDrive", filepath.Join(home, "OneDrive", "Documents", "EVE", "logs", "Chatlogs")},
 	}
-	return filepath.Join(home, "Documents", "EVE", "logs", "Chatlogs")
 }
@@ -0,0 +1,43 @@
+package config
+
+import "slices"
+
+const (
+	FilterActionExclude = "exclude"
+	FilterActionInclude = "include"
+)
+
+// FilterRule drops or keeps report lines by author and message regular
+// expressions. It is stored in settings and may also be pushed by the server
+// in the uploader config payload.
+type FilterRule struct {
+	Name     string   `json:"name,omitempty"`
+	Action   string   `json:"action"`
+	Author   string   `json:"author,omitempty"`
+	Message  string   `json:"message,omitempty"`
+	Channels []string `json:"channels,omitempty"`
+}
+
+func (r FilterRule) Equal(other FilterRule) bool {
+	return r.Name == other.Name &&
+		r.Action == other.Action &&
+		r.Author == other.Author &&
+		r.Message == other.Message &&
+		slices.Equal(r.Channels, other.Channels)
+}
+
+func FilterRulesEqual(a []FilterRule, b []FilterRule) bool {
+	return slices.EqualFunc(a, b, FilterRule.Equal)
+}
+
+func cloneFilterRules(rules []FilterRule) []FilterRule {
+	if len(rules) == 0 {
+		return nil
+	}
+	out := make([]FilterRule, len(rules))
+	for i, rule := range rules {
+		rule.Channels = slices.Clone(rule.Channels)
+		out[i] = rule
+	}
+	return out
+}
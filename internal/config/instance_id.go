@@ -0,0 +1,57 @@
+package config
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// InstanceIDPath is where the uploader keeps the ID it reports to servers.
+// It lives beside the settings but apart from them, so saving settings from
+// a UI never drops it.
+func InstanceIDPath() (string, error) {
+	root, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, "sentinel2", "uploader-instance-id"), nil
+}
+
+// LoadOrCreateInstanceID returns this install's uploader instance ID,
+// creating and saving a random one on first use.
+func LoadOrCreateInstanceID() (string, error) {
+	path, err := InstanceIDPath()
+	if err != nil {
+		return "", err
+	}
+	return loadOrCreateInstanceID(path)
+}
+
+func loadOrCreateInstanceID(path string) (string, error) {
+	if data, err := os.ReadFile(path); err == nil {
+		if id := strings.TrimSpace(string(data)); id != "" {
+			return id, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+	id := newInstanceID()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(id+"\n"), 0o600); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// newInstanceID returns a random version 4 UUID.
+func newInstanceID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
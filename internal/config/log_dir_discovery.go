@@ -0,0 +1,215 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogDirAuto as --log-dir, or as the saved log_dir, picks the most recently
+// active discovered log directory.
+const LogDirAuto = "auto"
+
+// eveSteamAppID is EVE Online's Steam app ID, which names its Proton prefix.
+const eveSteamAppID = "8500"
+
+// LogDirCandidate is an EVE chat log directory found on this machine.
+type LogDirCandidate struct {
+	Path string
+	// Source names the install layout, such as "Steam Proton" or "Wine".
+	Source string
+	// LastActivity is the newest chat log modification time, zero when the
+	// directory holds no logs.
+	LastActivity time.Time
+}
+
+// Label describes the candidate for pickers.
+func (c LogDirCandidate) Label(now time.Time) string {
+	activity := "no chat logs"
+	if !c.LastActivity.IsZero() {
+		activity = "last log " + formatActivityAge(now.Sub(c.LastActivity)) + " ago"
+	}
+	return c.Source + " (" + activity + "): " + c.Path
+}
+
+func formatActivityAge(age time.Duration) string {
+	switch {
+	case age < time.Minute:
+		return "<1m"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm", int(age/time.Minute))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(age/time.Hour))
+	default:
+		return fmt.Sprintf("%dd", int(age/(24*time.Hour)))
+	}
+}
+
+// logDirProbe is a filepath.Glob pattern for chat log directories of one
+// install layout.
+type logDirProbe struct {
+	source  string
+	pattern string
+}
+
+// DefaultLogDir returns the most recently active discovered log directory,
+// or the native client's directory when none exists.
+func DefaultLogDir() string {
+	if candidates := DiscoverLogDirs(); len(candidates) > 0 {
+		return candidates[0].Path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return logDirProbes(home)[0].pattern
+}
+
+// DiscoverLogDirs probes the known EVE install layouts for this platform and
+// returns the existing chat log directories, most recently active first.
+// The probes glob many prefixes, so they run once per process and later
+// calls return the same candidates. UIs should call it off their event loop.
+func DiscoverLogDirs() []LogDirCandidate {
+	return slices.Clone(discoveredLogDirs())
+}
+
+var discoveredLogDirs = sync.OnceValue(func() []LogDirCandidate {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return discoverLogDirs(logDirProbes(home))
+})
+
+// AutoLogDir resolves --log-dir auto.
+func AutoLogDir() (string, error) {
+	candidates := DiscoverLogDirs()
+	if len(candidates) == 0 {
+		return "", errors.New("no EVE chat log directory found; pass --log-dir explicitly")
+	}
+	return candidates[0].Path, nil
+}
+
+func discoverLogDirs(probes []logDirProbe) []LogDirCandidate {
+	var out []LogDirCandidate
+	seen := map[string]struct{}{}
+	for _, probe := range probes {
+		matches, _ := filepath.Glob(probe.pattern)
+		for _, path := range matches {
+			if info, err := os.Stat(path); err != nil || !info.IsDir() {
+				continue
+			}
+			path = filepath.Clean(path)
+			// Wine often links Documents to the host's, and ~/.steam/steam
+			// is a link to the Steam root.
+			key := path
+			if resolved, err := filepath.EvalSymlinks(path); err == nil {
+				key = resolved
+			}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			out = append(out, LogDirCandidate{
+				Path:         path,
+				Source:       probe.source,
+				LastActivity: lastChatLogActivity(path),
+			})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].LastActivity.After(out[j].LastActivity)
+	})
+	return out
+}
+
+func lastChatLogActivity(dir string) time.Time {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return time.Time{}
+	}
+	var latest time.Time
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".txt") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
+
+// unixLogDirProbes covers the native client plus Steam Proton, Flatpak
+// Steam, Wine and Lutris prefixes.
+func unixLogDirProbes(home string, getenv func(string) string) []logDirProbe {
+	probes := []logDirProbe{{"Native", filepath.Join(home, "Documents", "EVE", "logs", "Chatlogs")}}
+
+	steamRoots := []struct{ source, root string }{
+		{"Steam Proton", filepath.Join(home, ".steam", "steam")},
+		{"Steam Proton", filepath.Join(home, ".steam", "root")},
+		{"Steam Proton", filepath.Join(home, ".local", "share", "Steam")},
+		{"Flatpak Steam Proton", filepath.Join(home, ".var", "app", "com.valvesoftware.Steam", ".local", "share", "Steam")},
+		{"Flatpak Steam Proton", filepath.Join(home, ".var", "app", "com.valvesoftware.Steam", "data", "Steam")},
+	}
+	for _, steam := range steamRoots {
+		libraries := append([]string{steam.root}, steamLibraryFolders(steam.root)...)
+		for _, library := range libraries {
+			prefix := filepath.Join(library, "steamapps", "compatdata", eveSteamAppID, "pfx")
+			probes = append(probes, prefixLogDirProbes(steam.source, prefix, "steamuser")...)
+		}
+	}
+
+	winePrefixes := []string{filepath.Join(home, ".wine")}
+	if prefix := strings.TrimSpace(getenv("WINEPREFIX")); prefix != "" {
+		winePrefixes = append([]string{prefix}, winePrefixes...)
+	}
+	for _, prefix := range winePrefixes {
+		probes = append(probes, prefixLogDirProbes("Wine", prefix, "*")...)
+	}
+	probes = append(probes, prefixLogDirProbes("Lutris", filepath.Join(home, "Games", "*"), "*")...)
+	return probes
+}
+
+// prefixLogDirProbes returns the chat log directories inside a Windows
+// prefix for user, which may be a glob.
+func prefixLogDirProbes(source string, prefix string, user string) []logDirProbe {
+	var probes []logDirProbe
+	for _, documents := range []string{"Documents", "My Documents"} {
+		probes = append(probes, logDirProbe{
+			source:  source,
+			pattern: filepath.Join(prefix, "drive_c", "users", user, documents, "EVE", "logs", "Chatlogs"),
+		})
+	}
+	return probes
+}
+
+var steamLibraryPathPattern = regexp.MustCompile(`"path"\s+"((?:[^"\\]|\\.)*)"`)
+
+// steamLibraryFolders lists the extra Steam libraries configured under root;
+// EVE's Proton prefix lives in the library it is installed to.
+func steamLibraryFolders(root string) []string {
+	data, err := os.ReadFile(filepath.Join(root, "steamapps", "libraryfolders.vdf"))
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, match := range steamLibraryPathPattern.FindAllStringSubmatch(string(data), -1) {
+		path := strings.ReplaceAll(match[1], `\\`, `\`)
+		if filepath.Clean(path) != filepath.Clean(root) {
+			out = append(out, path)
+		}
+	}
+	return out
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiscoverLogDirs_RanksInstallsByActivity(t *testing.T) {
+	home := t.TempDir()
+	now := time.Now()
+	makeDir := func(path string, logAge time.Duration) string {
+		t.Helper()
+		if err := os.MkdirAll(path, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if logAge > 0 {
+			log := filepath.Join(path, "Intel_20260214_100000_90000001.txt")
+			if err := os.WriteFile(log, []byte("x"), 0o644); err != nil {
+				t.Fatalf("write log: %v", err)
+			}
+			at := now.Add(-logAge)
+			if err := os.Chtimes(log, at, at); err != nil {
+				t.Fatalf("chtimes: %v", err)
+			}
+		}
+		return path
+	}
+	chatlogs := func(parts ...string) string {
+		return filepath.Join(append(parts, "EVE", "logs", "Chatlogs")...)
+	}
+
+	native := makeDir(chatlogs(home, "Documents"), 0)
+	wine := makeDir(chatlogs(home, ".wine", "drive_c", "users", "pilot", "Documents"), 3*time.Hour)
+	library := filepath.Join(home, "games-ssd", "SteamLibrary")
+	proton := makeDir(chatlogs(library, "steamapps", "compatdata", "8500", "pfx", "drive_c", "users", "steamuser", "Documents"), 5*time.Minute)
+	flatpak := makeDir(chatlogs(home, ".var", "app", "com.valvesoftware.Steam", "data", "Steam", "steamapps", "compatdata", "8500", "pfx", "drive_c", "users", "steamuser", "My Documents"), time.Hour)
+	lutris := makeDir(chatlogs(home, "Games", "eve-online", "drive_c", "users", "pilot", "Documents"), 30*time.Minute)
+
+	steamRoot := filepath.Join(home, ".local", "share", "Steam")
+	makeDir(filepath.Join(steamRoot, "steamapps"), 0)
+	vdf := `"libraryfolders"
+{
+	"0"
+	{
+		"path"		"` + steamRoot + `"
+	}
+	"1"
+	{
+		"path"		"` + library + `"
+	}
+}`
+	if err := os.WriteFile(filepath.Join(steamRoot, "steamapps", "libraryfolders.vdf"), []byte(vdf), 0o644); err != nil {
+		t.Fatalf("write libraryfolders.vdf: %v", err)
+	}
+	// ~/.steam/steam links to the Steam root and must not list it twice.
+	if err := os.MkdirAll(filepath.Join(home, ".steam"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.Symlink(steamRoot, filepath.Join(home, ".steam", "steam")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	got := discoverLogDirs(unixLogDirProbes(home, func(string) string { return "" }))
+	want := []struct{ path, source string }{
+		{proton, "Steam Proton"},
+		{lutris, "Lutris"},
+		{flatpak, "Flatpak Steam Proton"},
+		{wine, "Wine"},
+		{native, "Native"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("discoverLogDirs() = %+v, want %d candidates", got, len(want))
+	}
+	for i, candidate := range got {
+		if candidate.Path != want[i].path || candidate.Source != want[i].source {
+			t.Fatalf("candidate %d = %s (%s), want %s (%s)", i, candidate.Path, candidate.Source, want[i].path, want[i].source)
+		}
+	}
+	if !got[len(got)-1].LastActivity.IsZero() {
+		t.Fatalf("native LastActivity = %s, want zero", got[len(got)-1].LastActivity)
+	}
+}
+
+func TestLogDirCandidate_Label(t *testing.T) {
+	now := time.Date(2026, 2, 14, 12, 0, 0, 0, time.UTC)
+	candidate := LogDirCandidate{Path: "/logs", Source: "Wine", LastActivity: now.Add(-90 * time.Minute)}
+	if got, want := candidate.Label(now), "Wine (last log 1h ago): /logs"; got != want {
+		t.Fatalf("Label() = %q, want %q", got, want)
+	}
+	candidate.LastActivity = time.Time{}
+	if got, want := candidate.Label(now), "Wine (no chat logs): /logs"; got != want {
+		t.Fatalf("Label() = %q, want %q", got, want)
+	}
+}
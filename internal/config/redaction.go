@@ -0,0 +1,15 @@
+package config
+
+import "slices"
+
+// RedactionRule replaces message spans matching Pattern with Placeholder
+// before upload. An empty placeholder uses the uploader default.
+type RedactionRule struct {
+	Name        string `json:"name,omitempty"`
+	Pattern     string `json:"pattern"`
+	Placeholder string `json:"placeholder,omitempty"`
+}
+
+func RedactionRulesEqual(a []RedactionRule, b []RedactionRule) bool {
+	return slices.Equal(a, b)
+}
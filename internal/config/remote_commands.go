@@ -0,0 +1,95 @@
+package config
+
+import (
+	"slices"
+	"strings"
+)
+
+// Remote commands the server can push over the realtime channel.
+const (
+	RemoteCommandResubscribe = "resubscribe"
+	RemoteCommandDiagnostics = "diagnostics"
+	RemoteCommandLogLevel    = "log_level"
+	RemoteCommandMessage     = "message"
+
+	// RemoteCommandsNone in the allowlist turns remote control off.
+	RemoteCommandsNone = "none"
+)
+
+// RemoteCommandNames lists every remote command the uploader understands.
+func RemoteCommandNames() []string {
+	return []string{
+		RemoteCommandResubscribe,
+		RemoteCommandDiagnostics,
+		RemoteCommandLogLevel,
+		RemoteCommandMessage,
+	}
+}
+
+// DefaultRemoteCommands lists the commands an empty allowlist permits: the
+// ones that cannot change what the uploader logs or sends back.
+func DefaultRemoteCommands() []string {
+	return []string{
+		RemoteCommandResubscribe,
+		RemoteCommandMessage,
+	}
+}
+
+// NormalizeRemoteCommands lowercases and trims allowlist entries, dropping
+// blanks and duplicates while keeping the original order.
+func NormalizeRemoteCommands(names []string) []string {
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || slices.Contains(out, name) {
+			continue
+		}
+		out = append(out, name)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// AllowedRemoteCommands returns the commands allow actually permits. An
+// empty allowlist permits DefaultRemoteCommands; one containing
+// RemoteCommandsNone permits none.
+func AllowedRemoteCommands(allow []string) []string {
+	allow = NormalizeRemoteCommands(allow)
+	if len(allow) == 0 {
+		return DefaultRemoteCommands()
+	}
+	if slices.Contains(allow, RemoteCommandsNone) {
+		return nil
+	}
+	return allow
+}
+
+// RemoteCommandsFromSelection turns the commands ticked in a settings form
+// into an allowlist, writing RemoteCommandsNone when nothing is ticked so the
+// saved list does not fall back to the defaults.
+func RemoteCommandsFromSelection(selected []string) []string {
+	selected = NormalizeRemoteCommands(selected)
+	if len(selected) == 0 {
+		return []string{RemoteCommandsNone}
+	}
+	return selected
+}
+
+// ParseRemoteCommandList reads a comma-separated allowlist from a text field.
+func ParseRemoteCommandList(value string) []string {
+	return NormalizeRemoteCommands(strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n'
+	}))
+}
+
+// FormatRemoteCommandList renders an allowlist for a text field.
+func FormatRemoteCommandList(names []string) string {
+	return strings.Join(NormalizeRemoteCommands(names), ", ")
+}
+
+// RemoteCommandAllowed reports whether allow permits the named command.
+func RemoteCommandAllowed(allow []string, name string) bool {
+	return slices.Contains(AllowedRemoteCommands(allow), strings.ToLower(strings.TrimSpace(name)))
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SettingKeys lists the UploaderSettings keys, by their JSON names, in file
+// order.
+func SettingKeys() []string {
+	typ := reflect.TypeFor[UploaderSettings]()
+	keys := make([]string, 0, typ.NumField())
+	for i := range typ.NumField() {
+		keys = append(keys, settingKey(typ.Field(i)))
+	}
+	return keys
+}
+
+// maskedSecret replaces secret settings in listings of every setting.
+const maskedSecret = "********"
+
+// MaskSecrets returns s with the token masked, for printing every setting
+// without giving it away.
+func (s UploaderSettings) MaskSecrets() UploaderSettings {
+	if s.Token != "" {
+		s.Token = maskedSecret
+	}
+	return s
+}
+
+// Get returns the JSON value of the setting named key.
+func (s UploaderSettings) Get(key string) (json.RawMessage, error) {
+	field, err := settingField(reflect.ValueOf(&s).Elem(), key)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(field.Interface())
+}
+
+// Set parses value into the setting named key. Values are JSON; strings may
+// also be given bare, and lists as comma-separated text.
+func (s *UploaderSettings) Set(key string, value string) error {
+	field, err := settingField(reflect.ValueOf(s).Elem(), key)
+	if err != nil {
+		return err
+	}
+	parsed := reflect.New(field.Type())
+	if jsonErr := json.Unmarshal([]byte(value), parsed.Interface()); jsonErr != nil {
+		switch {
+		case field.Kind() == reflect.String:
+			parsed.Elem().SetString(value)
+		case field.Type() == reflect.TypeFor[[]string]():
+			parsed.Elem().Set(reflect.ValueOf(splitSettingList(value)))
+		default:
+			return fmt.Errorf("invalid value for %s: %w", key, jsonErr)
+		}
+	}
+	if err := validateSetting(key, parsed.Elem().Interface()); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	field.Set(parsed.Elem())
+	return nil
+}
+
+func settingField(settings reflect.Value, key string) (reflect.Value, error) {
+	key = strings.TrimSpace(key)
+	typ := settings.Type()
+	for i := range typ.NumField() {
+		if settingKey(typ.Field(i)) == key {
+			return settings.Field(i), nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("unknown setting %q (known: %s)", key, strings.Join(SettingKeys(), ", "))
+}
+
+func settingKey(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return name
+}
+
+func splitSettingList(value string) []string {
+	var out []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// validateSetting rejects values the UIs would never save.
+func validateSetting(key string, value any) error {
+	switch key {
+	case "resume_max_age", "outbox_max_age":
+		if text := strings.TrimSpace(value.(string)); text != "" {
+			if _, err := time.ParseDuration(text); err != nil {
+				return err
+			}
+		}
+	case "realtime_transport":
+		choices := []string{"", RealtimeTransportAuto, RealtimeTransportSSE, RealtimeTransportWebSocket}
+		if !slices.Contains(choices, strings.TrimSpace(value.(string))) {
+			return fmt.Errorf("must be one of %s", strings.Join(choices[1:], ", "))
+		}
+	case "remote_commands":
+		for _, name := range NormalizeRemoteCommands(value.([]string)) {
+			if name != RemoteCommandsNone && !slices.Contains(RemoteCommandNames(), name) {
+				return fmt.Errorf("unknown remote command %q", name)
+			}
+		}
+	case "status_addr":
+		return ValidateStatusAddr(value.(string))
+	case "base_url":
+		if text := strings.TrimSpace(value.(string)); text != "" {
+			if _, err := buildAPIBaseURL(text); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -4,18 +4,57 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 )
 
 type UploaderSettings struct {
-	BaseURL                string `json:"base_url"`
-	Token                  string `json:"token"`
-	LogDir                 string `json:"log_dir"`
-	AutoConnect            bool   `json:"auto_connect"`
-	Debug                  bool   `json:"debug"`
-	MinimizeToTray         bool   `json:"minimize_to_tray"`
-	StartMinimized         bool   `json:"start_minimized"`
-	LastDismissedUpdateTag string `json:"last_dismissed_update_tag,omitempty"`
+	BaseURL                string          `json:"base_url"`
+	Token                  string          `json:"token"`
+	LogDir                 string          `json:"log_dir"`
+	ExtraLogDirs           []string        `json:"extra_log_dirs,omitempty"`
+	CharacterFilter        CharacterFilter `json:"character_filter,omitzero"`
+	FilterRules            []FilterRule    `json:"filter_rules,omitempty"`
+	FilterDryRun           bool            `json:"filter_dry_run,omitempty"`
+	RedactURLs             bool            `json:"redact_urls,omitempty"`
+	RedactEmails           bool            `json:"redact_emails,omitempty"`
+	RedactionRules         []RedactionRule `json:"redaction_rules,omitempty"`
+	ResumeMaxAge           string          `json:"resume_max_age,omitempty"`
+	OutboxMaxAge           string          `json:"outbox_max_age,omitempty"`
+	RealtimeTransport      string          `json:"realtime_transport,omitempty"`
+	RemoteCommands         []string        `json:"remote_commands,omitempty"`
+	StatusAddr             string          `json:"status_addr,omitempty"`
+	AutoConnect            bool            `json:"auto_connect"`
+	Debug                  bool            `json:"debug"`
+	MinimizeToTray         bool            `json:"minimize_to_tray"`
+	StartMinimized         bool            `json:"start_minimized"`
+	LastDismissedUpdateTag string          `json:"last_dismissed_update_tag,omitempty"`
+}
+
+// Equal reports whether s and other hold the same settings. UploaderSettings
+// contains slices, so it cannot be compared with ==.
+func (s UploaderSettings) Equal(other UploaderSettings) bool {
+	return s.BaseURL == other.BaseURL &&
+		s.Token == other.Token &&
+		s.LogDir == other.LogDir &&
+		slices.Equal(s.ExtraLogDirs, other.ExtraLogDirs) &&
+		s.CharacterFilter.Equal(other.CharacterFilter) &&
+		FilterRulesEqual(s.FilterRules, other.FilterRules) &&
+		s.FilterDryRun == other.FilterDryRun &&
+		s.RedactURLs == other.RedactURLs &&
+		s.RedactEmails == other.RedactEmails &&
+		RedactionRulesEqual(s.RedactionRules, other.RedactionRules) &&
+		s.ResumeMaxAge == other.ResumeMaxAge &&
+		s.OutboxMaxAge == other.OutboxMaxAge &&
+		s.RealtimeTransport == other.RealtimeTransport &&
+		slices.Equal(s.RemoteCommands, other.RemoteCommands) &&
+		s.StatusAddr == other.StatusAddr &&
+		s.AutoConnect == other.AutoConnect &&
+		s.Debug == other.Debug &&
+		s.MinimizeToTray == other.MinimizeToTray &&
+		s.StartMinimized == other.StartMinimized &&
+		s.LastDismissedUpdateTag == other.LastDismissedUpdateTag
 }
 
 func SettingsPath() (string, error) {
@@ -57,6 +96,53 @@ func SaveSettings(settings UploaderSettings) error {
 	return os.WriteFile(path, payload, 0o600)
 }
 
+// SettingsWriter saves the settings edited in a UI. A session started with
+// --mock-server points the base URL and token at the mock, so its writer
+// keeps the ones already in the settings file instead.
+type SettingsWriter struct {
+	mockServer bool
+}
+
+func NewSettingsWriter(opts Options) SettingsWriter {
+	return SettingsWriter{mockServer: strings.TrimSpace(opts.MockServer) != ""}
+}
+
+func (w SettingsWriter) Save(settings UploaderSettings) error {
+	if w.mockServer {
+		settings = settings.withSavedCredentials()
+	}
+	return SaveSettings(settings)
+}
+
+// withSavedCredentials returns s with BaseURL and Token taken from the
+// settings file, or cleared when there is none.
+func (s UploaderSettings) withSavedCredentials() UploaderSettings {
+	saved, err := LoadSettings()
+	if err != nil {
+		saved = UploaderSettings{}
+	}
+	s.BaseURL = saved.BaseURL
+	s.Token = saved.Token
+	return s
+}
+
+// WithFileOnlySettings returns o with the settings that have no control in
+// the settings forms taken from s: filter and redaction rules, the resume and
+// outbox ages, the realtime transport and the status address. The UIs build
+// their options through it so those values reach the uploader unedited.
+func (o Options) WithFileOnlySettings(s UploaderSettings) Options {
+	o.FilterRules = cloneFilterRules(s.FilterRules)
+	o.FilterDryRun = s.FilterDryRun
+	o.RedactURLs = s.RedactURLs
+	o.RedactEmails = s.RedactEmails
+	o.RedactionRules = slices.Clone(s.RedactionRules)
+	o.ResumeMaxAge = ParseDurationSetting(s.ResumeMaxAge)
+	o.OutboxMaxAge = ParseDurationSetting(s.OutboxMaxAge)
+	o.RealtimeTransport = s.RealtimeTransport
+	o.StatusAddr = s.StatusAddr
+	return o
+}
+
 func MergeOptionsWithSettings(cli Options, saved UploaderSettings) Options {
 	if strings.TrimSpace(cli.BaseURL) == "" {
 		cli.BaseURL = saved.BaseURL
@@ -67,6 +153,41 @@ func MergeOptionsWithSettings(cli Options, saved UploaderSettings) Options {
 	if strings.TrimSpace(cli.LogDir) == "" {
 		cli.LogDir = saved.LogDir
 	}
+	if strings.EqualFold(strings.TrimSpace(cli.LogDir), LogDirAuto) {
+		// Left empty when nothing is found, so callers fall back to their
+		// default directory.
+		cli.LogDir, _ = AutoLogDir()
+	}
+	if len(cli.ExtraLogDirs) == 0 {
+		cli.ExtraLogDirs = slices.Clone(saved.ExtraLogDirs)
+	}
+	cli.CharacterFilter = saved.CharacterFilter
+	cli.FilterRules = cloneFilterRules(saved.FilterRules)
+	if !cli.FilterDryRun {
+		cli.FilterDryRun = saved.FilterDryRun
+	}
+	if !cli.RedactURLs {
+		cli.RedactURLs = saved.RedactURLs
+	}
+	if !cli.RedactEmails {
+		cli.RedactEmails = saved.RedactEmails
+	}
+	cli.RedactionRules = slices.Clone(saved.RedactionRules)
+	if strings.TrimSpace(cli.RealtimeTransport) == "" {
+		cli.RealtimeTransport = saved.RealtimeTransport
+	}
+	if len(cli.RemoteCommands) == 0 {
+		cli.RemoteCommands = slices.Clone(saved.RemoteCommands)
+	}
+	if cli.ResumeMaxAge == 0 {
+		cli.ResumeMaxAge = ParseDurationSetting(saved.ResumeMaxAge)
+	}
+	if cli.OutboxMaxAge == 0 {
+		cli.OutboxMaxAge = ParseDurationSetting(saved.OutboxMaxAge)
+	}
+	if strings.TrimSpace(cli.StatusAddr) == "" {
+		cli.StatusAddr = saved.StatusAddr
+	}
 	if !cli.AutoConnect {
 		cli.AutoConnect = saved.AutoConnect
 	}
@@ -77,12 +198,44 @@ func MergeOptionsWithSettings(cli Options, saved UploaderSettings) Options {
 	return cli
 }
 
+// ParseDurationSetting reads a duration stored in settings, treating an empty
+// or malformed value as unset.
+func ParseDurationSetting(value string) time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+func FormatDurationSetting(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
+
 func SettingsFromOptions(opts Options) UploaderSettings {
 	return UploaderSettings{
-		BaseURL:     strings.TrimSpace(opts.BaseURL),
-		Token:       strings.TrimSpace(opts.Token),
-		LogDir:      strings.TrimSpace(opts.LogDir),
-		AutoConnect: opts.AutoConnect,
-		Debug:       opts.Debug,
+		BaseURL:      strings.TrimSpace(opts.BaseURL),
+		Token:        strings.TrimSpace(opts.Token),
+		LogDir:       strings.TrimSpace(opts.LogDir),
+		ExtraLogDirs: NormalizeLogDirs(opts.ExtraLogDirs),
+		CharacterFilter: CharacterFilter{
+			Mode:       NormalizeCharacterFilterMode(opts.CharacterFilter.Mode),
+			Characters: slices.Clone(opts.CharacterFilter.Characters),
+		},
+		FilterRules:       cloneFilterRules(opts.FilterRules),
+		FilterDryRun:      opts.FilterDryRun,
+		RedactURLs:        opts.RedactURLs,
+		RedactEmails:      opts.RedactEmails,
+		RedactionRules:    slices.Clone(opts.RedactionRules),
+		ResumeMaxAge:      FormatDurationSetting(opts.ResumeMaxAge),
+		OutboxMaxAge:      FormatDurationSetting(opts.OutboxMaxAge),
+		RealtimeTransport: strings.TrimSpace(opts.RealtimeTransport),
+		RemoteCommands:    NormalizeRemoteCommands(opts.RemoteCommands),
+		StatusAddr:        strings.TrimSpace(opts.StatusAddr),
+		AutoConnect:       opts.AutoConnect,
+		Debug:             opts.Debug,
 	}
 }
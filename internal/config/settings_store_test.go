@@ -1,6 +1,7 @@
 package config
 
 import (
+	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
@@ -48,6 +49,31 @@ func TestSettingsSaveLoadAndPath(t *testing.T) {
 	}
 }
 
+func TestSettingsWriter_KeepsSavedCredentialsForMockServer(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("AppData", t.TempDir())
+
+	mock := UploaderSettings{BaseURL: "http://127.0.0.1:4321", Token: "mock-uploader-token", LogDir: "/tmp/chatlogs"}
+	writer := NewSettingsWriter(Options{MockServer: "127.0.0.1:4321"})
+	if err := writer.Save(mock); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if got, err := LoadSettings(); err != nil || got.BaseURL != "" || got.Token != "" || got.LogDir != mock.LogDir {
+		t.Fatalf("saved without a file = %#v, %v", got, err)
+	}
+
+	if err := NewSettingsWriter(Options{}).Save(UploaderSettings{BaseURL: "https://intel.example.com", Token: "real-token"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := writer.Save(mock); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := LoadSettings()
+	if err != nil || got.BaseURL != "https://intel.example.com" || got.Token != "real-token" || got.LogDir != mock.LogDir {
+		t.Fatalf("saved = %#v, %v; want saved URL and token with the edited log dir", got, err)
+	}
+}
+
 func TestMergeOptionsWithSettings_PrefersCLIAndClearsLogFile(t *testing.T) {
 	merged := MergeOptionsWithSettings(
 		Options{
@@ -83,3 +109,173 @@ func TestMergeOptionsWithSettings_PrefersCLIAndClearsLogFile(t *testing.T) {
 		t.Fatalf("LogFile should be cleared, got %q", merged.LogFile)
 	}
 }
+
+func TestMergeOptionsWithSettings_ResolvesSavedAutoLogDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	native := logDirProbes(home)[0].pattern
+	if err := os.MkdirAll(native, 0o755); err != nil {
+		t.Fatalf("mkdir %s: %v", native, err)
+	}
+
+	merged := MergeOptionsWithSettings(Options{}, UploaderSettings{LogDir: "auto"})
+	if merged.LogDir != native {
+		t.Fatalf("LogDir = %q, want discovered %q", merged.LogDir, native)
+	}
+}
+
+func TestUploaderSettingsEqual_ComparesExtraLogDirs(t *testing.T) {
+	a := UploaderSettings{LogDir: "/tmp/a", ExtraLogDirs: []string{"/tmp/b"}}
+	b := a
+	b.ExtraLogDirs = []string{"/tmp/b"}
+	if !a.Equal(b) {
+		t.Fatalf("Equal() = false for identical settings")
+	}
+	b.ExtraLogDirs = append(b.ExtraLogDirs, "/tmp/c")
+	if a.Equal(b) {
+		t.Fatalf("Equal() = true with different extra log dirs")
+	}
+	if !(UploaderSettings{}).Equal(UploaderSettings{ExtraLogDirs: []string{}}) {
+		t.Fatalf("Equal() should treat nil and empty extra log dirs as equal")
+	}
+}
+
+func TestSplitLogDirs_TrimsAndDedupes(t *testing.T) {
+	sep := string(filepath.ListSeparator)
+	got := SplitLogDirs(" /tmp/a " + sep + sep + "/tmp/b/" + sep + "/tmp/a")
+	want := []string{filepath.Clean("/tmp/a"), filepath.Clean("/tmp/b")}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("SplitLogDirs() = %#v, want %#v", got, want)
+	}
+	if joined := JoinLogDirs(got); joined != want[0]+sep+want[1] {
+		t.Fatalf("JoinLogDirs() = %q", joined)
+	}
+}
+
+func TestParseCharacterList_RoundTrip(t *testing.T) {
+	got := ParseCharacterList(" 9001 = Scout One ,9002\n9001=Dup, ,=NoID")
+	want := []CharacterEntry{{ID: "9001", Label: "Scout One"}, {ID: "9002"}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("ParseCharacterList() = %#v, want %#v", got, want)
+	}
+	if formatted := FormatCharacterList(got); formatted != "9001=Scout One, 9002" {
+		t.Fatalf("FormatCharacterList() = %q", formatted)
+	}
+}
+
+func TestMergeOptionsWithSettings_CarriesCharacterFilter(t *testing.T) {
+	saved := UploaderSettings{CharacterFilter: CharacterFilter{
+		Mode:       CharacterFilterDeny,
+		Characters: []CharacterEntry{{ID: "9001", Label: "Hostile alt"}},
+	}}
+	merged := MergeOptionsWithSettings(Options{}, saved)
+	if !merged.CharacterFilter.Equal(saved.CharacterFilter) {
+		t.Fatalf("CharacterFilter = %#v, want %#v", merged.CharacterFilter, saved.CharacterFilter)
+	}
+	if roundTrip := SettingsFromOptions(merged); !roundTrip.CharacterFilter.Equal(saved.CharacterFilter) {
+		t.Fatalf("SettingsFromOptions().CharacterFilter = %#v", roundTrip.CharacterFilter)
+	}
+}
+
+func TestUploaderSettingsEqual_ComparesFilterRules(t *testing.T) {
+	a := UploaderSettings{FilterRules: []FilterRule{{Action: FilterActionExclude, Message: "o7", Channels: []string{"intel"}}}}
+	b := UploaderSettings{FilterRules: []FilterRule{{Action: FilterActionExclude, Message: "o7", Channels: []string{"intel"}}}}
+	if !a.Equal(b) {
+		t.Fatalf("Equal() = false for identical filter rules")
+	}
+	b.FilterRules[0].Channels = []string{"scouts"}
+	if a.Equal(b) {
+		t.Fatalf("Equal() = true with different rule channels")
+	}
+}
+
+func TestMergeOptionsWithSettings_CarriesRedaction(t *testing.T) {
+	saved := UploaderSettings{
+		RedactEmails:   true,
+		RedactionRules: []RedactionRule{{Name: "discord", Pattern: `discord\.gg/\w+`}},
+	}
+	merged := MergeOptionsWithSettings(Options{RedactURLs: true}, saved)
+	if !merged.RedactURLs || !merged.RedactEmails {
+		t.Fatalf("RedactURLs/RedactEmails = %v/%v, want true/true", merged.RedactURLs, merged.RedactEmails)
+	}
+	roundTrip := SettingsFromOptions(merged)
+	if !RedactionRulesEqual(roundTrip.RedactionRules, saved.RedactionRules) {
+		t.Fatalf("SettingsFromOptions().RedactionRules = %#v", roundTrip.RedactionRules)
+	}
+}
+
+func TestOptionsWithFileOnlySettings_RoundTrips(t *testing.T) {
+	saved := UploaderSettings{
+		BaseURL:           "https://intel.example.com",
+		FilterRules:       []FilterRule{{Action: FilterActionExclude, Message: "o7"}},
+		FilterDryRun:      true,
+		RedactURLs:        true,
+		RedactionRules:    []RedactionRule{{Name: "discord", Pattern: `discord\.gg/\w+`}},
+		ResumeMaxAge:      "2h0m0s",
+		OutboxMaxAge:      "24h0m0s",
+		RealtimeTransport: RealtimeTransportSSE,
+		StatusAddr:        "127.0.0.1:9464",
+	}
+	opts := Options{BaseURL: saved.BaseURL}.WithFileOnlySettings(saved)
+	if roundTrip := SettingsFromOptions(opts); !roundTrip.Equal(saved) {
+		t.Fatalf("SettingsFromOptions() = %#v, want %#v", roundTrip, saved)
+	}
+}
+
+func TestUploaderSettingsGetSet(t *testing.T) {
+	var settings UploaderSettings
+	for key, value := range map[string]string{
+		"base_url":           "https://intel.example.com",
+		"auto_connect":       "true",
+		"extra_log_dirs":     "/a, /b",
+		"remote_commands":    `["message"]`,
+		"resume_max_age":     "5m",
+		"realtime_transport": "websocket",
+	} {
+		if err := settings.Set(key, value); err != nil {
+			t.Fatalf("Set(%s, %q) error = %v", key, value, err)
+		}
+	}
+	if settings.BaseURL != "https://intel.example.com" || !settings.AutoConnect || settings.ResumeMaxAge != "5m" {
+		t.Fatalf("settings = %+v", settings)
+	}
+	if len(settings.ExtraLogDirs) != 2 || settings.ExtraLogDirs[1] != "/b" || len(settings.RemoteCommands) != 1 {
+		t.Fatalf("list settings = %q, %q", settings.ExtraLogDirs, settings.RemoteCommands)
+	}
+	got, err := settings.Get("extra_log_dirs")
+	if err != nil || string(got) != `["/a","/b"]` {
+		t.Fatalf("Get(extra_log_dirs) = %s, %v", got, err)
+	}
+
+	for key, value := range map[string]string{
+		"no_such_key":        "x",
+		"auto_connect":       "maybe",
+		"resume_max_age":     "soon",
+		"realtime_transport": "carrier-pigeon",
+		"remote_commands":    "reboot",
+		"base_url":           "ftp://intel.example.com",
+	} {
+		if err := settings.Set(key, value); err == nil {
+			t.Fatalf("Set(%s, %q) error = nil, want error", key, value)
+		}
+	}
+	if settings.RealtimeTransport != "websocket" {
+		t.Fatalf("failed Set changed realtime_transport to %q", settings.RealtimeTransport)
+	}
+}
+
+func TestLoadOrCreateInstanceID_IsStable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sentinel2", "uploader-instance-id")
+	first, err := loadOrCreateInstanceID(path)
+	if err != nil {
+		t.Fatalf("loadOrCreateInstanceID() error = %v", err)
+	}
+	if len(first) != 36 || first[14] != '4' {
+		t.Fatalf("instance ID = %q, want a v4 UUID", first)
+	}
+	second, err := loadOrCreateInstanceID(path)
+	if err != nil || second != first {
+		t.Fatalf("second load = %q, %v; want %q", second, err, first)
+	}
+}
@@ -0,0 +1,134 @@
+// Package chatlogtest writes synthetic EVE chat logs for tests: UTF-16LE
+// with a BOM, the client's session header, and the
+// Channel_YYYYMMDD_HHMMSS_CharacterID.txt naming the monitor matches on.
+package chatlogtest
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"unicode/utf16"
+)
+
+const (
+	lineTimeLayout   = "2006.01.02 15:04:05"
+	fileTimeLayout   = "20060102_150405"
+	headerRule       = "        ---------------------------------------------------------------\r\n"
+	downtimeHourUTC  = 11
+	sessionStartedAt = "          Session started: "
+)
+
+// Session identifies one character's log of one channel.
+type Session struct {
+	Channel     string
+	ChannelID   string
+	Listener    string
+	CharacterID string
+}
+
+// Log is a chat log being appended to, as the EVE client would.
+type Log struct {
+	dir     string
+	session Session
+
+	mu   sync.Mutex
+	path string
+}
+
+// Open starts a new log file for session in dir, as the client does when a
+// character joins a channel at started.
+func Open(dir string, session Session, started time.Time) (*Log, error) {
+	l := &Log{dir: dir, session: session}
+	if err := l.start(started); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Path is the file currently written to.
+func (l *Log) Path() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.path
+}
+
+// Say appends a chat line from author at the given time.
+func (l *Log) Say(at time.Time, author string, message string) error {
+	return l.Append(Line(at, author, message) + "\r\n")
+}
+
+// Append writes raw text to the end of the current file.
+func (l *Log) Append(text string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return err
+	}
+	_, writeErr := file.Write(encode(text))
+	closeErr := file.Close()
+	if writeErr != nil {
+		return writeErr
+	}
+	return closeErr
+}
+
+// Rotate switches to a new file started at, as the client does after
+// downtime. The old file is left in place and no longer written.
+func (l *Log) Rotate(at time.Time) error {
+	return l.start(at)
+}
+
+func (l *Log) start(at time.Time) error {
+	at = at.UTC()
+	name := fmt.Sprintf("%s_%s_%s.txt", l.session.Channel, at.Format(fileTimeLayout), l.session.CharacterID)
+	path := filepath.Join(l.dir, name)
+	raw := append([]byte{0xFF, 0xFE}, encode(Header(l.session, at))...)
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	l.path = path
+	l.mu.Unlock()
+	return nil
+}
+
+// Line formats a chat line without the trailing CRLF.
+func Line(at time.Time, author string, message string) string {
+	return fmt.Sprintf("[ %s ] %s > %s", at.UTC().Format(lineTimeLayout), author, message)
+}
+
+// Header is the session block the client writes at the top of each log.
+func Header(session Session, started time.Time) string {
+	return "\r\n\r\n" +
+		headerRule +
+		"\r\n" +
+		"          Channel ID:      " + session.ChannelID + "\r\n" +
+		"          Channel Name:    " + session.Channel + "\r\n" +
+		"          Listener:        " + session.Listener + "\r\n" +
+		sessionStartedAt + started.UTC().Format(lineTimeLayout) + "\r\n" +
+		headerRule + "\r\n"
+}
+
+// NextDowntime returns the first daily downtime (11:00 UTC) after t, when
+// the client rotates its logs.
+func NextDowntime(t time.Time) time.Time {
+	t = t.UTC()
+	downtime := time.Date(t.Year(), t.Month(), t.Day(), downtimeHourUTC, 0, 0, 0, time.UTC)
+	if !downtime.After(t) {
+		downtime = downtime.AddDate(0, 0, 1)
+	}
+	return downtime
+}
+
+func encode(text string) []byte {
+	units := utf16.Encode([]rune(text))
+	raw := make([]byte, 0, 2*len(units))
+	for _, unit := range units {
+		raw = binary.LittleEndian.AppendUint16(raw, unit)
+	}
+	return raw
+}
@@ -1,6 +1,8 @@
 package evelogs
 
 import (
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
@@ -10,21 +12,92 @@ import (
 	"sentinel2-uploader/internal/client"
 )
 
+// maxLogDirDepth bounds how far below a log root the scanner and watcher
+// descend, so pointing a root at a large tree stays cheap.
+const maxLogDirDepth = 3
+
 func ResolveChannelForPath(path string, channels []client.ChannelConfig) (client.ChannelConfig, bool) {
+	selection, ok := ResolveLogSelection(path, channels)
+	return selection.Channel, ok
+}
+
+// ResolveLogSelection maps the log at path to a configured channel, filling
+// in header metadata when the header was needed and has been written.
+func ResolveLogSelection(path string, channels []client.ChannelConfig) (LogSelection, bool) {
+	return resolveLogSelection(path, channels, nil)
+}
+
+func resolveLogSelection(path string, channels []client.ChannelConfig, headers *HeaderCache) (LogSelection, bool) {
 	meta, ok := parseLogFileMeta(path)
 	if !ok {
-		return client.ChannelConfig{}, false
+		return LogSelection{}, false
+	}
+	return newChannelMatcher(channels, headers).resolve(path, meta)
+}
+
+type channelMatcher struct {
+	byName  map[string]client.ChannelConfig
+	byID    map[string]client.ChannelConfig
+	headers *HeaderCache
+}
+
+func newChannelMatcher(channels []client.ChannelConfig, headers *HeaderCache) channelMatcher {
+	byID := map[string]client.ChannelConfig{}
+	for _, channel := range channels {
+		id := strings.TrimSpace(channel.EVEChannelID)
+		if id == "" {
+			continue
+		}
+		if _, exists := byID[id]; !exists {
+			byID[id] = channel
+		}
+	}
+	return channelMatcher{byName: channelIndex(channels), byID: byID, headers: headers}
+}
+
+// resolve prefers the header over the filename: a matching EVE channel ID
+// wins, then the header channel name, then the name in the filename. A
+// channel the server pinned to an EVE channel ID never matches a log whose
+// header carries a different ID, even if the names agree.
+//
+// The header is only read when some channel is pinned or the filename names
+// no configured channel, so scanning a folder of thousands of logs mostly
+// stays a directory listing.
+func (cm channelMatcher) resolve(path string, meta logFileMeta) (LogSelection, bool) {
+	selection := LogSelection{Path: path}
+	if len(cm.byID) == 0 {
+		if channel, ok := cm.byName[normalizeChannelKey(meta.ChannelName)]; ok && strings.TrimSpace(channel.ID) != "" {
+			selection.Channel = channel
+			return selection, true
+		}
+	}
+	name := meta.ChannelName
+	header, hasHeader, _ := cm.headers.Read(path)
+	if hasHeader {
+		selection.Listener = header.Listener
+		selection.EVEChannelID = header.ChannelID
+		if channel, ok := cm.byID[header.ChannelID]; ok && strings.TrimSpace(channel.ID) != "" {
+			selection.Channel = channel
+			return selection, true
+		}
+		name = header.ChannelName
+	}
+	channel, found := cm.byName[normalizeChannelKey(name)]
+	if !found && hasHeader {
+		channel, found = cm.byName[normalizeChannelKey(meta.ChannelName)]
 	}
-	index := channelIndex(channels)
-	channel, found := index[normalizeChannelKey(meta.ChannelName)]
 	if !found || strings.TrimSpace(channel.ID) == "" {
-		return client.ChannelConfig{}, false
+		return LogSelection{}, false
 	}
-	return channel, true
+	if pinned := strings.TrimSpace(channel.EVEChannelID); pinned != "" && hasHeader && pinned != header.ChannelID {
+		return LogSelection{}, false
+	}
+	selection.Channel = channel
+	return selection, true
 }
 
 func FindLatestLog(dir string, channels []client.ChannelConfig) (LogSelection, bool) {
-	matches, err := findLogMatches(dir, channels)
+	matches, err := findLogMatches(dir, channels, nil)
 	if err != nil {
 		return LogSelection{}, false
 	}
@@ -41,10 +114,41 @@ func FindLatestLog(dir string, channels []client.ChannelConfig) (LogSelection, b
 }
 
 func FindLogs(dir string, channels []client.ChannelConfig) ([]LogSelection, error) {
-	matches, err := findLogMatches(dir, channels)
-	if err != nil {
-		return nil, err
+	return FindLogsInDirs([]string{dir}, channels, nil)
+}
+
+// FindLogsInDirs scans every root (and its subdirectories) and returns the
+// newest log per (channel, character) across all of them. A root that cannot
+// be read is skipped; an error is returned only when none could be read.
+// Headers are read through headers, which is pruned to the logs this scan
+// found.
+func FindLogsInDirs(dirs []string, channels []client.ChannelConfig, headers *HeaderCache) ([]LogSelection, error) {
+	matches := make([]logMatch, 0)
+	seenPaths := map[string]struct{}{}
+	var firstErr error
+	scanned := 0
+	for _, dir := range dirs {
+		rootMatches, err := findLogMatches(dir, channels, headers)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		scanned++
+		for _, m := range rootMatches {
+			// Nested or repeated roots can surface the same file twice.
+			if _, ok := seenPaths[m.Selection.Path]; ok {
+				continue
+			}
+			seenPaths[m.Selection.Path] = struct{}{}
+			matches = append(matches, m)
+		}
 	}
+	if scanned == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	headers.Prune()
 
 	// Keep only the newest file per (channel, character) tuple.
 	latestByKey := make(map[string]logMatch)
@@ -60,12 +164,7 @@ func FindLogs(dir string, channels []client.ChannelConfig) ([]LogSelection, erro
 	for _, m := range latestByKey {
 		latest = append(latest, m)
 	}
-	sort.Slice(latest, func(i, j int) bool {
-		if latest[i].Meta.Timestamp.Equal(latest[j].Meta.Timestamp) {
-			return latest[i].Selection.Path < latest[j].Selection.Path
-		}
-		return latest[i].Meta.Timestamp.Before(latest[j].Meta.Timestamp)
-	})
+	sortLogMatches(latest)
 
 	out := make([]LogSelection, 0, len(latest))
 	for _, m := range latest {
@@ -74,44 +173,76 @@ func FindLogs(dir string, channels []client.ChannelConfig) ([]LogSelection, erro
 	return out, nil
 }
 
-func findLogMatches(dir string, channels []client.ChannelConfig) ([]logMatch, error) {
-	entries, err := os.ReadDir(dir)
+// findLogMatches walks dir up to maxLogDirDepth levels deep so installs that
+// keep per-profile Chatlogs folders under a common root are picked up.
+func findLogMatches(dir string, channels []client.ChannelConfig, headers *HeaderCache) ([]logMatch, error) {
+	root := filepath.Clean(dir)
+	info, err := os.Stat(root)
 	if err != nil {
 		return nil, err
 	}
-	index := channelIndex(channels)
+	if !info.IsDir() {
+		return nil, fmt.Errorf("log path is not a directory: %s", root)
+	}
+	matcher := newChannelMatcher(channels, headers)
 
 	matches := make([]logMatch, 0)
-
-	for _, entry := range entries {
+	walkErr := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable subdirectories are skipped; the root was checked above.
+			if entry != nil && entry.IsDir() && path != root {
+				return fs.SkipDir
+			}
+			return nil
+		}
 		if entry.IsDir() {
-			continue
+			if path != root && logDirDepth(root, path) > maxLogDirDepth {
+				return fs.SkipDir
+			}
+			return nil
 		}
 		meta, ok := parseLogFileMeta(entry.Name())
 		if !ok {
-			continue
+			return nil
 		}
-		channel, found := index[normalizeChannelKey(meta.ChannelName)]
-		if !found || strings.TrimSpace(channel.ID) == "" {
-			continue
+		selection, found := matcher.resolve(path, meta)
+		if !found {
+			return nil
 		}
 		info, infoErr := entry.Info()
 		if infoErr != nil {
-			continue
+			return nil
 		}
 		matches = append(matches, logMatch{
-			Selection: LogSelection{Path: filepath.Join(dir, entry.Name()), Channel: channel},
+			Selection: selection,
 			Meta:      meta,
 			ModTime:   info.ModTime(),
 		})
+		return nil
+	})
+	if walkErr != nil {
+		return nil, walkErr
 	}
+	sortLogMatches(matches)
+	return matches, nil
+}
+
+func sortLogMatches(matches []logMatch) {
 	sort.Slice(matches, func(i, j int) bool {
 		if matches[i].Meta.Timestamp.Equal(matches[j].Meta.Timestamp) {
 			return matches[i].Selection.Path < matches[j].Selection.Path
 		}
 		return matches[i].Meta.Timestamp.Before(matches[j].Meta.Timestamp)
 	})
-	return matches, nil
+}
+
+// logDirDepth returns how many directory levels path sits below root.
+func logDirDepth(root string, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
 }
 
 func channelIndex(channels []client.ChannelConfig) map[string]client.ChannelConfig {
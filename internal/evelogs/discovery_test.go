@@ -82,3 +82,38 @@ func TestFindLogs_OnlyLatestPerChannelAndCharacter(t *testing.T) {
 		t.Fatalf("FindLogs() missing latest file for Intel/charA")
 	}
 }
+
+func TestFindLogsInDirs_MergesRootsAndSubdirectories(t *testing.T) {
+	rootA := t.TempDir()
+	rootB := t.TempDir()
+	write := func(path string) string {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+		return path
+	}
+
+	write(filepath.Join(rootA, "Intel_20260214_120000_charA.txt"))
+	newerA := write(filepath.Join(rootB, "prefix", "Chatlogs", "Intel_20260214_120500_charA.txt"))
+	charB := write(filepath.Join(rootA, "Chatlogs", "Intel_20260214_120100_charB.txt"))
+	write(filepath.Join(rootA, "a", "b", "c", "d", "Intel_20260214_130000_charC.txt"))
+
+	channels := []client.ChannelConfig{{ID: "intel", Name: "Intel"}}
+	logs, err := FindLogsInDirs([]string{rootA, rootB, rootA, filepath.Join(rootA, "missing")}, channels, nil)
+	if err != nil {
+		t.Fatalf("FindLogsInDirs() error = %v", err)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("FindLogsInDirs() = %#v, want 2 selections", logs)
+	}
+	if logs[0].Path != charB || logs[1].Path != newerA {
+		t.Fatalf("FindLogsInDirs() paths = [%s %s], want [%s %s]", logs[0].Path, logs[1].Path, charB, newerA)
+	}
+
+	if _, err := FindLogsInDirs([]string{filepath.Join(rootA, "missing")}, channels, nil); err == nil {
+		t.Fatalf("FindLogsInDirs() expected error when no root is readable")
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package evelogs
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileIdentity returns the inode of the file, or zero when unavailable.
+func fileIdentity(_ string, info os.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Ino)
+	}
+	return 0
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package evelogs
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileIdentity returns the NTFS file index, or zero when unavailable.
+func fileIdentity(path string, _ os.FileInfo) uint64 {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+	var data syscall.ByHandleFileInformation
+	if err := syscall.GetFileInformationByHandle(syscall.Handle(file.Fd()), &data); err != nil {
+		return 0
+	}
+	return uint64(data.FileIndexHigh)<<32 | uint64(data.FileIndexLow)
+}
@@ -0,0 +1,192 @@
+package evelogs
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"sentinel2-uploader/internal/client"
+)
+
+type FilterAction int
+
+const (
+	FilterExclude FilterAction = iota
+	FilterInclude
+)
+
+func (a FilterAction) String() string {
+	if a == FilterInclude {
+		return "include"
+	}
+	return "exclude"
+}
+
+// FilterRule matches report lines by author and message regular expressions.
+// An empty pattern matches anything; Channels limits the rule to channels by
+// ID or name and applies it everywhere when empty.
+type FilterRule struct {
+	Name     string
+	Action   FilterAction
+	Author   string
+	Message  string
+	Channels []string
+}
+
+// FilterRuleStats counts matches per rule: lines dropped for exclude rules,
+// lines let through for include rules.
+type FilterRuleStats struct {
+	Name   string
+	Action FilterAction
+	Hits   uint64
+}
+
+type FilterStats struct {
+	Rules []FilterRuleStats
+	// NoInclude counts lines dropped because include rules applied to their
+	// channel and none matched.
+	NoInclude uint64
+	DryRun    bool
+}
+
+// FilterSet evaluates report lines against exclude rules first, then include
+// rules. In dry-run mode it only counts what it would drop.
+type FilterSet struct {
+	rules     []*compiledFilterRule
+	dryRun    bool
+	noInclude atomic.Uint64
+}
+
+type compiledFilterRule struct {
+	FilterRule
+	author   *regexp.Regexp
+	message  *regexp.Regexp
+	channels map[string]struct{}
+	hits     atomic.Uint64
+}
+
+// NewFilterSet compiles rules. Invalid rules are left out and reported in the
+// returned error; the set is still usable with the remaining rules.
+func NewFilterSet(rules []FilterRule, dryRun bool) (*FilterSet, error) {
+	set := &FilterSet{dryRun: dryRun}
+	var errs []error
+	for i, rule := range rules {
+		compiled, err := compileFilterRule(rule)
+		if err != nil {
+			name := rule.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i+1)
+			}
+			errs = append(errs, fmt.Errorf("filter rule %s: %w", name, err))
+			continue
+		}
+		if compiled.Name == "" {
+			compiled.Name = fmt.Sprintf("%s #%d", compiled.Action, i+1)
+		}
+		set.rules = append(set.rules, compiled)
+	}
+	return set, errors.Join(errs...)
+}
+
+func compileFilterRule(rule FilterRule) (*compiledFilterRule, error) {
+	rule.Author = strings.TrimSpace(rule.Author)
+	rule.Message = strings.TrimSpace(rule.Message)
+	if rule.Author == "" && rule.Message == "" {
+		return nil, errors.New("needs an author or message pattern")
+	}
+	compiled := &compiledFilterRule{FilterRule: rule, channels: map[string]struct{}{}}
+	var err error
+	if rule.Author != "" {
+		if compiled.author, err = regexp.Compile(rule.Author); err != nil {
+			return nil, fmt.Errorf("author pattern: %w", err)
+		}
+	}
+	if rule.Message != "" {
+		if compiled.message, err = regexp.Compile(rule.Message); err != nil {
+			return nil, fmt.Errorf("message pattern: %w", err)
+		}
+	}
+	for _, channel := range rule.Channels {
+		if key := normalizeChannelKey(channel); key != "" {
+			compiled.channels[key] = struct{}{}
+		}
+	}
+	return compiled, nil
+}
+
+func (r *compiledFilterRule) appliesTo(channel client.ChannelConfig) bool {
+	if len(r.channels) == 0 {
+		return true
+	}
+	_, byID := r.channels[normalizeChannelKey(channel.ID)]
+	_, byName := r.channels[normalizeChannelKey(channel.Name)]
+	return byID || byName
+}
+
+func (r *compiledFilterRule) matches(report ParsedReport) bool {
+	if r.author != nil && !r.author.MatchString(report.Author) {
+		return false
+	}
+	return r.message == nil || r.message.MatchString(report.Message)
+}
+
+// Check reports whether the set drops report and, if so, which rule did.
+// Counters are updated even in dry-run mode.
+func (f *FilterSet) Check(channel client.ChannelConfig, report ParsedReport) (string, bool) {
+	if f == nil {
+		return "", false
+	}
+	hasInclude := false
+	for _, rule := range f.rules {
+		if !rule.appliesTo(channel) {
+			continue
+		}
+		if rule.Action == FilterInclude {
+			hasInclude = true
+			continue
+		}
+		if rule.matches(report) {
+			rule.hits.Add(1)
+			return rule.Name, true
+		}
+	}
+	if !hasInclude {
+		return "", false
+	}
+	for _, rule := range f.rules {
+		if rule.Action == FilterInclude && rule.appliesTo(channel) && rule.matches(report) {
+			rule.hits.Add(1)
+			return "", false
+		}
+	}
+	f.noInclude.Add(1)
+	return "no include rule matched", true
+}
+
+func (f *FilterSet) DryRun() bool {
+	return f != nil && f.dryRun
+}
+
+func (f *FilterSet) Len() int {
+	if f == nil {
+		return 0
+	}
+	return len(f.rules)
+}
+
+func (f *FilterSet) Stats() FilterStats {
+	if f == nil {
+		return FilterStats{}
+	}
+	stats := FilterStats{
+		Rules:     make([]FilterRuleStats, 0, len(f.rules)),
+		NoInclude: f.noInclude.Load(),
+		DryRun:    f.dryRun,
+	}
+	for _, rule := range f.rules {
+		stats.Rules = append(stats.Rules, FilterRuleStats{Name: rule.Name, Action: rule.Action, Hits: rule.hits.Load()})
+	}
+	return stats
+}
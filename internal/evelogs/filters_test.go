@@ -0,0 +1,88 @@
+package evelogs
+
+import (
+	"testing"
+
+	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/logging"
+)
+
+func TestFilterSet_ExcludeIncludeAndChannelScope(t *testing.T) {
+	set, err := NewFilterSet([]FilterRule{
+		{Name: "o7", Action: FilterExclude, Message: `(?i)^o7$`},
+		{Name: "bots", Action: FilterExclude, Author: `Bot$`, Channels: []string{"intel"}},
+		{Name: "systems", Action: FilterInclude, Message: `[A-Z0-9]{1,4}-[A-Z0-9]{1,4}`, Channels: []string{"Scouts"}},
+		{Name: "broken", Action: FilterExclude, Message: `(`},
+	}, false)
+	if err == nil {
+		t.Fatalf("NewFilterSet() expected error for invalid pattern")
+	}
+	if set.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3 valid rules", set.Len())
+	}
+
+	intel := client.ChannelConfig{ID: "intel", Name: "Intel"}
+	scouts := client.ChannelConfig{ID: "scouts-id", Name: "Scouts"}
+	cases := []struct {
+		channel client.ChannelConfig
+		report  ParsedReport
+		rule    string
+		drop    bool
+	}{
+		{intel, ParsedReport{Author: "Pilot", Message: "O7"}, "o7", true},
+		{intel, ParsedReport{Author: "Intel Bot", Message: "hello"}, "bots", true},
+		{scouts, ParsedReport{Author: "Intel Bot", Message: "1DQ1-A red"}, "", false},
+		{scouts, ParsedReport{Author: "Pilot", Message: "anyone up for roams?"}, "no include rule matched", true},
+		{intel, ParsedReport{Author: "Pilot", Message: "anyone up for roams?"}, "", false},
+	}
+	for _, tc := range cases {
+		rule, drop := set.Check(tc.channel, tc.report)
+		if rule != tc.rule || drop != tc.drop {
+			t.Fatalf("Check(%s, %q) = (%q, %v), want (%q, %v)", tc.channel.Name, tc.report.Message, rule, drop, tc.rule, tc.drop)
+		}
+	}
+
+	stats := set.Stats()
+	if stats.NoInclude != 1 {
+		t.Fatalf("NoInclude = %d, want 1", stats.NoInclude)
+	}
+	for _, rule := range stats.Rules {
+		if rule.Hits != 1 {
+			t.Fatalf("rule %s hits = %d, want 1", rule.Name, rule.Hits)
+		}
+	}
+}
+
+func TestProcessLines_FilterDryRunCountsButEmits(t *testing.T) {
+	for _, dryRun := range []bool{false, true} {
+		set, err := NewFilterSet([]FilterRule{{Name: "o7", Action: FilterExclude, Message: `^o7$`}}, dryRun)
+		if err != nil {
+			t.Fatalf("NewFilterSet() error = %v", err)
+		}
+		logger := logging.New(false)
+		logger.SetTerminalOutputEnabled(false)
+		reports := 0
+		monitor := NewMonitor(MonitorOptions{Filters: set}, logger, MonitorCallbacks{
+			OnReport: func(ReportEvent) error {
+				reports++
+				return nil
+			},
+		})
+
+		monitor.processLines([]string{
+			"[ 2026.02.14 12:00:00 ] Pilot > o7",
+			"[ 2026.02.14 12:00:01 ] Pilot > 1DQ1-A red",
+		}, LogSelection{Path: "Intel_20260214_120000_1.txt", Channel: client.ChannelConfig{ID: "intel", Name: "Intel"}}, nil, 0)
+
+		want := 1
+		if dryRun {
+			want = 2
+		}
+		if reports != want {
+			t.Fatalf("dryRun=%v: reports = %d, want %d", dryRun, reports, want)
+		}
+		if hits := monitor.Filters().Stats().Rules[0].Hits; hits != 1 {
+			t.Fatalf("dryRun=%v: hits = %d, want 1", dryRun, hits)
+		}
+	}
+}
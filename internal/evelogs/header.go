@@ -0,0 +1,152 @@
+package evelogs
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxHeaderBytes covers the preamble EVE writes at the top of every chat log
+// (roughly 300 characters, doubled for UTF-16) with room to spare.
+const maxHeaderBytes = 4096
+
+// LogHeader is the metadata block EVE writes at the top of every chat log.
+type LogHeader struct {
+	ChannelID      string
+	ChannelName    string
+	Listener       string
+	SessionStarted time.Time
+}
+
+// HeaderCache keeps parsed log headers, which never change once written, so
+// repeated scans read each file only once. Prune drops every header not read
+// since the previous Prune, which keeps the cache to the logs still on disk
+// as chat logs rotate. A nil *HeaderCache caches nothing.
+type HeaderCache struct {
+	mu     sync.Mutex
+	byPath map[string]LogHeader
+	used   map[string]struct{}
+}
+
+// NewHeaderCache returns an empty cache.
+func NewHeaderCache() *HeaderCache {
+	return &HeaderCache{byPath: map[string]LogHeader{}, used: map[string]struct{}{}}
+}
+
+// Read is ReadLogHeader through the cache.
+func (c *HeaderCache) Read(path string) (LogHeader, bool, error) {
+	if c == nil {
+		return ReadLogHeader(path)
+	}
+	c.mu.Lock()
+	c.used[path] = struct{}{}
+	cached, ok := c.byPath[path]
+	c.mu.Unlock()
+	if ok {
+		return cached, true, nil
+	}
+	header, ok, err := ReadLogHeader(path)
+	if ok {
+		c.mu.Lock()
+		c.byPath[path] = header
+		c.mu.Unlock()
+	}
+	return header, ok, err
+}
+
+// Prune drops the headers not read since the previous Prune.
+func (c *HeaderCache) Prune() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for path := range c.byPath {
+		if _, ok := c.used[path]; !ok {
+			delete(c.byPath, path)
+		}
+	}
+	clear(c.used)
+}
+
+// Len returns the number of cached headers.
+func (c *HeaderCache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.byPath)
+}
+
+// ReadLogHeader parses the header block of the chat log at path. It reports
+// false when the file has no recognizable header yet, e.g. because the client
+// has only just created it.
+func ReadLogHeader(path string) (LogHeader, bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return LogHeader{}, false, err
+	}
+	defer file.Close()
+
+	raw := make([]byte, maxHeaderBytes)
+	n, err := io.ReadFull(file, raw)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return LogHeader{}, false, err
+	}
+	raw = raw[:n]
+	tailer := &Tailer{Encoding: detectLogEncoding(raw)}
+	header, ok := parseLogHeader(decodeLogChunk(raw, tailer))
+	if !ok {
+		return LogHeader{}, false, nil
+	}
+	return header, true, nil
+}
+
+func parseLogHeader(text string) (LogHeader, bool) {
+	header := LogHeader{}
+	complete := false
+	for line := range strings.Lines(text) {
+		line = strings.TrimSpace(NormalizeLogLine(strings.TrimRight(line, "\n")))
+		if IsReportLine(line) {
+			break
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "channel id":
+			header.ChannelID = value
+		case "channel name":
+			header.ChannelName = value
+		case "listener":
+			header.Listener = value
+		case "session started":
+			if ts, err := time.ParseInLocation("2006.01.02 15:04:05", value, time.UTC); err == nil {
+				header.SessionStarted = ts
+			}
+			complete = true
+		}
+		if complete {
+			break
+		}
+	}
+	// A header cut short mid-write is retried on the next read rather than
+	// cached half-filled.
+	return header, complete && header.ChannelName != ""
+}
+
+func detectLogEncoding(prefix []byte) string {
+	switch {
+	case len(prefix) >= 2 && prefix[0] == 0xFF && prefix[1] == 0xFE:
+		return "utf16le"
+	case len(prefix) >= 2 && prefix[0] == 0xFE && prefix[1] == 0xFF:
+		return "utf16be"
+	default:
+		return "utf8"
+	}
+}
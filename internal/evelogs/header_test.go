@@ -0,0 +1,137 @@
+package evelogs
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"sentinel2-uploader/internal/client"
+)
+
+func writeUTF16LELog(t *testing.T, path string, text string) {
+	t.Helper()
+	units := utf16.Encode([]rune(text))
+	raw := make([]byte, 2, 2+2*len(units))
+	raw[0], raw[1] = 0xFF, 0xFE
+	for _, unit := range units {
+		raw = binary.LittleEndian.AppendUint16(raw, unit)
+	}
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func chatLogHeader(channelID string, channelName string, listener string) string {
+	return "\r\n\r\n" +
+		"        ---------------------------------------------------------------\r\n" +
+		"\r\n" +
+		"          Channel ID:      " + channelID + "\r\n" +
+		"          Channel Name:    " + channelName + "\r\n" +
+		"          Listener:        " + listener + "\r\n" +
+		"          Session started: 2026.02.14 12:00:00\r\n" +
+		"        ---------------------------------------------------------------\r\n\r\n"
+}
+
+func TestReadLogHeader_UTF16(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "My_Intel_20260214_120000_9001.txt")
+	writeUTF16LELog(t, path, chatLogHeader("-12345", "My_Intel", "Pilot One")+"[ 2026.02.14 12:00:05 ] Pilot > clear\r\n")
+
+	header, ok, err := ReadLogHeader(path)
+	if err != nil || !ok {
+		t.Fatalf("ReadLogHeader() ok=%v err=%v", ok, err)
+	}
+	want := LogHeader{
+		ChannelID:      "-12345",
+		ChannelName:    "My_Intel",
+		Listener:       "Pilot One",
+		SessionStarted: time.Date(2026, 2, 14, 12, 0, 0, 0, time.UTC),
+	}
+	if header != want {
+		t.Fatalf("ReadLogHeader() = %#v, want %#v", header, want)
+	}
+}
+
+func TestReadLogHeader_IncompleteHeaderIsNotCached(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Intel_20260214_120000_9001.txt")
+	writeUTF16LELog(t, path, "\r\n\r\n          Channel ID:      -1\r\n")
+	headers := NewHeaderCache()
+	if _, ok, err := headers.Read(path); ok || err != nil {
+		t.Fatalf("Read(partial) ok=%v err=%v, want false, nil", ok, err)
+	}
+
+	writeUTF16LELog(t, path, chatLogHeader("-1", "Intel", "Pilot"))
+	header, ok, err := headers.Read(path)
+	if err != nil || !ok || header.Listener != "Pilot" {
+		t.Fatalf("Read(complete) = %#v ok=%v err=%v", header, ok, err)
+	}
+}
+
+func TestHeaderCache_PrunesLogsMissingFromLatestScan(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "Intel_20260213_120000_9001.txt")
+	newPath := filepath.Join(dir, "Intel_20260214_120000_9001.txt")
+	writeUTF16LELog(t, oldPath, chatLogHeader("-1", "Intel", "Pilot"))
+	writeUTF16LELog(t, newPath, chatLogHeader("-1", "Intel", "Pilot"))
+
+	headers := NewHeaderCache()
+	if _, err := FindLogsInDirs([]string{dir}, []client.ChannelConfig{{ID: "c1", Name: "Intel", EVEChannelID: "-1"}}, headers); err != nil {
+		t.Fatalf("FindLogsInDirs() error = %v", err)
+	}
+	if got := headers.Len(); got != 2 {
+		t.Fatalf("Len() after first scan = %d, want 2", got)
+	}
+
+	if err := os.Remove(oldPath); err != nil {
+		t.Fatalf("remove old log: %v", err)
+	}
+	if _, err := FindLogsInDirs([]string{dir}, []client.ChannelConfig{{ID: "c1", Name: "Intel", EVEChannelID: "-1"}}, headers); err != nil {
+		t.Fatalf("FindLogsInDirs() error = %v", err)
+	}
+	if got := headers.Len(); got != 1 {
+		t.Fatalf("Len() after old log removed = %d, want 1", got)
+	}
+}
+
+func TestFindLogsInDirs_ReadsHeadersOnlyWhenTheFilenameIsNotEnough(t *testing.T) {
+	dir := t.TempDir()
+	named := filepath.Join(dir, "Intel_20260214_120000_9001.txt")
+	writeUTF16LELog(t, named, chatLogHeader("-1", "Intel", "Pilot One"))
+	renamed := filepath.Join(dir, "Intel Old_20260214_120000_9002.txt")
+	writeUTF16LELog(t, renamed, chatLogHeader("-1", "Intel", "Pilot Two"))
+
+	headers := NewHeaderCache()
+	logs, err := FindLogsInDirs([]string{dir}, []client.ChannelConfig{{ID: "c1", Name: "Intel"}}, headers)
+	if err != nil {
+		t.Fatalf("FindLogsInDirs() error = %v", err)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("FindLogsInDirs() = %#v, want both logs", logs)
+	}
+	if got := headers.Len(); got != 1 {
+		t.Fatalf("Len() = %d, want only the header of the log its filename does not name", got)
+	}
+}
+
+func TestFindLogs_MatchesByEVEChannelIDFromHeader(t *testing.T) {
+	dir := t.TempDir()
+	renamed := filepath.Join(dir, "Old Name_20260214_120000_9001.txt")
+	writeUTF16LELog(t, renamed, chatLogHeader("-555", "New Name", "Pilot One"))
+	impostor := filepath.Join(dir, "Intel_20260214_120000_9002.txt")
+	writeUTF16LELog(t, impostor, chatLogHeader("-777", "Intel", "Pilot Two"))
+
+	channels := []client.ChannelConfig{{ID: "intel", Name: "Intel", EVEChannelID: "-555"}}
+	logs, err := FindLogs(dir, channels)
+	if err != nil {
+		t.Fatalf("FindLogs() error = %v", err)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("FindLogs() = %#v, want only the log with the pinned channel ID", logs)
+	}
+	got := logs[0]
+	if got.Path != renamed || got.Channel.ID != "intel" || got.Listener != "Pilot One" || got.EVEChannelID != "-555" {
+		t.Fatalf("FindLogs()[0] = %#v", got)
+	}
+}
@@ -0,0 +1,176 @@
+package evelogs
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/logging"
+)
+
+// localChannel matches Local chat logs. They are read only to follow each
+// character's solar system and are never uploaded.
+var localChannel = client.ChannelConfig{ID: "local", Name: "Local"}
+
+const localChangePrefix = "Channel changed to Local :"
+
+// maxLocationHistory bounds the system changes kept per character; older
+// reports than the oldest kept change get no location.
+const maxLocationHistory = 64
+
+// ParseLocationChange returns the system from the "Channel changed to
+// Local : <System>" line EVE writes to the Local log on every jump.
+func ParseLocationChange(report ParsedReport) (string, bool) {
+	if !strings.EqualFold(report.Author, "EVE System") {
+		return "", false
+	}
+	rest, ok := strings.CutPrefix(report.Message, localChangePrefix)
+	if !ok {
+		return "", false
+	}
+	system := strings.TrimSpace(rest)
+	return system, system != ""
+}
+
+type locationChange struct {
+	at     time.Time
+	system string
+}
+
+// LocationTracker follows each character's solar system, keyed by character
+// ID, from their Local logs. It is safe for concurrent use.
+type LocationTracker struct {
+	mu      sync.Mutex
+	changes map[string][]locationChange
+}
+
+func NewLocationTracker() *LocationTracker {
+	return &LocationTracker{changes: map[string][]locationChange{}}
+}
+
+// Observe records a jump of characterID into system at time at. Changes may
+// arrive out of order; the history stays sorted.
+func (t *LocationTracker) Observe(characterID string, system string, at time.Time) {
+	characterID = strings.TrimSpace(characterID)
+	if characterID == "" || system == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	history := t.changes[characterID]
+	i := len(history)
+	for i > 0 && history[i-1].at.After(at) {
+		i--
+	}
+	if i > 0 && history[i-1].at.Equal(at) && history[i-1].system == system {
+		return
+	}
+	history = append(history, locationChange{})
+	copy(history[i+1:], history[i:])
+	history[i] = locationChange{at: at, system: system}
+	if len(history) > maxLocationHistory {
+		history = history[len(history)-maxLocationHistory:]
+	}
+	t.changes[characterID] = history
+}
+
+// SystemAt returns the system characterID was in at time at, or "" when no
+// earlier change is known.
+func (t *LocationTracker) SystemAt(characterID string, at time.Time) string {
+	if t == nil {
+		return ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	history := t.changes[strings.TrimSpace(characterID)]
+	for i := len(history) - 1; i >= 0; i-- {
+		if !history[i].at.After(at) {
+			return history[i].system
+		}
+	}
+	return ""
+}
+
+// Current returns the last known system of characterID.
+func (t *LocationTracker) Current(characterID string) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	history := t.changes[strings.TrimSpace(characterID)]
+	if len(history) == 0 {
+		return "", false
+	}
+	return history[len(history)-1].system, true
+}
+
+// syncLocalLogs tails the newest Local log of every allowed character. A
+// newly found log is read from the start, since the last jump may be hours
+// old.
+func (m *Monitor) syncLocalLogs() {
+	roots := m.watchDirs
+	if len(roots) == 0 {
+		roots = m.logRoots()
+	}
+	selections, err := FindLogsInDirs(roots, []client.ChannelConfig{localChannel}, m.headers)
+	if err != nil {
+		m.logger.Debugf("local log sync failed: %v", err)
+		return
+	}
+	for _, selection := range m.filterCharacters(selections) {
+		meta, ok := parseLogFileMeta(selection.Path)
+		if !ok {
+			continue
+		}
+		path := filepath.Clean(selection.Path)
+		if current, ok := m.localLogs[meta.CharacterID]; ok && current.Path == path {
+			continue
+		}
+		m.logger.Debug("following local log", logging.Field("path", path), logging.Field("character_id", meta.CharacterID))
+		m.localLogs[meta.CharacterID] = &Tailer{Path: path}
+		m.readLocalLog(meta.CharacterID)
+	}
+}
+
+// readLocalLog feeds new lines of characterID's Local log to the tracker.
+func (m *Monitor) readLocalLog(characterID string) {
+	tailer, ok := m.localLogs[characterID]
+	if !ok {
+		return
+	}
+	lines, err := tailer.ReadNewLines()
+	if err != nil {
+		m.logger.Debugf("failed to read local log %s: %v", tailer.Path, err)
+		return
+	}
+	for _, raw := range lines {
+		report, ok := ParseReportLine(NormalizeLogLine(raw))
+		if !ok {
+			continue
+		}
+		if system, ok := ParseLocationChange(report); ok {
+			m.locations.Observe(characterID, system, report.Time)
+		}
+	}
+}
+
+// maybeReadLocalEventPath handles a watcher event on a Local log.
+func (m *Monitor) maybeReadLocalEventPath(path string) {
+	meta, ok := parseLogFileMeta(path)
+	if !ok || !strings.EqualFold(meta.ChannelName, localChannel.Name) {
+		return
+	}
+	if current, ok := m.localLogs[meta.CharacterID]; ok && current.Path == filepath.Clean(path) {
+		m.readLocalLog(meta.CharacterID)
+		return
+	}
+	m.syncLocalLogs()
+}
+
+// Locations is the tracker fed from Local logs.
+func (m *Monitor) Locations() *LocationTracker {
+	return m.locations
+}
@@ -0,0 +1,127 @@
+package evelogs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/evelogs/chatlogtest"
+	"sentinel2-uploader/internal/logging"
+)
+
+func TestParseLocationChange(t *testing.T) {
+	tests := []struct {
+		report ParsedReport
+		system string
+		ok     bool
+	}{
+		{ParsedReport{Author: "EVE System", Message: "Channel changed to Local : HED-GP"}, "HED-GP", true},
+		{ParsedReport{Author: "EVE System", Message: "Channel changed to Local : J123450"}, "J123450", true},
+		{ParsedReport{Author: "EVE System", Message: "Channel MOTD: stay safe"}, "", false},
+		{ParsedReport{Author: "Scout", Message: "Channel changed to Local : Jita"}, "", false},
+		{ParsedReport{Author: "EVE System", Message: "Channel changed to Local : "}, "", false},
+	}
+	for _, tt := range tests {
+		system, ok := ParseLocationChange(tt.report)
+		if system != tt.system || ok != tt.ok {
+			t.Fatalf("ParseLocationChange(%+v) = %q, %v; want %q, %v", tt.report, system, ok, tt.system, tt.ok)
+		}
+	}
+}
+
+func TestLocationTracker_SystemAtFollowsJumps(t *testing.T) {
+	base := time.Date(2026, 2, 14, 12, 0, 0, 0, time.UTC)
+	tracker := NewLocationTracker()
+	tracker.Observe("charA", "HED-GP", base)
+	tracker.Observe("charA", "1DQ1-A", base.Add(10*time.Minute))
+	// Out of order, as when an older Local log is read after a newer one.
+	tracker.Observe("charA", "NOL-M9", base.Add(5*time.Minute))
+
+	tests := []struct {
+		at   time.Time
+		want string
+	}{
+		{base.Add(-time.Second), ""},
+		{base, "HED-GP"},
+		{base.Add(6 * time.Minute), "NOL-M9"},
+		{base.Add(time.Hour), "1DQ1-A"},
+	}
+	for _, tt := range tests {
+		if got := tracker.SystemAt("charA", tt.at); got != tt.want {
+			t.Fatalf("SystemAt(%s) = %q, want %q", tt.at, got, tt.want)
+		}
+	}
+	if got := tracker.SystemAt("charB", base.Add(time.Hour)); got != "" {
+		t.Fatalf("SystemAt(unknown character) = %q, want empty", got)
+	}
+	if got, ok := tracker.Current("charA"); !ok || got != "1DQ1-A" {
+		t.Fatalf("Current() = %q, %v; want 1DQ1-A", got, ok)
+	}
+}
+
+func TestMonitor_AttachesReporterSystemFromLocalLog(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC().Truncate(time.Second)
+	started := now.Add(-2 * time.Hour)
+	local, err := chatlogtest.Open(dir, chatlogtest.Session{Channel: "Local", ChannelID: "local", Listener: "Pilot", CharacterID: "charA"}, started)
+	if err != nil {
+		t.Fatalf("open local log: %v", err)
+	}
+	intel, err := chatlogtest.Open(dir, chatlogtest.Session{Channel: "Intel", ChannelID: "-100", Listener: "Pilot", CharacterID: "charA"}, started)
+	if err != nil {
+		t.Fatalf("open intel log: %v", err)
+	}
+	// The jump is older than the lookback and must still be known.
+	if err := local.Say(now.Add(-time.Hour), "EVE System", "Channel changed to Local : HED-GP"); err != nil {
+		t.Fatalf("say: %v", err)
+	}
+	if err := local.Say(now.Add(-30*time.Minute), "Neighbour", "o7"); err != nil {
+		t.Fatalf("say: %v", err)
+	}
+
+	logger := logging.New(false)
+	logger.SetTerminalOutputEnabled(false)
+	var reports []ReportEvent
+	monitor := NewMonitor(
+		MonitorOptions{
+			LogDirs:  []string{dir},
+			Channels: []client.ChannelConfig{{ID: "intel", Name: "Intel"}},
+		},
+		logger,
+		MonitorCallbacks{
+			OnReport: func(event ReportEvent) error {
+				reports = append(reports, event)
+				return nil
+			},
+		},
+	)
+	if err := monitor.Prepare(); err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+
+	if err := intel.Say(now.Add(-2*time.Second), "Scout", "red on gate"); err != nil {
+		t.Fatalf("say: %v", err)
+	}
+	monitor.handleWatcherEvent(fsnotify.Event{Name: intel.Path(), Op: fsnotify.Write})
+
+	// The jump is written to Local before the report, but the report's
+	// write event arrives first.
+	if err := local.Say(now.Add(-time.Second), "EVE System", "Channel changed to Local : 1DQ1-A"); err != nil {
+		t.Fatalf("say: %v", err)
+	}
+	if err := intel.Say(now, "Scout", "1DQ1-A 5x Sabre"); err != nil {
+		t.Fatalf("say: %v", err)
+	}
+	monitor.handleWatcherEvent(fsnotify.Event{Name: intel.Path(), Op: fsnotify.Write})
+
+	if len(reports) != 2 {
+		t.Fatalf("reports = %+v, want two intel reports and nothing from Local", reports)
+	}
+	for i, want := range []string{"HED-GP", "1DQ1-A"} {
+		if reports[i].ReporterSystem != want || reports[i].Channel.ID != "intel" {
+			t.Fatalf("reports[%d] = %+v, want intel report from %s", i, reports[i], want)
+		}
+	}
+}
@@ -34,39 +34,75 @@ func NewMonitor(opts MonitorOptions, logger *logging.Logger, callbacks MonitorCa
 	if opts.InitialLookback <= 0 {
 		opts.InitialLookback = defaultInitialLookback
 	}
-	return &Monitor{
+	m := &Monitor{
 		opts:                      opts,
 		logger:                    logger,
 		callbacks:                 callbacks,
 		channels:                  append([]client.ChannelConfig(nil), opts.Channels...),
 		tracked:                   map[string]*trackedLog{},
+		watched:                   map[string]struct{}{},
 		recent:                    map[string]time.Time{},
 		health:                    map[string]channelHealthState{},
+		skippedCharacters:         map[string]struct{}{},
+		localLogs:                 map[string]*Tailer{},
+		locations:                 NewLocationTracker(),
+		headers:                   NewHeaderCache(),
 		lastPollTrackedCount:      -1,
 		lastDesiredSelectionCount: -1,
 	}
+	m.filters.Store(opts.Filters)
+	return m
+}
+
+// SetFilters replaces the report filter rules. It is safe to call while the
+// monitor is running.
+func (m *Monitor) SetFilters(filters *FilterSet) {
+	m.filters.Store(filters)
+}
+
+func (m *Monitor) Filters() *FilterSet {
+	return m.filters.Load()
+}
+
+// MonitorStats counts chat log lines since the monitor was created.
+type MonitorStats struct {
+	// LinesParsed is every log line checked for a report.
+	LinesParsed uint64
+	// DedupHits is reports skipped because another character's log, or
+	// the same log, already produced the line.
+	DedupHits uint64
+}
+
+func (m *Monitor) Stats() MonitorStats {
+	return MonitorStats{
+		LinesParsed: m.linesParsed.Load(),
+		DedupHits:   m.dedupHits.Load(),
+	}
 }
 
 func (m *Monitor) RunContext(ctx context.Context, configUpdates <-chan []client.ChannelConfig) error {
 	m.logger.Debug("starting log monitor",
 		logging.Field("configured_channels", len(m.channels)),
-		logging.Field("log_dir", m.opts.LogDir),
+		logging.Field("log_dirs", strings.Join(m.opts.LogDirs, ", ")),
 		logging.Field("log_file", m.opts.LogFile),
 	)
 	if err := m.Prepare(); err != nil {
 		return err
 	}
 
+	defer m.flushOffsets()
+
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return fmt.Errorf("failed to initialize fsnotify watcher: %w", err)
 	}
 	defer watcher.Close()
+	m.watcher = watcher
+	defer func() { m.watcher = nil }()
 
-	if err := watcher.Add(m.watchDir); err != nil {
-		return fmt.Errorf("failed to watch log directory %s: %w", m.watchDir, err)
+	if err := m.watchRoots(); err != nil {
+		return err
 	}
-	m.logger.Debugf("watching directory: %s", m.watchDir)
 
 	rescanTicker := time.NewTicker(m.opts.RescanPeriod)
 	defer rescanTicker.Stop()
@@ -105,14 +141,12 @@ func (m *Monitor) Prepare() error {
 }
 
 func (m *Monitor) initialize() error {
-	m.watchDir = m.opts.LogDir
-	if m.watchDir == "" {
-		m.watchDir = filepath.Dir(m.opts.LogFile)
-	}
-	if m.watchDir == "" {
+	m.watchDirs = m.logRoots()
+	if len(m.watchDirs) == 0 {
 		return fmt.Errorf("missing log directory")
 	}
 
+	m.syncLocalLogs()
 	if err := m.syncTrackedLogs(); err != nil {
 		return err
 	}
@@ -121,28 +155,45 @@ func (m *Monitor) initialize() error {
 		m.logger.Warn("no matching log files found for configured channels")
 	}
 
-	cutoff := time.Now().Add(-1 * m.opts.InitialLookback)
 	for _, tracked := range m.tracked {
-		if err := m.sendExistingLines(tracked, cutoff); err != nil {
-			m.logger.Warn("failed to read recent logs", logging.Field("path", tracked.selection.Path), logging.Field("error", err))
-		}
-		if err := tracked.tailer.Prime(); err != nil {
-			m.logger.Warn("failed to prime log tailer", logging.Field("path", tracked.selection.Path), logging.Field("error", err))
+		if m.resumeTrackedLog(tracked) {
+			continue
 		}
+		m.catchUpTrackedLog(tracked)
 	}
+	m.flushOffsets()
 
-	m.logger.Info("watching logs", logging.Field("directory", m.watchDir), logging.Field("files", len(m.tracked)), logging.Field("channels", len(m.channels)))
+	m.logger.Info("watching logs", logging.Field("directories", strings.Join(m.watchDirs, ", ")), logging.Field("files", len(m.tracked)), logging.Field("channels", len(m.channels)))
 	return nil
 }
 
+// catchUpTrackedLog starts tailing a newly tracked log: report lines from
+// the last InitialLookback are sent and tailing continues after them.
+func (m *Monitor) catchUpTrackedLog(tracked *trackedLog) {
+	cutoff := time.Now().Add(-1 * m.opts.InitialLookback)
+	if err := m.sendExistingLines(tracked, cutoff); err != nil {
+		m.logger.Warn("failed to read recent logs", logging.Field("path", tracked.selection.Path), logging.Field("error", err))
+	}
+	if err := tracked.tailer.Prime(); err != nil {
+		m.logger.Warn("failed to prime log tailer", logging.Field("path", tracked.selection.Path), logging.Field("error", err))
+	}
+	tracked.progress.read(tracked.tailer)
+	m.recordOffset(tracked)
+}
+
 func (m *Monitor) handleWatcherEvent(event fsnotify.Event) {
 	m.logger.Debugf("fsnotify event: op=%s path=%s", event.Op.String(), event.Name)
 
+	if event.Op&fsnotify.Create != 0 {
+		m.maybeWatchNewDir(event.Name)
+	}
 	if event.Op&(fsnotify.Create|fsnotify.Rename|fsnotify.Write) != 0 {
+		m.maybeReadLocalEventPath(event.Name)
 		m.maybeTrackEventPath(event.Name)
 	}
 	if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
 		m.maybeUntrackPath(event.Name)
+		delete(m.watched, filepath.Clean(event.Name))
 	}
 
 	if tracked, ok := m.tracked[filepath.Clean(event.Name)]; ok && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
@@ -165,6 +216,10 @@ func (m *Monitor) handlePollTick() {
 		m.logger.Debug("poll tick: syncing tracked logs", logging.Field("tracked", len(m.tracked)))
 		m.lastPollTrackedCount = len(m.tracked)
 	}
+	m.syncLocalLogs()
+	for characterID := range m.localLogs {
+		m.readLocalLog(characterID)
+	}
 	if err := m.syncTrackedLogs(); err != nil {
 		m.logger.Debugf("log sync failed: %v", err)
 	}
@@ -173,6 +228,7 @@ func (m *Monitor) handlePollTick() {
 	}
 	m.reportChannelHealthTransitions(time.Now())
 	m.pruneRecentDedup(time.Now())
+	m.flushOffsets()
 }
 
 func (m *Monitor) handleChannelUpdate(updated []client.ChannelConfig) {
@@ -199,11 +255,21 @@ func (m *Monitor) syncTrackedLogs() error {
 	}
 
 	for path, sel := range desiredByPath {
+		sel = m.withHeader(sel)
 		if tracked, ok := m.tracked[path]; ok {
 			tracked.selection.Channel = sel.Channel
+			if sel.Listener != "" || sel.EVEChannelID != "" {
+				tracked.selection.Listener = sel.Listener
+				tracked.selection.EVEChannelID = sel.EVEChannelID
+			}
 			continue
 		}
 		m.addTrackedLog(sel)
+		// Startup catches up in initialize; logs that appear or gain a
+		// channel later must not replay their whole history.
+		if m.prepared {
+			m.catchUpTrackedLog(m.tracked[path])
+		}
 	}
 
 	for path := range m.tracked {
@@ -222,33 +288,81 @@ func (m *Monitor) syncTrackedLogs() error {
 
 func (m *Monitor) desiredSelections() ([]LogSelection, error) {
 	if m.opts.LogFile != "" {
-		channel, ok := ResolveChannelForPath(m.opts.LogFile, m.channels)
-		if !ok || channel.ID == "" {
+		selection, ok := resolveLogSelection(m.opts.LogFile, m.channels, m.headers)
+		if !ok || selection.Channel.ID == "" {
 			return nil, fmt.Errorf("failed to map log file to configured channel: %s", m.opts.LogFile)
 		}
-		return []LogSelection{{Path: m.opts.LogFile, Channel: channel}}, nil
+		return m.filterCharacters([]LogSelection{selection}), nil
 	}
 
-	if m.opts.LogDir == "" {
+	roots := m.watchDirs
+	if len(roots) == 0 {
+		roots = m.logRoots()
+	}
+	if len(roots) == 0 {
 		return nil, fmt.Errorf("missing log directory")
 	}
 
-	selections, err := FindLogs(m.opts.LogDir, m.channels)
+	selections, err := FindLogsInDirs(roots, m.channels, m.headers)
 	if err != nil {
 		return nil, err
 	}
-	return selections, nil
+	return m.filterCharacters(selections), nil
+}
+
+func (m *Monitor) filterCharacters(selections []LogSelection) []LogSelection {
+	if !m.opts.Characters.Enabled() {
+		return selections
+	}
+	out := selections[:0]
+	for _, sel := range selections {
+		if m.characterAllowed(sel.Path) {
+			out = append(out, sel)
+		}
+	}
+	return out
+}
+
+// characterAllowed applies the character filter to the log at path, logging
+// each skipped character once.
+func (m *Monitor) characterAllowed(path string) bool {
+	meta, ok := parseLogFileMeta(path)
+	if !ok || m.opts.Characters.Allows(meta.CharacterID) {
+		return true
+	}
+	if _, logged := m.skippedCharacters[meta.CharacterID]; !logged {
+		m.skippedCharacters[meta.CharacterID] = struct{}{}
+		m.logger.Info("skipping logs for filtered character",
+			logging.Field("character", m.opts.Characters.Describe(meta.CharacterID)),
+		)
+	}
+	return false
+}
+
+// withHeader fills in the header metadata that discovery skips reading when
+// the filename alone names the channel. Only tracked logs are read, through
+// the cache, which also keeps their headers past the next Prune.
+func (m *Monitor) withHeader(sel LogSelection) LogSelection {
+	if sel.Listener != "" {
+		return sel
+	}
+	if header, ok, _ := m.headers.Read(sel.Path); ok {
+		sel.Listener = header.Listener
+		sel.EVEChannelID = header.ChannelID
+	}
+	return sel
 }
 
 func (m *Monitor) addTrackedLog(sel LogSelection) {
 	path := filepath.Clean(sel.Path)
 	tailer := &Tailer{Path: sel.Path}
-	m.tracked[path] = &trackedLog{selection: sel, tailer: tailer}
+	m.tracked[path] = &trackedLog{selection: sel, tailer: tailer, progress: newLogProgress(sel.Path)}
 	m.logger.Info(
 		"tracking log file",
 		logging.Field("path", sel.Path),
 		logging.Field("channel", sel.Channel.Name),
 		logging.Field("channel_id", sel.Channel.ID),
+		logging.Field("listener", sel.Listener),
 	)
 	if m.callbacks.OnTracked != nil {
 		m.callbacks.OnTracked(sel)
@@ -335,10 +449,11 @@ func (m *Monitor) maybeTrackEventPath(path string) {
 		return
 	}
 	clean := filepath.Clean(path)
-	channel, ok := ResolveChannelForPath(clean, m.channels)
-	if !ok || channel.ID == "" {
+	selection, ok := resolveLogSelection(clean, m.channels, m.headers)
+	if !ok || selection.Channel.ID == "" {
 		return
 	}
+	channel := selection.Channel
 	meta, ok := parseLogFileMeta(clean)
 	if !ok {
 		return
@@ -346,6 +461,9 @@ func (m *Monitor) maybeTrackEventPath(path string) {
 	if info, err := os.Stat(clean); err != nil || info.IsDir() {
 		return
 	}
+	if !m.characterAllowed(clean) {
+		return
+	}
 	if existingPath, found := m.findTrackedForChannelCharacter(channel.ID, meta.CharacterID); found {
 		if existingPath == clean {
 			return
@@ -364,11 +482,9 @@ func (m *Monitor) maybeTrackEventPath(path string) {
 		}
 		delete(m.tracked, existingPath)
 	}
-	m.addTrackedLog(LogSelection{Path: clean, Channel: channel})
+	m.addTrackedLog(m.withHeader(selection))
 	if tracked, exists := m.tracked[clean]; exists {
-		if err := tracked.tailer.Prime(); err != nil {
-			m.logger.Debugf("failed to prime new log file %s: %v", clean, err)
-		}
+		m.catchUpTrackedLog(tracked)
 	}
 }
 
@@ -435,10 +551,55 @@ func (m *Monitor) maybeUntrackPath(path string) {
 }
 
 func (m *Monitor) readAndProcessTrackedLog(tracked *trackedLog) {
+	// A jump logged just before the report must be seen first.
+	if meta, ok := parseLogFileMeta(tracked.selection.Path); ok {
+		m.readLocalLog(meta.CharacterID)
+	}
+	from := tracked.tailer.Offset - int64(len(tracked.tailer.PendingBytes))
 	lines, err := tracked.tailer.ReadNewLines()
 	if err != nil {
 		m.logger.Debugf("failed to read new lines from %s: %v", tracked.tailer.Path, err)
 		return
 	}
-	m.processLines(lines, tracked.selection)
+	tracked.progress.read(tracked.tailer)
+	m.processLines(lines, tracked.selection, tracked.progress, from)
+	m.recordOffset(tracked)
+}
+
+// resumeTrackedLog continues a log from the offset saved by a previous run,
+// delivering only the lines written since.
+func (m *Monitor) resumeTrackedLog(tracked *trackedLog) bool {
+	offset, encoding, ok := m.opts.Offsets.Resume(tracked.selection.Path, time.Now())
+	if !ok {
+		return false
+	}
+	tracked.tailer.Offset = offset
+	tracked.tailer.Encoding = encoding
+	if err := tracked.tailer.Prime(); err != nil {
+		m.logger.Warn("failed to prime log tailer", logging.Field("path", tracked.selection.Path), logging.Field("error", err))
+		tracked.tailer.Offset = 0
+		tracked.tailer.Encoding = ""
+		return false
+	}
+	m.logger.Info("resuming log from saved offset",
+		logging.Field("path", tracked.selection.Path),
+		logging.Field("offset", offset),
+	)
+	m.readAndProcessTrackedLog(tracked)
+	return true
+}
+
+// recordOffset saves how far the log has been read, held back to the
+// oldest read whose reports are not all settled yet.
+func (m *Monitor) recordOffset(tracked *trackedLog) {
+	if m.opts.Offsets == nil {
+		return
+	}
+	tracked.progress.save(m.opts.Offsets, time.Now())
+}
+
+func (m *Monitor) flushOffsets() {
+	if err := m.opts.Offsets.Flush(time.Now()); err != nil {
+		m.logger.Warn("failed to save log offsets", logging.Field("path", m.opts.Offsets.Path()), logging.Field("error", err))
+	}
 }
@@ -3,12 +3,15 @@ package evelogs
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 
 	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/config"
+	"sentinel2-uploader/internal/evelogs/chatlogtest"
 	"sentinel2-uploader/internal/logging"
 )
 
@@ -35,7 +38,7 @@ func TestPrepare_TracksExistingLatestLogsPerConfiguredChannelCharacter(t *testin
 
 	monitor := NewMonitor(
 		MonitorOptions{
-			LogDir:   dir,
+			LogDirs:  []string{dir},
 			Channels: []client.ChannelConfig{{ID: "intel", Name: "Intel"}, {ID: "other", Name: "Other"}},
 		},
 		logger,
@@ -80,7 +83,7 @@ func TestHandleChannelUpdate_NewlyAddedChannelTracksNewestLogfile(t *testing.T)
 
 	monitor := NewMonitor(
 		MonitorOptions{
-			LogDir:   dir,
+			LogDirs:  []string{dir},
 			Channels: []client.ChannelConfig{{ID: "intel", Name: "Intel"}},
 		},
 		logger,
@@ -124,7 +127,7 @@ func TestHandleChannelUpdate_RemovedChannelStopsTrackingAndReporting(t *testing.
 	reportCount := 0
 	monitor := NewMonitor(
 		MonitorOptions{
-			LogDir:   dir,
+			LogDirs:  []string{dir},
 			Channels: []client.ChannelConfig{{ID: "intel", Name: "Intel"}},
 		},
 		logger,
@@ -185,7 +188,7 @@ func TestHandleWatcherEvent_WriteOnTrackedFileEmitsReport(t *testing.T) {
 	var last ReportEvent
 	monitor := NewMonitor(
 		MonitorOptions{
-			LogDir:   dir,
+			LogDirs:  []string{dir},
 			Channels: []client.ChannelConfig{{ID: "intel", Name: "Intel"}},
 		},
 		logger,
@@ -232,3 +235,133 @@ func TestHandleWatcherEvent_WriteOnTrackedFileEmitsReport(t *testing.T) {
 		t.Fatalf("CharacterID = %q, want charA", last.CharacterID)
 	}
 }
+
+func TestPrepare_TracksLogsAcrossMultipleRoots(t *testing.T) {
+	rootA := t.TempDir()
+	rootB := t.TempDir()
+	pathA := filepath.Join(rootA, "Intel_20260216_120000_charA.txt")
+	pathB := filepath.Join(rootB, "Chatlogs", "Intel_20260216_120000_charB.txt")
+	if err := os.MkdirAll(filepath.Dir(pathB), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	for _, path := range []string{pathA, pathB} {
+		if err := os.WriteFile(path, []byte("header\n"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+
+	logger := logging.New(false)
+	logger.SetTerminalOutputEnabled(false)
+	monitor := NewMonitor(
+		MonitorOptions{
+			LogDirs:  []string{rootA, rootB, filepath.Join(rootB, "missing")},
+			Channels: []client.ChannelConfig{{ID: "intel", Name: "Intel"}},
+		},
+		logger,
+		MonitorCallbacks{},
+	)
+
+	if err := monitor.Prepare(); err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	if len(monitor.tracked) != 2 {
+		t.Fatalf("tracked len = %d, want 2", len(monitor.tracked))
+	}
+	for _, path := range []string{pathA, pathB} {
+		if _, ok := monitor.tracked[filepath.Clean(path)]; !ok {
+			t.Fatalf("expected %s to be tracked", path)
+		}
+	}
+}
+
+func TestPrepare_AppliesCharacterFilter(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"Intel_20260216_120000_scout.txt", "Intel_20260216_120000_alt.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("header\n"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		mode    string
+		want    string
+		blocked string
+	}{
+		{mode: config.CharacterFilterAllow, want: "Intel_20260216_120000_scout.txt", blocked: "Intel_20260216_120000_alt.txt"},
+		{mode: config.CharacterFilterDeny, want: "Intel_20260216_120000_alt.txt", blocked: "Intel_20260216_120000_scout.txt"},
+	} {
+		logger := logging.New(false)
+		logger.SetTerminalOutputEnabled(false)
+		monitor := NewMonitor(
+			MonitorOptions{
+				LogDirs:    []string{dir},
+				Channels:   []client.ChannelConfig{{ID: "intel", Name: "Intel"}},
+				Characters: config.CharacterFilter{Mode: tc.mode, Characters: []config.CharacterEntry{{ID: "scout", Label: "Scout"}}},
+			},
+			logger,
+			MonitorCallbacks{},
+		)
+		if err := monitor.Prepare(); err != nil {
+			t.Fatalf("Prepare() error = %v", err)
+		}
+		if len(monitor.tracked) != 1 {
+			t.Fatalf("mode %s: tracked len = %d, want 1", tc.mode, len(monitor.tracked))
+		}
+		if _, ok := monitor.tracked[filepath.Join(dir, tc.want)]; !ok {
+			t.Fatalf("mode %s: expected %s to be tracked", tc.mode, tc.want)
+		}
+
+		monitor.maybeTrackEventPath(filepath.Join(dir, tc.blocked))
+		if len(monitor.tracked) != 1 {
+			t.Fatalf("mode %s: filtered character was tracked from watcher event", tc.mode)
+		}
+	}
+}
+
+func TestPrepare_SendsRecentLinesFromUTF16Log(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC()
+	session := chatlogtest.Session{Channel: "Intel", ChannelID: "-100", Listener: "Pilot", CharacterID: "charA"}
+	log, err := chatlogtest.Open(dir, session, now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("open log: %v", err)
+	}
+	if err := log.Say(now.Add(-10*time.Minute), "Scout", "stale report"); err != nil {
+		t.Fatalf("say: %v", err)
+	}
+	if err := log.Say(now.Add(-10*time.Second), "Scout", "recent report"); err != nil {
+		t.Fatalf("say: %v", err)
+	}
+
+	logger := logging.New(false)
+	logger.SetTerminalOutputEnabled(false)
+
+	var reports []ReportEvent
+	monitor := NewMonitor(
+		MonitorOptions{
+			LogDirs:  []string{dir},
+			Channels: []client.ChannelConfig{{ID: "intel", Name: "Intel"}},
+		},
+		logger,
+		MonitorCallbacks{
+			OnReport: func(event ReportEvent) error {
+				reports = append(reports, event)
+				return nil
+			},
+		},
+	)
+	if err := monitor.Prepare(); err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	if len(reports) != 1 || !strings.HasSuffix(reports[0].Line, "> recent report") {
+		t.Fatalf("reports = %+v, want only the recent report", reports)
+	}
+
+	if err := log.Say(now, "Scout", "live report"); err != nil {
+		t.Fatalf("say: %v", err)
+	}
+	monitor.handleWatcherEvent(fsnotify.Event{Name: log.Path(), Op: fsnotify.Write})
+	if len(reports) != 2 || !strings.HasSuffix(reports[1].Line, "> live report") {
+		t.Fatalf("reports = %+v, want the live report appended", reports)
+	}
+}
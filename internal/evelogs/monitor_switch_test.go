@@ -32,7 +32,7 @@ func TestMaybeTrackEventPath_ReplacesOlderTrackedFileForSameChannelAndCharacter(
 
 	monitor := NewMonitor(
 		MonitorOptions{
-			LogDir:   dir,
+			LogDirs:  []string{dir},
 			Channels: []client.ChannelConfig{{ID: "intel", Name: "Intel"}},
 		},
 		logger,
@@ -0,0 +1,231 @@
+package evelogs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultResumeMaxAge = 10 * time.Minute
+
+	// offsetRefreshInterval bounds how long an unchanged state file goes
+	// without being rewritten, so idle logs stay within the resume window.
+	offsetRefreshInterval = time.Minute
+)
+
+// savedOffset is the position in one log file up to which every report has
+// been settled.
+// Inode and Size identify the file so a replaced or truncated log is not
+// resumed at a stale position.
+type savedOffset struct {
+	Inode    uint64    `json:"inode,omitempty"`
+	Size     int64     `json:"size"`
+	Offset   int64     `json:"offset"`
+	Encoding string    `json:"encoding,omitempty"`
+	SavedAt  time.Time `json:"saved_at"`
+}
+
+type offsetFile struct {
+	Files map[string]savedOffset `json:"files"`
+}
+
+// OffsetStore persists tailer offsets across restarts so lines written while
+// the uploader was down are delivered instead of being skipped.
+//
+// A saved offset only passes a report once it is settled (see
+// ReportEvent.Settle), so reports still queued for upload when the process
+// dies are read again on the next start. Lines read alongside them may be
+// sent twice; their idempotency keys let the server drop the repeats.
+type OffsetStore struct {
+	mu        sync.Mutex
+	path      string
+	maxAge    time.Duration
+	entries   map[string]savedOffset
+	dirty     bool
+	lastWrite time.Time
+}
+
+func DefaultOffsetStorePath() (string, error) {
+	root, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, "sentinel2", "uploader", "offsets.json"), nil
+}
+
+// OpenOffsetStore loads the state file at path. A missing file yields an
+// empty store; an unreadable one is discarded and reported in the returned
+// error alongside a usable empty store.
+func OpenOffsetStore(path string, maxAge time.Duration) (*OffsetStore, error) {
+	if maxAge <= 0 {
+		maxAge = DefaultResumeMaxAge
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	store := &OffsetStore{path: path, maxAge: maxAge, entries: map[string]savedOffset{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state offsetFile
+	if err := json.Unmarshal(data, &state); err != nil {
+		store.dirty = true
+		return store, fmt.Errorf("discarding unreadable offset state %s: %w", path, err)
+	}
+	for path, entry := range state.Files {
+		store.entries[path] = entry
+	}
+	return store, nil
+}
+
+func (s *OffsetStore) Path() string {
+	if s == nil {
+		return ""
+	}
+	return s.path
+}
+
+// Resume returns the saved offset for path when it was recorded within the
+// max age and the file is still the same one, at least as long as before.
+func (s *OffsetStore) Resume(path string, now time.Time) (int64, string, bool) {
+	if s == nil {
+		return 0, "", false
+	}
+	s.mu.Lock()
+	entry, ok := s.entries[filepath.Clean(path)]
+	s.mu.Unlock()
+	if !ok || entry.Offset <= 0 || now.Sub(entry.SavedAt) > s.maxAge {
+		return 0, "", false
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Size() < entry.Offset || info.Size() < entry.Size {
+		return 0, "", false
+	}
+	if id := fileIdentity(path, info); id != 0 && entry.Inode != 0 && id != entry.Inode {
+		return 0, "", false
+	}
+	return entry.Offset, entry.Encoding, true
+}
+
+func (s *OffsetStore) record(path string, entry savedOffset) {
+	if s == nil || entry.Offset <= 0 {
+		return
+	}
+	key := filepath.Clean(path)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous, ok := s.entries[key]
+	if !ok || previous.Offset != entry.Offset || previous.Inode != entry.Inode || previous.Encoding != entry.Encoding {
+		s.dirty = true
+	}
+	s.entries[key] = entry
+}
+
+// Flush drops entries past the max age and writes the state file when
+// offsets moved or the saved timestamps are due for a refresh.
+func (s *OffsetStore) Flush(now time.Time) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for path, entry := range s.entries {
+		if now.Sub(entry.SavedAt) > s.maxAge {
+			delete(s.entries, path)
+			s.dirty = true
+		}
+	}
+	if !s.dirty && now.Sub(s.lastWrite) < offsetRefreshInterval {
+		return nil
+	}
+	payload, err := json.MarshalIndent(offsetFile{Files: s.entries}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, payload, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+	s.dirty = false
+	s.lastWrite = now
+	return nil
+}
+
+// logProgress is how far one tracked log has been read and settled. Each
+// report emitted from a read holds the offset that read started at until it
+// is settled, and the saved offset never passes the oldest one held. The
+// file identity is looked up once, when the log starts being tracked.
+type logProgress struct {
+	mu       sync.Mutex
+	path     string
+	identity uint64
+	size     int64
+	offset   int64
+	encoding string
+	held     map[int64]int
+}
+
+func newLogProgress(path string) *logProgress {
+	progress := &logProgress{path: path, held: map[int64]int{}}
+	if info, err := os.Stat(path); err == nil {
+		progress.identity = fileIdentity(path, info)
+	}
+	return progress
+}
+
+// read notes that t has read and decoded everything before its offset but
+// for a trailing half of a UTF-16 code unit.
+func (p *logProgress) read(t *Tailer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.size = t.Offset
+	p.offset = t.Offset - int64(len(t.PendingBytes))
+	p.encoding = t.Encoding
+}
+
+// hold keeps the saved offset at or before from until the returned func is
+// called, which saves the progress to store.
+func (p *logProgress) hold(from int64, store *OffsetStore) func() {
+	p.mu.Lock()
+	p.held[from]++
+	p.mu.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			p.mu.Lock()
+			if p.held[from]--; p.held[from] <= 0 {
+				delete(p.held, from)
+			}
+			p.mu.Unlock()
+			p.save(store, time.Now())
+		})
+	}
+}
+
+func (p *logProgress) save(store *OffsetStore, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	offset := p.offset
+	for from := range p.held {
+		offset = min(offset, from)
+	}
+	store.record(p.path, savedOffset{
+		Inode:    p.identity,
+		Size:     p.size,
+		Offset:   offset,
+		Encoding: p.encoding,
+		SavedAt:  now,
+	})
+}
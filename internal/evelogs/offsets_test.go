@@ -0,0 +1,241 @@
+package evelogs
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/logging"
+)
+
+func TestOffsetStore_ResumeChecksAgeAndFileSize(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "Intel_20260214_120000_9001.txt")
+	if err := os.WriteFile(logPath, []byte("header\nline\n"), 0o644); err != nil {
+		t.Fatalf("write %s: %v", logPath, err)
+	}
+	statePath := filepath.Join(dir, "state", "offsets.json")
+	now := time.Now()
+
+	store, err := OpenOffsetStore(statePath, time.Minute)
+	if err != nil {
+		t.Fatalf("OpenOffsetStore() error = %v", err)
+	}
+	store.record(logPath, savedOffset{Size: 12, Offset: 7, Encoding: "utf8", SavedAt: now})
+	if err := store.Flush(now); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	reopened, err := OpenOffsetStore(statePath, time.Minute)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	if offset, encoding, ok := reopened.Resume(logPath, now.Add(30*time.Second)); !ok || offset != 7 || encoding != "utf8" {
+		t.Fatalf("Resume() = %d, %q, %v; want 7, utf8, true", offset, encoding, ok)
+	}
+	if _, _, ok := reopened.Resume(logPath, now.Add(2*time.Minute)); ok {
+		t.Fatalf("Resume() past max age should fail")
+	}
+	if err := os.WriteFile(logPath, []byte("new\n"), 0o644); err != nil {
+		t.Fatalf("truncate %s: %v", logPath, err)
+	}
+	if _, _, ok := reopened.Resume(logPath, now); ok {
+		t.Fatalf("Resume() on truncated file should fail")
+	}
+
+	if err := os.WriteFile(statePath, []byte("{not json"), 0o600); err != nil {
+		t.Fatalf("corrupt state: %v", err)
+	}
+	if store, err := OpenOffsetStore(statePath, time.Minute); err == nil || store == nil {
+		t.Fatalf("OpenOffsetStore(corrupt) = %v, %v; want empty store and error", store, err)
+	}
+}
+
+func TestPrepare_ResumesFromSavedOffsetExactlyOnce(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "Intel_20260214_120000_9001.txt")
+	statePath := filepath.Join(t.TempDir(), "offsets.json")
+	stamp := time.Now().UTC().Format("2006.01.02 15:04:05")
+	if err := os.WriteFile(logPath, []byte("[ "+stamp+" ] Pilot > before restart\n"), 0o644); err != nil {
+		t.Fatalf("write %s: %v", logPath, err)
+	}
+
+	run := func() []string {
+		t.Helper()
+		store, err := OpenOffsetStore(statePath, time.Minute)
+		if err != nil {
+			t.Fatalf("OpenOffsetStore() error = %v", err)
+		}
+		logger := logging.New(false)
+		logger.SetTerminalOutputEnabled(false)
+		var lines []string
+		monitor := NewMonitor(MonitorOptions{
+			LogDirs:  []string{dir},
+			Channels: []client.ChannelConfig{{ID: "intel", Name: "Intel"}},
+			Offsets:  store,
+		}, logger, MonitorCallbacks{
+			OnReport: func(event ReportEvent) error {
+				lines = append(lines, event.Line)
+				event.Settle()
+				return nil
+			},
+		})
+		if err := monitor.Prepare(); err != nil {
+			t.Fatalf("Prepare() error = %v", err)
+		}
+		return lines
+	}
+
+	if got := run(); len(got) != 1 {
+		t.Fatalf("first run reports = %q, want the lookback line", got)
+	}
+
+	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("open append %s: %v", logPath, err)
+	}
+	if _, err := f.WriteString("[ " + stamp + " ] Pilot > while down\n"); err != nil {
+		_ = f.Close()
+		t.Fatalf("append line: %v", err)
+	}
+	_ = f.Close()
+
+	want := []string{"[ " + stamp + " ] Pilot > while down"}
+	if got := run(); !slices.Equal(got, want) {
+		t.Fatalf("second run reports = %q, want %q", got, want)
+	}
+	if got := run(); len(got) != 0 {
+		t.Fatalf("third run reports = %q, want none", got)
+	}
+}
+
+func TestPrepare_ReadsUnsettledReportsAgain(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "Intel_20260214_120000_9001.txt")
+	statePath := filepath.Join(t.TempDir(), "offsets.json")
+	stamp := time.Now().UTC().Format("2006.01.02 15:04:05")
+	if err := os.WriteFile(logPath, []byte("[ "+stamp+" ] Pilot > before restart\n"), 0o644); err != nil {
+		t.Fatalf("write %s: %v", logPath, err)
+	}
+
+	// run stops the way a crash would: events that are not settled stay in
+	// flight.
+	run := func(settle bool) []string {
+		t.Helper()
+		store, err := OpenOffsetStore(statePath, time.Minute)
+		if err != nil {
+			t.Fatalf("OpenOffsetStore() error = %v", err)
+		}
+		logger := logging.New(false)
+		logger.SetTerminalOutputEnabled(false)
+		var lines []string
+		monitor := NewMonitor(MonitorOptions{
+			LogDirs:  []string{dir},
+			Channels: []client.ChannelConfig{{ID: "intel", Name: "Intel"}},
+			Offsets:  store,
+		}, logger, MonitorCallbacks{
+			OnReport: func(event ReportEvent) error {
+				lines = append(lines, event.Line)
+				if settle {
+					event.Settle()
+				}
+				return nil
+			},
+		})
+		if err := monitor.Prepare(); err != nil {
+			t.Fatalf("Prepare() error = %v", err)
+		}
+		return lines
+	}
+
+	if got := run(true); len(got) != 1 {
+		t.Fatalf("first run reports = %q, want the lookback line", got)
+	}
+	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("open append %s: %v", logPath, err)
+	}
+	if _, err := f.WriteString("[ " + stamp + " ] Pilot > while down\n"); err != nil {
+		_ = f.Close()
+		t.Fatalf("append line: %v", err)
+	}
+	_ = f.Close()
+
+	want := []string{"[ " + stamp + " ] Pilot > while down"}
+	if got := run(false); !slices.Equal(got, want) {
+		t.Fatalf("second run reports = %q, want %q", got, want)
+	}
+	if got := run(true); !slices.Equal(got, want) {
+		t.Fatalf("run after unsettled exit reports = %q, want %q again", got, want)
+	}
+	if got := run(true); len(got) != 0 {
+		t.Fatalf("run after settled exit reports = %q, want none", got)
+	}
+}
+
+func TestPrepare_ResumesUTF16LogCutMidCodeUnit(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "Intel_20260214_120000_9001.txt")
+	statePath := filepath.Join(t.TempDir(), "offsets.json")
+	stamp := time.Now().UTC().Format("2006.01.02 15:04:05")
+	writeUTF16LELog(t, logPath, chatLogHeader("-1", "Intel", "Pilot")+"[ "+stamp+" ] Pilot > before restart\r\n")
+
+	next := utf16.Encode([]rune("[ " + stamp + " ] Pilot > while down\r\n"))
+	nextRaw := make([]byte, 0, 2*len(next))
+	for _, unit := range next {
+		nextRaw = binary.LittleEndian.AppendUint16(nextRaw, unit)
+	}
+	appendRaw := func(raw []byte) {
+		t.Helper()
+		f, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Fatalf("open append %s: %v", logPath, err)
+		}
+		defer f.Close()
+		if _, err := f.Write(raw); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	// The client is stopped mid write, half way through a UTF-16 code unit.
+	appendRaw(nextRaw[:1])
+
+	run := func() []string {
+		t.Helper()
+		store, err := OpenOffsetStore(statePath, time.Minute)
+		if err != nil {
+			t.Fatalf("OpenOffsetStore() error = %v", err)
+		}
+		logger := logging.New(false)
+		logger.SetTerminalOutputEnabled(false)
+		var lines []string
+		monitor := NewMonitor(MonitorOptions{
+			LogDirs:  []string{dir},
+			Channels: []client.ChannelConfig{{ID: "intel", Name: "Intel"}},
+			Offsets:  store,
+		}, logger, MonitorCallbacks{
+			OnReport: func(event ReportEvent) error {
+				lines = append(lines, event.Line)
+				event.Settle()
+				return nil
+			},
+		})
+		if err := monitor.Prepare(); err != nil {
+			t.Fatalf("Prepare() error = %v", err)
+		}
+		return lines
+	}
+
+	if got := run(); len(got) != 1 {
+		t.Fatalf("first run reports = %q, want the lookback line", got)
+	}
+	appendRaw(nextRaw[1:])
+	want := []string{"[ " + stamp + " ] Pilot > while down"}
+	if got := run(); !slices.Equal(got, want) {
+		t.Fatalf("second run reports = %q, want %q", got, want)
+	}
+}
@@ -1,27 +1,31 @@
 package evelogs
 
 import (
-	"bufio"
-	"os"
 	"strings"
 	"time"
 
 	"sentinel2-uploader/internal/logging"
 )
 
+// sendExistingLines sends report lines already in the log that are newer
+// than cutoff, then leaves the tailer at the end of what was read.
 func (m *Monitor) sendExistingLines(tracked *trackedLog, cutoff time.Time) error {
-	file, err := os.Open(tracked.selection.Path)
+	scan := &Tailer{Path: tracked.selection.Path}
+	lines, err := scan.ReadNewLines()
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	tracked.tailer.Offset = scan.Offset
+	tracked.tailer.Encoding = scan.Encoding
+	tracked.tailer.PendingBytes = scan.PendingBytes
+	tracked.progress.read(scan)
 
-	scanner := bufio.NewScanner(file)
 	var scanned int
 	var submitted int
-	for scanner.Scan() {
+	for _, raw := range lines {
 		scanned++
-		line := NormalizeLogLine(scanner.Text())
+		line := NormalizeLogLine(raw)
+		m.linesParsed.Add(1)
 		report, ok := ParseReportLine(line)
 		if !ok {
 			continue
@@ -36,27 +40,37 @@ func (m *Monitor) sendExistingLines(tracked *trackedLog, cutoff time.Time) error
 		if !cutoff.IsZero() && report.Time.Before(cutoff) {
 			continue
 		}
+		if m.filterDrops(tracked.selection, report) {
+			continue
+		}
+		line = m.redact(line, tracked.selection)
 		if m.shouldSkipLocalDuplicate(tracked.selection.Channel.ID, line, time.Now()) {
 			continue
 		}
-		if err := m.emitReport(tracked.selection, line, report.Time, time.Now()); err != nil {
+		if err := m.emitReport(tracked.selection, line, report.Time, time.Now(), m.holdOffset(tracked.progress, 0)); err != nil {
 			m.logger.Debugf("failed to emit existing report: %v", err)
 			continue
 		}
 		submitted++
 	}
 	m.logger.Debugf("existing scan complete: scanned=%d submitted=%d file=%s", scanned, submitted, tracked.selection.Path)
-	return scanner.Err()
+	return nil
 }
 
-func (m *Monitor) processLines(lines []string, selection LogSelection) {
+// processLines emits the reports among lines, which were read from offset
+// from of the log whose progress is given; progress is nil for lines that
+// are not resumed from.
+func (m *Monitor) processLines(lines []string, selection LogSelection, progress *logProgress, from int64) {
 	if len(lines) == 0 {
 		return
 	}
 	m.logger.Debugf("read %d new lines from %s", len(lines), selection.Path)
 	for _, line := range lines {
 		line = NormalizeLogLine(line)
-		m.logger.Debugf("line: %s", logging.Truncate(line))
+		if m.opts.Redactor.Len() == 0 {
+			m.logger.Debugf("line: %s", logging.Truncate(line))
+		}
+		m.linesParsed.Add(1)
 		report, ok := ParseReportLine(line)
 		if !ok {
 			m.logger.Debugf("skipping non-report line")
@@ -69,11 +83,15 @@ func (m *Monitor) processLines(lines []string, selection LogSelection) {
 			)
 			continue
 		}
+		if m.filterDrops(selection, report) {
+			continue
+		}
+		line = m.redact(line, selection)
 		if m.shouldSkipLocalDuplicate(selection.Channel.ID, line, time.Now()) {
 			m.logger.Debugf("skipping local duplicate line")
 			continue
 		}
-		if err := m.emitReport(selection, line, report.Time, time.Now()); err != nil {
+		if err := m.emitReport(selection, line, report.Time, time.Now(), m.holdOffset(progress, from)); err != nil {
 			m.logger.Warn("failed to emit report line", logging.Field("error", err))
 			continue
 		}
@@ -92,20 +110,79 @@ func shouldIgnoreReport(report ParsedReport) bool {
 	return strings.TrimSpace(report.Author) == "" || strings.TrimSpace(report.Message) == ""
 }
 
-func (m *Monitor) emitReport(selection LogSelection, line string, reportTime time.Time, now time.Time) error {
+// filterDrops applies the configured filter rules. In dry-run mode matching
+// lines are counted and logged but still uploaded.
+func (m *Monitor) filterDrops(selection LogSelection, report ParsedReport) bool {
+	filters := m.filters.Load()
+	rule, drop := filters.Check(selection.Channel, report)
+	if !drop {
+		return false
+	}
+	if filters.DryRun() {
+		m.logger.Debug("filter rule would drop report (dry run)",
+			logging.Field("rule", rule),
+			logging.Field("channel_id", selection.Channel.ID),
+			logging.Field("author", report.Author),
+		)
+		return false
+	}
+	m.logger.Debug("report dropped by filter rule",
+		logging.Field("rule", rule),
+		logging.Field("channel_id", selection.Channel.ID),
+		logging.Field("author", report.Author),
+	)
+	return true
+}
+
+// redact scrubs sensitive spans from line. Only the names of the rules that
+// fired are logged, never the original text.
+func (m *Monitor) redact(line string, selection LogSelection) string {
+	redacted, fired := m.opts.Redactor.Redact(line)
+	if len(fired) > 0 {
+		m.logger.Debug("redacted report line",
+			logging.Field("rules", strings.Join(fired, ",")),
+			logging.Field("channel_id", selection.Channel.ID),
+		)
+	}
+	return redacted
+}
+
+// holdOffset keeps the saved offset of a log at or before from until the
+// report it is attached to is settled. It returns nil when offsets are not
+// saved.
+func (m *Monitor) holdOffset(progress *logProgress, from int64) func() {
+	if m.opts.Offsets == nil || progress == nil {
+		return nil
+	}
+	return progress.hold(from, m.opts.Offsets)
+}
+
+// emitReport hands a report to OnReport. settle, when set, becomes the
+// event's Settle; it is called here when there is no one to hand it to.
+func (m *Monitor) emitReport(selection LogSelection, line string, reportTime time.Time, now time.Time, settle func()) error {
 	if m.callbacks.OnReport == nil {
+		if settle != nil {
+			settle()
+		}
 		m.markLocalDuplicate(selection.Channel.ID, line, now)
 		return nil
 	}
 	meta, _ := parseLogFileMeta(selection.Path)
 	err := m.callbacks.OnReport(ReportEvent{
-		Line:        line,
-		Channel:     selection.Channel,
-		SourcePath:  selection.Path,
-		CharacterID: meta.CharacterID,
-		Timestamp:   reportTime,
+		Line:           line,
+		Channel:        selection.Channel,
+		SourcePath:     selection.Path,
+		CharacterID:    meta.CharacterID,
+		Listener:       selection.Listener,
+		EVEChannelID:   selection.EVEChannelID,
+		Timestamp:      reportTime,
+		ReporterSystem: m.locations.SystemAt(meta.CharacterID, reportTime),
+		settle:         settle,
 	})
 	if err != nil {
+		if settle != nil {
+			settle()
+		}
 		if m.callbacks.OnError != nil {
 			m.callbacks.OnError(err)
 		}
@@ -126,6 +203,7 @@ func (m *Monitor) shouldSkipLocalDuplicate(channelID string, line string, now ti
 	}
 	key := channelID + "\x00" + line
 	if seenAt, ok := m.recent[key]; ok && now.Sub(seenAt) <= m.opts.DedupWindow {
+		m.dedupHits.Add(1)
 		return true
 	}
 	return false
@@ -0,0 +1,100 @@
+package evelogs
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const defaultRedactionPlaceholder = "[redacted]"
+
+// RedactionRule replaces every span of a report message matching Pattern
+// with Placeholder before the report is uploaded.
+type RedactionRule struct {
+	Name        string
+	Pattern     string
+	Placeholder string
+}
+
+// URLRedactionRule scrubs web links, including bare www. hosts.
+func URLRedactionRule() RedactionRule {
+	return RedactionRule{Name: "url", Pattern: `(?i)\b(?:https?|ftp)://\S+|\bwww\.\S+`, Placeholder: "[url]"}
+}
+
+// EmailRedactionRule scrubs email addresses.
+func EmailRedactionRule() RedactionRule {
+	return RedactionRule{Name: "email", Pattern: `(?i)\b[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}\b`, Placeholder: "[email]"}
+}
+
+type Redactor struct {
+	rules []compiledRedaction
+}
+
+type compiledRedaction struct {
+	name        string
+	pattern     *regexp.Regexp
+	placeholder string
+}
+
+// NewRedactor compiles rules in order. Invalid rules are left out and
+// reported in the returned error; the redactor still applies the rest.
+func NewRedactor(rules []RedactionRule) (*Redactor, error) {
+	redactor := &Redactor{}
+	var errs []error
+	for i, rule := range rules {
+		name := strings.TrimSpace(rule.Name)
+		if name == "" {
+			name = fmt.Sprintf("rule #%d", i+1)
+		}
+		pattern := strings.TrimSpace(rule.Pattern)
+		if pattern == "" {
+			errs = append(errs, fmt.Errorf("redaction rule %s: empty pattern", name))
+			continue
+		}
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("redaction rule %s: %w", name, err))
+			continue
+		}
+		placeholder := rule.Placeholder
+		if placeholder == "" {
+			placeholder = defaultRedactionPlaceholder
+		}
+		redactor.rules = append(redactor.rules, compiledRedaction{name: name, pattern: compiled, placeholder: placeholder})
+	}
+	return redactor, errors.Join(errs...)
+}
+
+func (r *Redactor) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.rules)
+}
+
+// Redact scrubs the message part of a report line, leaving the timestamp and
+// author untouched, and returns the names of the rules that fired.
+func (r *Redactor) Redact(line string) (string, []string) {
+	if r.Len() == 0 {
+		return line, nil
+	}
+	// Authors cannot contain '>', so the first " > " ends the header.
+	sep := strings.Index(line, " > ")
+	if sep < 0 {
+		return line, nil
+	}
+	prefix, message := line[:sep+3], line[sep+3:]
+	var fired []string
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(message) {
+			continue
+		}
+		message = rule.pattern.ReplaceAllLiteralString(message, rule.placeholder)
+		fired = append(fired, rule.name)
+	}
+	if len(fired) == 0 {
+		return line, nil
+	}
+	return prefix + message, fired
+}
@@ -0,0 +1,76 @@
+package evelogs
+
+import (
+	"slices"
+	"testing"
+
+	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/logging"
+)
+
+func TestRedactor_ScrubsMessageOnly(t *testing.T) {
+	redactor, err := NewRedactor([]RedactionRule{
+		URLRedactionRule(),
+		EmailRedactionRule(),
+		{Name: "discord", Pattern: `discord\.gg/\w+`},
+		{Name: "broken", Pattern: `(`},
+	})
+	if err == nil {
+		t.Fatalf("NewRedactor() expected error for invalid pattern")
+	}
+	if redactor.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3 valid rules", redactor.Len())
+	}
+
+	cases := []struct {
+		line  string
+		want  string
+		fired []string
+	}{
+		{
+			line:  "[ 2026.02.14 12:00:00 ] Pilot > 1DQ1-A red see https://zkillboard.com/kill/1/ now",
+			want:  "[ 2026.02.14 12:00:00 ] Pilot > 1DQ1-A red see [url] now",
+			fired: []string{"url"},
+		},
+		{
+			line:  "[ 2026.02.14 12:00:00 ] Pilot > mail fc@example.com or join discord.gg/abc",
+			want:  "[ 2026.02.14 12:00:00 ] Pilot > mail [email] or join [redacted]",
+			fired: []string{"email", "discord"},
+		},
+		{
+			line: "[ 2026.02.14 12:00:00 ] Pilot > 1DQ1-A clr",
+			want: "[ 2026.02.14 12:00:00 ] Pilot > 1DQ1-A clr",
+		},
+	}
+	for _, tc := range cases {
+		got, fired := redactor.Redact(tc.line)
+		if got != tc.want || !slices.Equal(fired, tc.fired) {
+			t.Fatalf("Redact(%q) = %q, %v; want %q, %v", tc.line, got, fired, tc.want, tc.fired)
+		}
+	}
+}
+
+func TestProcessLines_UploadsRedactedLine(t *testing.T) {
+	redactor, err := NewRedactor([]RedactionRule{URLRedactionRule()})
+	if err != nil {
+		t.Fatalf("NewRedactor() error = %v", err)
+	}
+	logger := logging.New(false)
+	logger.SetTerminalOutputEnabled(false)
+	var lines []string
+	monitor := NewMonitor(MonitorOptions{Redactor: redactor}, logger, MonitorCallbacks{
+		OnReport: func(event ReportEvent) error {
+			lines = append(lines, event.Line)
+			return nil
+		},
+	})
+
+	monitor.processLines([]string{
+		"[ 2026.02.14 12:00:00 ] Pilot > 1DQ1-A red http://example.com/fit",
+	}, LogSelection{Path: "Intel_20260214_120000_1.txt", Channel: client.ChannelConfig{ID: "intel", Name: "Intel"}}, nil, 0)
+
+	want := []string{"[ 2026.02.14 12:00:00 ] Pilot > 1DQ1-A red [url]"}
+	if !slices.Equal(lines, want) {
+		t.Fatalf("reported lines = %q, want %q", lines, want)
+	}
+}
@@ -0,0 +1,141 @@
+package evelogs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/config"
+	"sentinel2-uploader/internal/logging"
+)
+
+// ReplayOptions selects historical chat logs to upload again.
+type ReplayOptions struct {
+	// Paths are log files or directories; directories are scanned like log
+	// roots and every matching log in them is read, not just the newest.
+	Paths []string
+	// From and To bound report times, inclusive. Zero values leave that
+	// side of the range open.
+	From       time.Time
+	To         time.Time
+	Channels   []client.ChannelConfig
+	Characters config.CharacterFilter
+	Filters    *FilterSet
+	Redactor   *Redactor
+}
+
+// CollectReplay reads the logs selected by opts and returns their reports
+// oldest first. Lines go through the same parsing, ignore rules, filters,
+// redaction and cross-character dedup as live tailing; the tailing
+// lookback does not apply.
+func CollectReplay(opts ReplayOptions, logger *logging.Logger) ([]ReportEvent, error) {
+	selections, err := replaySelections(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []ReportEvent
+	seen := map[string]struct{}{}
+	for _, selection := range selections {
+		meta, _ := parseLogFileMeta(selection.Path)
+		if !opts.Characters.Allows(meta.CharacterID) {
+			logger.Debug("replay skipping filtered character", logging.Field("path", selection.Path))
+			continue
+		}
+		lines, err := (&Tailer{Path: selection.Path}).ReadNewLines()
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", selection.Path, err)
+		}
+		var accepted int
+		for _, raw := range lines {
+			line := NormalizeLogLine(raw)
+			report, ok := ParseReportLine(line)
+			if !ok || shouldIgnoreReport(report) {
+				continue
+			}
+			if !opts.From.IsZero() && report.Time.Before(opts.From) {
+				continue
+			}
+			if !opts.To.IsZero() && report.Time.After(opts.To) {
+				continue
+			}
+			if _, drop := opts.Filters.Check(selection.Channel, report); drop && !opts.Filters.DryRun() {
+				continue
+			}
+			line, _ = opts.Redactor.Redact(line)
+			key := selection.Channel.ID + "\x00" + line
+			if _, dup := seen[key]; dup {
+				continue
+			}
+			seen[key] = struct{}{}
+			events = append(events, ReportEvent{
+				Line:         line,
+				Channel:      selection.Channel,
+				SourcePath:   selection.Path,
+				CharacterID:  meta.CharacterID,
+				Listener:     selection.Listener,
+				EVEChannelID: selection.EVEChannelID,
+				Timestamp:    report.Time,
+			})
+			accepted++
+		}
+		logger.Debug("replay read log",
+			logging.Field("path", selection.Path),
+			logging.Field("channel_id", selection.Channel.ID),
+			logging.Field("lines", len(lines)),
+			logging.Field("reports", accepted),
+		)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+	return events, nil
+}
+
+// replaySelections resolves opts.Paths to configured-channel logs. A file
+// given explicitly must match a channel; logs found in a directory that
+// cannot overlap the time range are skipped.
+func replaySelections(opts ReplayOptions) ([]LogSelection, error) {
+	var out []LogSelection
+	seen := map[string]struct{}{}
+	add := func(selection LogSelection) {
+		clean := filepath.Clean(selection.Path)
+		if _, ok := seen[clean]; ok {
+			return
+		}
+		seen[clean] = struct{}{}
+		out = append(out, selection)
+	}
+	for _, path := range opts.Paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			selection, ok := ResolveLogSelection(path, opts.Channels)
+			if !ok {
+				return nil, fmt.Errorf("%s is not a chat log of a configured channel", path)
+			}
+			add(selection)
+			continue
+		}
+		matches, err := findLogMatches(path, opts.Channels, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			if !opts.To.IsZero() && m.Meta.Timestamp.After(opts.To) {
+				continue
+			}
+			if !opts.From.IsZero() && m.ModTime.Before(opts.From) {
+				continue
+			}
+			add(m.Selection)
+		}
+	}
+	return out, nil
+}
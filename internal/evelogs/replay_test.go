@@ -0,0 +1,85 @@
+package evelogs
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/evelogs/chatlogtest"
+	"sentinel2-uploader/internal/logging"
+)
+
+func TestCollectReplay_OrdersDedupsAndBoundsReports(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 2, 16, 19, 0, 0, 0, time.UTC)
+	open := func(session chatlogtest.Session, started time.Time) *chatlogtest.Log {
+		t.Helper()
+		log, err := chatlogtest.Open(dir, session, started)
+		if err != nil {
+			t.Fatalf("open log: %v", err)
+		}
+		return log
+	}
+	say := func(log *chatlogtest.Log, at time.Time, author string, message string) string {
+		t.Helper()
+		if err := log.Say(at, author, message); err != nil {
+			t.Fatalf("say: %v", err)
+		}
+		return chatlogtest.Line(at, author, message)
+	}
+
+	pilotA := open(chatlogtest.Session{Channel: "Intel", ChannelID: "-100", Listener: "Pilot A", CharacterID: "90000001"}, base)
+	pilotB := open(chatlogtest.Session{Channel: "Intel", ChannelID: "-100", Listener: "Pilot B", CharacterID: "90000002"}, base)
+	other := open(chatlogtest.Session{Channel: "Corp", ChannelID: "-300", Listener: "Pilot A", CharacterID: "90000001"}, base)
+
+	say(pilotA, base.Add(1*time.Minute), "Scout", "before the fight")
+	second := say(pilotB, base.Add(12*time.Minute), "Scout", "HED-GP +20")
+	first := say(pilotA, base.Add(10*time.Minute), "Scout", "1DQ1-A 5x Sabre")
+	say(pilotB, base.Add(10*time.Minute), "Scout", "1DQ1-A 5x Sabre")
+	say(pilotA, base.Add(11*time.Minute), "EVE System", "Channel MOTD")
+	say(other, base.Add(11*time.Minute), "CEO", "not intel")
+	say(pilotA, base.Add(40*time.Minute), "Scout", "after the fight")
+
+	logger := logging.New(false)
+	logger.SetTerminalOutputEnabled(false)
+	events, err := CollectReplay(ReplayOptions{
+		Paths:    []string{dir},
+		From:     base.Add(5 * time.Minute),
+		To:       base.Add(30 * time.Minute),
+		Channels: []client.ChannelConfig{{ID: "intel", Name: "Intel"}},
+	}, logger)
+	if err != nil {
+		t.Fatalf("CollectReplay() error = %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("events = %+v, want 2", events)
+	}
+	if events[0].Line != first || events[1].Line != second {
+		t.Fatalf("lines = %q, %q; want %q, %q", events[0].Line, events[1].Line, first, second)
+	}
+	if events[0].Channel.ID != "intel" || !events[0].Timestamp.Equal(base.Add(10*time.Minute)) {
+		t.Fatalf("first event = %+v", events[0])
+	}
+	if events[1].CharacterID != "90000002" {
+		t.Fatalf("second event CharacterID = %q, want 90000002", events[1].CharacterID)
+	}
+}
+
+func TestCollectReplay_FileMustMatchConfiguredChannel(t *testing.T) {
+	dir := t.TempDir()
+	log, err := chatlogtest.Open(dir, chatlogtest.Session{Channel: "Corp", ChannelID: "-300", Listener: "Pilot", CharacterID: "90000001"}, time.Now())
+	if err != nil {
+		t.Fatalf("open log: %v", err)
+	}
+
+	logger := logging.New(false)
+	logger.SetTerminalOutputEnabled(false)
+	_, err = CollectReplay(ReplayOptions{
+		Paths:    []string{log.Path()},
+		Channels: []client.ChannelConfig{{ID: "intel", Name: "Intel"}},
+	}, logger)
+	if err == nil {
+		t.Fatalf("CollectReplay(%s) error = nil, want unmatched channel error", filepath.Base(log.Path()))
+	}
+}
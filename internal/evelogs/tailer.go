@@ -23,15 +23,14 @@ func (t *Tailer) Prime() error {
 	}
 
 	if t.Encoding == "" {
+		// A file too short for a byte order mark is still being created;
+		// the encoding is detected once it has been written.
+		if info.Size() < 2 {
+			return nil
+		}
 		header := make([]byte, 2)
 		n, _ := io.ReadFull(file, header)
-		if n == 2 && header[0] == 0xFF && header[1] == 0xFE {
-			t.Encoding = "utf16le"
-		} else if n == 2 && header[0] == 0xFE && header[1] == 0xFF {
-			t.Encoding = "utf16be"
-		} else {
-			t.Encoding = "utf8"
-		}
+		t.Encoding = detectLogEncoding(header[:n])
 	}
 
 	if t.Offset == 0 {
@@ -55,6 +54,14 @@ func (t *Tailer) ReadNewLines() ([]string, error) {
 	if info.Size() < t.Offset {
 		t.Offset = 0
 	}
+	if t.Encoding == "" && t.Offset == 0 {
+		if info.Size() < 2 {
+			return nil, nil
+		}
+		header := make([]byte, 2)
+		n, _ := io.ReadFull(file, header)
+		t.Encoding = detectLogEncoding(header[:n])
+	}
 	if _, err := file.Seek(t.Offset, io.SeekStart); err != nil {
 		return nil, err
 	}
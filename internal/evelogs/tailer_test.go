@@ -0,0 +1,32 @@
+package evelogs
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestTailer_DetectsEncodingOnceTheByteOrderMarkIsWritten(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Intel_20260214_120000_9001.txt")
+	// The client has created the file but not written to it yet.
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatalf("create %s: %v", path, err)
+	}
+	tailer := &Tailer{Path: path}
+	if err := tailer.Prime(); err != nil {
+		t.Fatalf("Prime() error = %v", err)
+	}
+	if lines, err := tailer.ReadNewLines(); err != nil || len(lines) != 0 {
+		t.Fatalf("ReadNewLines(empty) = %q, %v", lines, err)
+	}
+
+	writeUTF16LELog(t, path, "[ 2026.02.14 12:00:05 ] Pilot > clear\r\n")
+	lines, err := tailer.ReadNewLines()
+	if err != nil {
+		t.Fatalf("ReadNewLines() error = %v", err)
+	}
+	if want := []string{"[ 2026.02.14 12:00:05 ] Pilot > clear"}; tailer.Encoding != "utf16le" || !slices.Equal(lines, want) {
+		t.Fatalf("ReadNewLines() = %q with encoding %q, want %q as utf16le", lines, tailer.Encoding, want)
+	}
+}
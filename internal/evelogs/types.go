@@ -1,9 +1,13 @@
 package evelogs
 
 import (
+	"sync/atomic"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
+
 	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/config"
 	"sentinel2-uploader/internal/logging"
 )
 
@@ -12,25 +16,44 @@ type Monitor struct {
 	logger    *logging.Logger
 	callbacks MonitorCallbacks
 
-	channels []client.ChannelConfig
-	watchDir string
-	prepared bool
-
-	tracked map[string]*trackedLog
-	recent  map[string]time.Time
-	health  map[string]channelHealthState
+	channels  []client.ChannelConfig
+	watchDirs []string
+	watcher   *fsnotify.Watcher
+	watched   map[string]struct{}
+	prepared  bool
+
+	tracked           map[string]*trackedLog
+	recent            map[string]time.Time
+	health            map[string]channelHealthState
+	skippedCharacters map[string]struct{}
+	filters           atomic.Pointer[FilterSet]
+	linesParsed       atomic.Uint64
+	dedupHits         atomic.Uint64
+	// localLogs tails the newest Local log per character ID for locations.
+	localLogs map[string]*Tailer
+	locations *LocationTracker
+	// headers caches parsed log headers for the logs found by the latest scan.
+	headers *HeaderCache
 
 	lastPollTrackedCount      int
 	lastDesiredSelectionCount int
 }
 
 type MonitorOptions struct {
-	LogDir          string
+	// LogDirs are the roots to scan and watch, already trimmed, cleaned and
+	// deduplicated as by config.Options.LogRoots.
+	LogDirs         []string
 	LogFile         string
 	Channels        []client.ChannelConfig
 	RescanPeriod    time.Duration
 	DedupWindow     time.Duration
 	InitialLookback time.Duration
+	Characters      config.CharacterFilter
+	Filters         *FilterSet
+	Redactor        *Redactor
+	// Offsets, when set, lets startup resume each log where the previous
+	// run left off instead of replaying InitialLookback.
+	Offsets *OffsetStore
 }
 
 type MonitorCallbacks struct {
@@ -42,16 +65,37 @@ type MonitorCallbacks struct {
 }
 
 type ReportEvent struct {
-	Line        string
-	Channel     client.ChannelConfig
-	SourcePath  string
-	CharacterID string
-	Timestamp   time.Time
+	Line         string
+	Channel      client.ChannelConfig
+	SourcePath   string
+	CharacterID  string
+	Listener     string
+	EVEChannelID string
+	Timestamp    time.Time
+	// ReporterSystem is the solar system the reporting character was in,
+	// from their Local log; empty when unknown.
+	ReporterSystem string
+
+	settle func()
 }
 
+// Settle tells the monitor the report was uploaded, written to the outbox or
+// dropped for good, so a restart resumes its log after it. Until then the
+// saved offset stays before the report. It is safe to call more than once
+// and on events that did not come from a monitor.
+func (e ReportEvent) Settle() {
+	if e.settle != nil {
+		e.settle()
+	}
+}
+
+// LogSelection is a chat log mapped to a configured channel. Listener and
+// EVEChannelID come from the log header and are empty until it is written.
 type LogSelection struct {
-	Path    string
-	Channel client.ChannelConfig
+	Path         string
+	Channel      client.ChannelConfig
+	Listener     string
+	EVEChannelID string
 }
 
 type Tailer struct {
@@ -76,6 +120,7 @@ type logMatch struct {
 type trackedLog struct {
 	selection LogSelection
 	tailer    *Tailer
+	progress  *logProgress
 }
 
 type channelHealthState int
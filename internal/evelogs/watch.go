@@ -0,0 +1,117 @@
+package evelogs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sentinel2-uploader/internal/logging"
+)
+
+// logRoots returns the directories to scan and watch: LogDirs, or the
+// directory of LogFile when no root is configured.
+func (m *Monitor) logRoots() []string {
+	if len(m.opts.LogDirs) == 0 && strings.TrimSpace(m.opts.LogFile) != "" {
+		return []string{filepath.Dir(m.opts.LogFile)}
+	}
+	return m.opts.LogDirs
+}
+
+// watchRoots registers every root and its subdirectories with the watcher.
+// A root that cannot be watched is logged and skipped; it is an error only
+// when no root could be watched at all.
+func (m *Monitor) watchRoots() error {
+	var errs []error
+	for _, root := range m.watchDirs {
+		if err := m.watchTree(root, 0); err != nil {
+			m.logger.Warn("failed to watch log directory", logging.Field("directory", root), logging.Field("error", err))
+			errs = append(errs, fmt.Errorf("failed to watch log directory %s: %w", root, err))
+		}
+	}
+	if len(errs) == len(m.watchDirs) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// watchTree adds dir and its subdirectories, up to maxLogDirDepth below the
+// owning root, to the watcher. depth is dir's own depth below that root.
+func (m *Monitor) watchTree(dir string, depth int) error {
+	if m.watcher == nil {
+		return nil
+	}
+	dir = filepath.Clean(dir)
+	if err := m.addWatch(dir); err != nil {
+		return err
+	}
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if path == dir {
+			return nil
+		}
+		if err != nil || !entry.IsDir() {
+			if entry != nil && entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if depth+logDirDepth(dir, path) > maxLogDirDepth {
+			return fs.SkipDir
+		}
+		if addErr := m.addWatch(path); addErr != nil {
+			m.logger.Debugf("failed to watch log subdirectory %s: %v", path, addErr)
+			return fs.SkipDir
+		}
+		return nil
+	})
+}
+
+func (m *Monitor) addWatch(dir string) error {
+	if _, ok := m.watched[dir]; ok {
+		return nil
+	}
+	if err := m.watcher.Add(dir); err != nil {
+		return err
+	}
+	m.watched[dir] = struct{}{}
+	m.logger.Debugf("watching directory: %s", dir)
+	return nil
+}
+
+// maybeWatchNewDir starts watching a directory created under one of the
+// roots, e.g. a new Chatlogs folder for a freshly installed client.
+func (m *Monitor) maybeWatchNewDir(path string) {
+	if m.watcher == nil || path == "" {
+		return
+	}
+	clean := filepath.Clean(path)
+	info, err := os.Stat(clean)
+	if err != nil || !info.IsDir() {
+		return
+	}
+	depth, ok := m.rootDepth(clean)
+	if !ok || depth > maxLogDirDepth {
+		return
+	}
+	if err := m.watchTree(clean, depth); err != nil {
+		m.logger.Debugf("failed to watch new log subdirectory %s: %v", clean, err)
+	}
+}
+
+// rootDepth returns how deep path sits below the closest root containing it.
+func (m *Monitor) rootDepth(path string) (int, bool) {
+	best, found := 0, false
+	for _, root := range m.watchDirs {
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		depth := logDirDepth(root, path)
+		if !found || depth < best {
+			best, found = depth, true
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,291 @@
+// Package intel extracts structured information from intel channel messages
+// using bundled static data for ship types and named solar systems.
+package intel
+
+import (
+	"bufio"
+	_ "embed"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed data/ships.txt
+var shipsData string
+
+//go:embed data/systems.txt
+var systemsData string
+
+// maxNameWords is the longest ship or system name, in words, looked up.
+const maxNameWords = 4
+
+var (
+	// The shape of procedurally named null-sec systems such as 1DQ1-A or
+	// HED-GP and wormholes such as J123456.
+	proceduralSystemPattern = regexp.MustCompile(`^(?:[A-Z0-9]{1,5}-[A-Z0-9]{1,6}|J[0-9]{6})$`)
+	digitPattern            = regexp.MustCompile(`[0-9]`)
+	letterPattern           = regexp.MustCompile(`[A-Za-z]`)
+	plusCountPattern        = regexp.MustCompile(`^\+([0-9]{1,3})$`)
+	timesCountPattern       = regexp.MustCompile(`^(?:([0-9]{1,3})x|x([0-9]{1,3}))$`)
+)
+
+// Report is the structured form of one intel message. Fields are left empty
+// when nothing was recognised.
+type Report struct {
+	Systems  []string
+	Ships    []Ship
+	Pilots   int
+	Clear    bool
+	NoVisual bool
+	Gates    []string
+}
+
+type Ship struct {
+	Type  string
+	Count int
+}
+
+func (r Report) Empty() bool {
+	return len(r.Systems) == 0 && len(r.Ships) == 0 && r.Pilots == 0 &&
+		!r.Clear && !r.NoVisual && len(r.Gates) == 0
+}
+
+type dictionary struct {
+	ships   map[string]string
+	systems map[string]string
+	// wordShips holds the canonical names of ships that are also everyday
+	// words, marked with "!" in the data.
+	wordShips map[string]struct{}
+	// procedural holds the bundled systems with procedural names, upper
+	// case. They are kept apart from systems so lower-case chat is only
+	// taken for one under the rules in matchSystem.
+	procedural map[string]struct{}
+}
+
+var loadDictionary = sync.OnceValue(func() dictionary {
+	return newDictionary(shipsData, systemsData)
+})
+
+func newDictionary(ships string, systems string) dictionary {
+	dict := dictionary{procedural: map[string]struct{}{}}
+	dict.ships, dict.wordShips = parseNames(ships)
+	dict.systems, _ = parseNames(systems)
+	for key, name := range dict.systems {
+		if proceduralSystemPattern.MatchString(name) {
+			dict.procedural[name] = struct{}{}
+			delete(dict.systems, key)
+		}
+	}
+	return dict
+}
+
+// parseNames reads "Name|alias, alias" lines into a lower-case lookup of
+// canonical names, and returns the names marked with a leading "!" apart.
+func parseNames(data string) (map[string]string, map[string]struct{}) {
+	names := map[string]string{}
+	marked := map[string]struct{}{}
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, aliases, _ := strings.Cut(line, "|")
+		name = strings.TrimSpace(name)
+		if rest, ok := strings.CutPrefix(name, "!"); ok {
+			name = strings.TrimSpace(rest)
+			marked[name] = struct{}{}
+		}
+		names[strings.ToLower(name)] = name
+		for alias := range strings.SplitSeq(aliases, ",") {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				names[strings.ToLower(alias)] = name
+			}
+		}
+	}
+	return names, marked
+}
+
+// Parse extracts systems, ships, pilot counts, clear and no-visual markers
+// and gate mentions from the message part of a report line.
+func Parse(message string) Report {
+	return loadDictionary().parse(message)
+}
+
+func (d dictionary) parse(message string) Report {
+	tokens := tokenize(message)
+	var report Report
+	pendingCount := 0
+	for i := 0; i < len(tokens); {
+		token := tokens[i]
+		lower := strings.ToLower(token)
+
+		if ship, n, ok := d.matchShip(tokens[i:]); ok {
+			count := pendingCount
+			if i+n < len(tokens) {
+				if c, ok := timesCount(tokens[i+n]); ok {
+					count = c
+					n++
+				}
+			}
+			// "Probe" or "Legion" on their own are more likely words.
+			if _, word := d.wordShips[ship]; !word || count > 0 {
+				pendingCount = 0
+				report.addShip(ship, max(count, 1))
+				i += n
+				continue
+			}
+		}
+		if system, n, ok := d.matchSystem(tokens[i:]); ok {
+			report.Systems = appendUnique(report.Systems, system)
+			if i+n < len(tokens) && strings.EqualFold(tokens[i+n], "gate") {
+				report.Gates = appendUnique(report.Gates, system)
+				n++
+			}
+			pendingCount = 0
+			i += n
+			continue
+		}
+
+		switch {
+		case lower == "clr" || lower == "clear":
+			report.Clear = true
+		case lower == "nv" || lower == "novis" || lower == "novisual":
+			report.NoVisual = true
+		case lower == "no" && i+1 < len(tokens) && isVisualWord(tokens[i+1]):
+			report.NoVisual = true
+			i++
+		default:
+			if c, ok := plusCount(token); ok {
+				report.Pilots += c
+			} else if c, ok := timesCount(token); ok {
+				// "5x Sabre" counts ships; "5x" on its own counts pilots.
+				if _, _, nextIsShip := d.matchShip(tokens[i+1:]); nextIsShip {
+					pendingCount = c
+				} else {
+					report.Pilots += c
+				}
+			} else if c, err := strconv.Atoi(token); err == nil && c > 0 && c < 1000 {
+				if _, _, nextIsShip := d.matchShip(tokens[i+1:]); nextIsShip {
+					pendingCount = c
+				}
+			}
+		}
+		i++
+	}
+	return report
+}
+
+func (r *Report) addShip(shipType string, count int) {
+	for i := range r.Ships {
+		if r.Ships[i].Type == shipType {
+			r.Ships[i].Count += count
+			return
+		}
+	}
+	r.Ships = append(r.Ships, Ship{Type: shipType, Count: count})
+}
+
+// matchShip finds the longest ship name at the start of tokens, accepting a
+// plural "s" on single-word names. Ships that are also everyday words must
+// be written as listed.
+func (d dictionary) matchShip(tokens []string) (string, int, bool) {
+	if name, n, ok := matchLongest(d.ships, tokens); ok && d.spelledAsListed(name, strings.Join(tokens[:n], " ")) {
+		return name, n, true
+	}
+	if len(tokens) > 0 {
+		lower := strings.ToLower(tokens[0])
+		if len(lower) > 3 && strings.HasSuffix(lower, "s") {
+			if name, ok := d.ships[strings.TrimSuffix(lower, "s")]; ok && d.spelledAsListed(name, strings.TrimSuffix(tokens[0], "s")) {
+				return name, 1, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
+func (d dictionary) spelledAsListed(name string, written string) bool {
+	_, word := d.wordShips[name]
+	return !word || written == name
+}
+
+func (d dictionary) matchSystem(tokens []string) (string, int, bool) {
+	if name, n, ok := matchLongest(d.systems, tokens); ok {
+		return name, n, true
+	}
+	if len(tokens) == 0 {
+		return "", 0, false
+	}
+	// Lower-case procedural names are only trusted when they contain a
+	// digit, so chat such as "x-up" is not taken for a system, and ranges
+	// such as "10-15" need a letter.
+	token := tokens[0]
+	upper := strings.ToUpper(token)
+	if !proceduralSystemPattern.MatchString(upper) || !letterPattern.MatchString(token) ||
+		(token != upper && !digitPattern.MatchString(token)) {
+		return "", 0, false
+	}
+	// Names of the right shape such as "GO-GO" must also be real systems.
+	if _, ok := d.procedural[upper]; ok {
+		return upper, 1, true
+	}
+	return "", 0, false
+}
+
+func matchLongest(names map[string]string, tokens []string) (string, int, bool) {
+	for n := min(maxNameWords, len(tokens)); n > 0; n-- {
+		key := strings.ToLower(strings.Join(tokens[:n], " "))
+		if name, ok := names[key]; ok {
+			return name, n, true
+		}
+	}
+	return "", 0, false
+}
+
+func tokenize(message string) []string {
+	fields := strings.Fields(message)
+	tokens := fields[:0]
+	for _, field := range fields {
+		field = strings.Trim(field, `,.;:!?()[]{}"'*`)
+		if field != "" {
+			tokens = append(tokens, field)
+		}
+	}
+	return tokens
+}
+
+func plusCount(token string) (int, bool) {
+	match := plusCountPattern.FindStringSubmatch(token)
+	if match == nil {
+		return 0, false
+	}
+	count, err := strconv.Atoi(match[1])
+	return count, err == nil && count > 0
+}
+
+func timesCount(token string) (int, bool) {
+	match := timesCountPattern.FindStringSubmatch(strings.ToLower(token))
+	if match == nil {
+		return 0, false
+	}
+	count, err := strconv.Atoi(match[1] + match[2])
+	return count, err == nil && count > 0
+}
+
+func isVisualWord(token string) bool {
+	switch strings.ToLower(token) {
+	case "visual", "vis", "visuals":
+		return true
+	}
+	return false
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
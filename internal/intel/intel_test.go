@@ -0,0 +1,94 @@
+package intel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	dict := newDictionary(shipsData, "Old Man Star\n1DQ1-A\nJ5A-IX\nHED-GP\nJ123456\n")
+	cases := []struct {
+		message string
+		want    Report
+	}{
+		{
+			message: "1DQ1-A  Pilot One  Pilot Two +5 nv",
+			want:    Report{Systems: []string{"1DQ1-A"}, Pilots: 5, NoVisual: true},
+		},
+		{
+			message: "j5a-ix gate camp 10x Sabre, Loki x2",
+			want: Report{
+				Systems: []string{"J5A-IX"},
+				Gates:   []string{"J5A-IX"},
+				Ships:   []Ship{{Type: "Sabre", Count: 10}, {Type: "Loki", Count: 2}},
+			},
+		},
+		{
+			message: "Old Man Star clr",
+			want:    Report{Systems: []string{"Old Man Star"}, Clear: true},
+		},
+		{
+			message: "HED-GP 3x no visual, vni and 2 Vexor Navy Issue",
+			want: Report{
+				Systems:  []string{"HED-GP"},
+				Pilots:   3,
+				NoVisual: true,
+				Ships:    []Ship{{Type: "Vexor Navy Issue", Count: 3}},
+			},
+		},
+		{
+			message: "x-up for 10-15 pods in J123456",
+			want:    Report{Systems: []string{"J123456"}, Ships: []Ship{{Type: "Capsule", Count: 1}}},
+		},
+		{
+			message: "o7",
+			want:    Report{},
+		},
+		{
+			message: "legion of them, probe the sin out of it",
+			want:    Report{},
+		},
+		{
+			message: "Legion on gate, curse you",
+			want:    Report{},
+		},
+		{
+			message: "2 Legion and Probe x3 in 1DQ1-A",
+			want: Report{
+				Systems: []string{"1DQ1-A"},
+				Ships:   []Ship{{Type: "Legion", Count: 2}, {Type: "Probe", Count: 3}},
+			},
+		},
+	}
+	for _, tc := range cases {
+		got := dict.parse(tc.message)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("Parse(%q) = %#v, want %#v", tc.message, got, tc.want)
+		}
+		if got.Empty() != tc.want.Empty() {
+			t.Fatalf("Parse(%q).Empty() = %v", tc.message, got.Empty())
+		}
+	}
+}
+
+func TestMatchSystem_ChecksProceduralNamesAgainstTheList(t *testing.T) {
+	dict := newDictionary("", "Jita\n1DQ1-A\nHED-GP\nJ123456\n")
+	for _, tc := range []struct {
+		token string
+		want  string
+	}{
+		{token: "jita", want: "Jita"},
+		{token: "1dq1-a", want: "1DQ1-A"},
+		{token: "HED-GP", want: "HED-GP"},
+		{token: "J123456", want: "J123456"},
+		{token: "hed-gp"},
+		{token: "GO-GO"},
+		{token: "X-UP"},
+		{token: "J654321"},
+	} {
+		got, _, ok := dict.matchSystem([]string{tc.token})
+		if got != tc.want || ok != (tc.want != "") {
+			t.Fatalf("matchSystem(%q) = %q, %v; want %q", tc.token, got, ok, tc.want)
+		}
+	}
+}
@@ -80,15 +80,7 @@ func (s *fileSink) WriteEvent(event Event) error {
 	if s == nil {
 		return nil
 	}
-	entry := jsonLogLine{
-		Time:    event.Time.UTC().Format(time.RFC3339Nano),
-		Level:   strings.ToUpper(event.Level.String()),
-		Message: event.Message,
-	}
-	if len(event.Fields) > 0 {
-		entry.Fields = normalizeLogFields(event.Fields)
-	}
-	payload, err := json.Marshal(entry)
+	payload, err := FormatEventJSON(event)
 	if err != nil {
 		return err
 	}
@@ -141,6 +133,20 @@ func (s *fileSink) rotateLocked() error {
 	return nil
 }
 
+// FormatEventJSON encodes event as one JSON log line, without the trailing
+// newline, in the format of the persisted log files.
+func FormatEventJSON(event Event) ([]byte, error) {
+	entry := jsonLogLine{
+		Time:    event.Time.UTC().Format(time.RFC3339Nano),
+		Level:   strings.ToUpper(event.Level.String()),
+		Message: event.Message,
+	}
+	if len(event.Fields) > 0 {
+		entry.Fields = normalizeLogFields(event.Fields)
+	}
+	return json.Marshal(entry)
+}
+
 func normalizeLogFields(fields map[string]any) map[string]any {
 	out := make(map[string]any, len(fields))
 	for key, value := range fields {
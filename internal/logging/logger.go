@@ -63,6 +63,13 @@ func (l *Logger) SetDebugEnabled(enabled bool) {
 	l.debugEnabled.Store(enabled)
 }
 
+func (l *Logger) DebugEnabled() bool {
+	if l == nil {
+		return false
+	}
+	return l.debugEnabled.Load()
+}
+
 func (l *Logger) SetTerminalOutputEnabled(enabled bool) {
 	if l == nil {
 		return
@@ -0,0 +1,578 @@
+// Package mockserver is a fake Sentinel backend for development and
+// integration tests. It serves the uploader API from an httptest server:
+// realtime token, SSE stream with PB_CONNECT and subscribe, channel config,
+// submit, heartbeat, session refresh and diagnostics. Faults can be queued
+// per endpoint to script 401s, dropped connections and slow responses.
+package mockserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/logging"
+	"sentinel2-uploader/internal/pbrealtime"
+)
+
+// Endpoint names one route of the mock API for fault injection.
+type Endpoint string
+
+const (
+	EndpointRealtimeToken  Endpoint = "POST /api/uploader/realtime/token"
+	EndpointStream         Endpoint = "GET /api/realtime"
+	EndpointSubscribe      Endpoint = "POST /api/realtime"
+	EndpointConfig         Endpoint = "GET /api/uploader/config"
+	EndpointSubmit         Endpoint = "PUT /api/uploader/submit"
+	EndpointSubmitBatch    Endpoint = "PUT /api/uploader/submit/batch"
+	EndpointHeartbeat      Endpoint = "POST /api/uploader/heartbeat"
+	EndpointSessionRefresh Endpoint = "POST /api/uploader/session/refresh"
+	EndpointDiagnostics    Endpoint = "POST /api/uploader/diagnostics"
+)
+
+const (
+	configTopic    = pbrealtime.DefaultTopic
+	keepaliveTopic = "realtime.keepalive"
+	commandTopic   = "uploader.command"
+
+	defaultSessionTTL = 10 * time.Minute
+)
+
+// Fault replaces the normal handling of one request. Faults queued for an
+// endpoint are used in order, one per request.
+type Fault struct {
+	// Delay holds the request before it is answered or failed.
+	Delay time.Duration
+	// Status answers with this HTTP status instead of handling the request.
+	Status int
+	// RetryAfter is sent as the Retry-After header of a Status answer, in
+	// whole seconds.
+	RetryAfter time.Duration
+	// EOF drops the connection without a response. On the event stream
+	// PB_CONNECT is sent first, so the client sees the stream end.
+	EOF bool
+}
+
+type Options struct {
+	// UploaderToken is the long-lived token the token endpoint accepts.
+	// Empty accepts any token.
+	UploaderToken string
+	Channels      []client.ChannelConfig
+	// SessionTTL is how long issued session tokens live. Zero means 10m.
+	SessionTTL time.Duration
+	// KeepaliveInterval, when set, sends keepalive events on open streams
+	// and advertises the interval in issued sessions.
+	KeepaliveInterval time.Duration
+	// RetryDelay, when set, is advised to clients as the SSE retry field.
+	RetryDelay time.Duration
+	// Capabilities are advertised in the channel config when set.
+	Capabilities []string
+	Logger       *logging.Logger
+}
+
+type Server struct {
+	// URL is the server base URL, suitable for config.BuildEndpoints.
+	URL string
+
+	opts Options
+	http *httptest.Server
+
+	mu          sync.Mutex
+	faults      map[Endpoint][]Fault
+	channels    []client.ChannelConfig
+	sessions    map[string]struct{}
+	nextSession int
+	nextClient  int
+	nextEvent   int
+	streams     map[*streamConn]struct{}
+	submissions []client.SubmitPayload
+	submitted   chan struct{}
+	submitKeys  map[string]struct{}
+	heartbeats  int
+	diagnostics []json.RawMessage
+}
+
+type streamConn struct {
+	clientID string
+	topics   []string
+	events   chan sseEvent
+	done     chan struct{}
+}
+
+type sseEvent struct {
+	id   int
+	name string
+	data []byte
+}
+
+// New starts a mock server on a loopback port.
+func New(opts Options) *Server {
+	s := newServer(opts)
+	s.http = httptest.NewServer(s.routes())
+	s.URL = s.http.URL
+	return s
+}
+
+// Listen starts a mock server on addr, for running it outside tests.
+func Listen(addr string, opts Options) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := newServer(opts)
+	s.http = httptest.NewUnstartedServer(s.routes())
+	_ = s.http.Listener.Close()
+	s.http.Listener = listener
+	s.http.Start()
+	s.URL = s.http.URL
+	return s, nil
+}
+
+func newServer(opts Options) *Server {
+	if opts.SessionTTL <= 0 {
+		opts.SessionTTL = defaultSessionTTL
+	}
+	return &Server{
+		opts:       opts,
+		faults:     make(map[Endpoint][]Fault),
+		channels:   slices.Clone(opts.Channels),
+		sessions:   make(map[string]struct{}),
+		streams:    make(map[*streamConn]struct{}),
+		submitted:  make(chan struct{}),
+		submitKeys: make(map[string]struct{}),
+	}
+}
+
+// Close ends open streams and shuts the server down.
+func (s *Server) Close() {
+	s.DropStreams()
+	s.http.Close()
+}
+
+// Fail queues faults for the next requests to endpoint.
+func (s *Server) Fail(endpoint Endpoint, faults ...Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[endpoint] = append(s.faults[endpoint], faults...)
+}
+
+// SetChannels replaces the channel config and pushes it to subscribed
+// streams.
+func (s *Server) SetChannels(channels []client.ChannelConfig) {
+	s.mu.Lock()
+	s.channels = slices.Clone(channels)
+	s.mu.Unlock()
+	s.publish(configTopic, s.configPayload())
+}
+
+// PushCommand sends cmd to streams subscribed to the command topic.
+func (s *Server) PushCommand(cmd client.Command) {
+	data, _ := json.Marshal(cmd)
+	s.publish(commandTopic, data)
+}
+
+// DropStreams ends every open event stream, as if the connection dropped.
+func (s *Server) DropStreams() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.streams {
+		close(conn.done)
+		delete(s.streams, conn)
+	}
+}
+
+// RevokeSessions invalidates every issued session token, so the next call
+// with one is answered 401.
+func (s *Server) RevokeSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.sessions)
+}
+
+// Submissions returns the reports accepted so far, batch submits included.
+// Submits repeating an earlier idempotency key are not recorded again.
+func (s *Server) Submissions() []client.SubmitPayload {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.submissions)
+}
+
+// WaitForSubmissions blocks until at least n reports were accepted.
+func (s *Server) WaitForSubmissions(ctx context.Context, n int) ([]client.SubmitPayload, error) {
+	for {
+		s.mu.Lock()
+		if len(s.submissions) >= n {
+			out := slices.Clone(s.submissions)
+			s.mu.Unlock()
+			return out, nil
+		}
+		wait := s.submitted
+		s.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return s.Submissions(), ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+func (s *Server) Heartbeats() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.heartbeats
+}
+
+// Diagnostics returns the diagnostics bundles uploaded so far.
+func (s *Server) Diagnostics() []json.RawMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.diagnostics)
+}
+
+// Streams reports how many event streams are open.
+func (s *Server) Streams() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.streams)
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(string(EndpointRealtimeToken), s.handle(EndpointRealtimeToken, s.serveToken))
+	mux.HandleFunc(string(EndpointStream), s.handle(EndpointStream, s.serveStream))
+	mux.HandleFunc(string(EndpointSubscribe), s.handle(EndpointSubscribe, s.serveSubscribe))
+	mux.HandleFunc(string(EndpointConfig), s.handle(EndpointConfig, s.authorized(s.serveConfig)))
+	mux.HandleFunc(string(EndpointSubmit), s.handle(EndpointSubmit, s.authorized(s.serveSubmit)))
+	mux.HandleFunc(string(EndpointSubmitBatch), s.handle(EndpointSubmitBatch, s.authorized(s.serveSubmitBatch)))
+	mux.HandleFunc(string(EndpointHeartbeat), s.handle(EndpointHeartbeat, s.authorized(s.serveHeartbeat)))
+	mux.HandleFunc(string(EndpointSessionRefresh), s.handle(EndpointSessionRefresh, s.authorized(s.serveRefresh)))
+	mux.HandleFunc(string(EndpointDiagnostics), s.handle(EndpointDiagnostics, s.authorized(s.serveDiagnostics)))
+	return mux
+}
+
+// handle applies the next queued fault for endpoint before calling next.
+func (s *Server) handle(endpoint Endpoint, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fault, faulted := s.nextFault(endpoint)
+		if s.opts.Logger != nil {
+			s.opts.Logger.Debug("mock server request",
+				logging.Field("endpoint", string(endpoint)),
+				logging.Field("faulted", faulted),
+			)
+		}
+		if !faulted {
+			next(w, r)
+			return
+		}
+		if fault.Delay > 0 {
+			timer := time.NewTimer(fault.Delay)
+			select {
+			case <-r.Context().Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+		switch {
+		case fault.Status != 0:
+			if fault.RetryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(fault.RetryAfter.Seconds())))
+			}
+			http.Error(w, http.StatusText(fault.Status), fault.Status)
+		case fault.EOF && endpoint == EndpointStream:
+			s.openStream(w, nil)
+		case fault.EOF:
+			dropConnection(w)
+		default:
+			next(w, r)
+		}
+	}
+}
+
+func (s *Server) nextFault(endpoint Endpoint) (Fault, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	queued := s.faults[endpoint]
+	if len(queued) == 0 {
+		return Fault{}, false
+	}
+	s.faults[endpoint] = queued[1:]
+	return queued[0], true
+}
+
+func dropConnection(w http.ResponseWriter) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		panic("mockserver: response writer cannot be hijacked")
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	_ = conn.Close()
+}
+
+// authorized rejects requests without a live session token.
+func (s *Server) authorized(next func(http.ResponseWriter, *http.Request, string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		s.mu.Lock()
+		_, ok := s.sessions[token]
+		s.mu.Unlock()
+		if !ok {
+			http.Error(w, "invalid session token", http.StatusUnauthorized)
+			return
+		}
+		next(w, r, token)
+	}
+}
+
+func (s *Server) issueSession() pbrealtime.Session {
+	s.mu.Lock()
+	s.nextSession++
+	token := fmt.Sprintf("mock-session-%d", s.nextSession)
+	s.sessions[token] = struct{}{}
+	s.mu.Unlock()
+	return pbrealtime.Session{
+		Token:               token,
+		Topic:               configTopic,
+		ExpiresAt:           time.Now().Add(s.opts.SessionTTL).Unix(),
+		RefreshAfterSeconds: int64(s.opts.SessionTTL / time.Second),
+		KeepaliveSeconds:    int64(s.opts.KeepaliveInterval / time.Second),
+	}
+}
+
+func (s *Server) serveToken(w http.ResponseWriter, r *http.Request) {
+	if want := s.opts.UploaderToken; want != "" && r.Header.Get("X-Uploader-Token") != want {
+		http.Error(w, "invalid uploader token", http.StatusUnauthorized)
+		return
+	}
+	writeJSON(w, s.issueSession())
+}
+
+func (s *Server) serveRefresh(w http.ResponseWriter, _ *http.Request, token string) {
+	s.mu.Lock()
+	delete(s.sessions, token)
+	s.mu.Unlock()
+	writeJSON(w, s.issueSession())
+}
+
+func (s *Server) configPayload() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	payload := map[string]any{"channels": s.channels}
+	if s.opts.Capabilities != nil {
+		payload["capabilities"] = s.opts.Capabilities
+	}
+	data, _ := json.Marshal(payload)
+	return data
+}
+
+func (s *Server) serveConfig(w http.ResponseWriter, _ *http.Request, _ string) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(s.configPayload())
+}
+
+func (s *Server) serveSubmit(w http.ResponseWriter, r *http.Request, _ string) {
+	var payload client.SubmitPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// Repeats of an accepted report succeed without being recorded again.
+	if s.firstSubmit(r.Header.Get(client.IdempotencyKeyHeader)) {
+		s.recordSubmissions(payload)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) serveSubmitBatch(w http.ResponseWriter, r *http.Request, _ string) {
+	var batch struct {
+		Reports []struct {
+			client.SubmitPayload
+			IdempotencyKey string `json:"idempotency_key"`
+		} `json:"reports"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var accepted []client.SubmitPayload
+	for _, report := range batch.Reports {
+		if s.firstSubmit(report.IdempotencyKey) {
+			accepted = append(accepted, report.SubmitPayload)
+		}
+	}
+	if len(accepted) > 0 {
+		s.recordSubmissions(accepted...)
+	}
+	results := make([]client.SubmitResult, len(batch.Reports))
+	for i := range results {
+		results[i].OK = true
+	}
+	writeJSON(w, map[string]any{"results": results})
+}
+
+// firstSubmit reports whether key, an idempotency key, has not been seen
+// before. Submits without a key are always new.
+func (s *Server) firstSubmit(key string) bool {
+	if key == "" {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, dup := s.submitKeys[key]
+	s.submitKeys[key] = struct{}{}
+	return !dup
+}
+
+func (s *Server) recordSubmissions(payloads ...client.SubmitPayload) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.submissions = append(s.submissions, payloads...)
+	close(s.submitted)
+	s.submitted = make(chan struct{})
+}
+
+func (s *Server) serveHeartbeat(w http.ResponseWriter, _ *http.Request, _ string) {
+	s.mu.Lock()
+	s.heartbeats++
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) serveDiagnostics(w http.ResponseWriter, r *http.Request, _ string) {
+	data, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil || !json.Valid(data) {
+		http.Error(w, "invalid diagnostics bundle", http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.diagnostics = append(s.diagnostics, data)
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) serveSubscribe(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		ClientID      string   `json:"clientId"`
+		Subscriptions []string `json:"subscriptions"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sessions[token]; !ok {
+		http.Error(w, "invalid session token", http.StatusUnauthorized)
+		return
+	}
+	for conn := range s.streams {
+		if conn.clientID == payload.ClientID {
+			conn.topics = slices.Clone(payload.Subscriptions)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	http.Error(w, "unknown client id", http.StatusNotFound)
+}
+
+func (s *Server) serveStream(w http.ResponseWriter, r *http.Request) {
+	conn := &streamConn{
+		events: make(chan sseEvent, 16),
+		done:   make(chan struct{}),
+	}
+	s.openStream(w, conn)
+	if conn.clientID == "" {
+		return
+	}
+	defer func() {
+		s.mu.Lock()
+		delete(s.streams, conn)
+		s.mu.Unlock()
+	}()
+
+	var keepalive <-chan time.Time
+	if s.opts.KeepaliveInterval > 0 {
+		ticker := time.NewTicker(s.opts.KeepaliveInterval)
+		defer ticker.Stop()
+		keepalive = ticker.C
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-conn.done:
+			return
+		case <-keepalive:
+			writeEvent(w, sseEvent{name: keepaliveTopic, data: []byte("{}")})
+		case event := <-conn.events:
+			writeEvent(w, event)
+		}
+	}
+}
+
+// openStream writes the stream headers and PB_CONNECT. A nil conn sends a
+// connect for a client that is never registered, for the EOF fault.
+func (s *Server) openStream(w http.ResponseWriter, conn *streamConn) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if s.opts.RetryDelay > 0 {
+		_, _ = fmt.Fprintf(w, "retry: %d\n\n", s.opts.RetryDelay.Milliseconds())
+	}
+
+	s.mu.Lock()
+	s.nextClient++
+	clientID := fmt.Sprintf("mock-client-%d", s.nextClient)
+	if conn != nil {
+		conn.clientID = clientID
+		s.streams[conn] = struct{}{}
+	}
+	s.mu.Unlock()
+
+	data, _ := json.Marshal(map[string]string{"clientId": clientID})
+	writeEvent(w, sseEvent{name: "PB_CONNECT", data: data})
+}
+
+func (s *Server) publish(topic string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.streams {
+		if !slices.Contains(conn.topics, topic) {
+			continue
+		}
+		s.nextEvent++
+		select {
+		case conn.events <- sseEvent{id: s.nextEvent, name: topic, data: data}:
+		default:
+			// A stalled client misses the event, as it would on a real server.
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event sseEvent) {
+	if event.id > 0 {
+		_, _ = fmt.Fprintf(w, "id: %d\n", event.id)
+	}
+	_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.name, event.data)
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,151 @@
+package mockserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/config"
+	"sentinel2-uploader/internal/logging"
+	"sentinel2-uploader/internal/pbrealtime"
+)
+
+func newTestClient(t *testing.T, server *Server, token string) *client.SentinelClient {
+	t.Helper()
+	endpoints, err := config.BuildEndpoints(server.URL)
+	if err != nil {
+		t.Fatalf("BuildEndpoints() error = %v", err)
+	}
+	logger := logging.New(false)
+	logger.SetTerminalOutputEnabled(false)
+	return client.New(server.http.Client(), token, endpoints, logger)
+}
+
+func TestServer_SessionConfigSubmitAndFaults(t *testing.T) {
+	server := New(Options{
+		UploaderToken: "uploader-token",
+		Channels:      []client.ChannelConfig{{ID: "c1", Name: "Intel"}},
+	})
+	defer server.Close()
+	ctx := context.Background()
+
+	if _, err := newTestClient(t, server, "wrong").FetchRealtimeSession(ctx); !client.IsUnauthorized(err) {
+		t.Fatalf("FetchRealtimeSession(wrong token) error = %v, want unauthorized", err)
+	}
+	c := newTestClient(t, server, "uploader-token")
+	session, err := c.FetchRealtimeSession(ctx)
+	if err != nil {
+		t.Fatalf("FetchRealtimeSession() error = %v", err)
+	}
+	channels, err := c.FetchChannels(ctx, session.Token)
+	if err != nil || len(channels) != 1 || channels[0].Name != "Intel" {
+		t.Fatalf("FetchChannels() = %v, %v", channels, err)
+	}
+
+	// Submits carry an idempotency key, which lets net/http resend them on
+	// a dropped connection, so the EOF fault is exercised on the heartbeat.
+	server.Fail(EndpointSubmit, Fault{Status: 401})
+	payload := client.SubmitPayload{Text: "Pilot > HED-GP clr", ChannelID: "c1"}
+	if err := c.Submit(ctx, payload, session.Token); !client.IsUnauthorized(err) {
+		t.Fatalf("Submit() with 401 fault error = %v, want unauthorized", err)
+	}
+	if err := c.Submit(ctx, payload, session.Token); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if err := c.Submit(ctx, payload, session.Token); err != nil {
+		t.Fatalf("Submit() repeat error = %v", err)
+	}
+	if got := server.Submissions(); len(got) != 1 || got[0].Text != payload.Text {
+		t.Fatalf("Submissions() = %v, want one report", got)
+	}
+	next := client.SubmitPayload{Text: "Pilot > 1DQ1-A +5", ChannelID: "c1"}
+	if results, err := c.SubmitBatch(ctx, []client.SubmitPayload{payload, next}, session.Token); err != nil || len(results) != 2 {
+		t.Fatalf("SubmitBatch() = %v, %v", results, err)
+	}
+	if got := server.Submissions(); len(got) != 2 || got[1].Text != next.Text {
+		t.Fatalf("Submissions() after batch = %v, want the repeat collapsed and one new report", got)
+	}
+
+	refreshed, err := c.RefreshSession(ctx, session.Token)
+	if err != nil {
+		t.Fatalf("RefreshSession() error = %v", err)
+	}
+	if err := c.Heartbeat(ctx, session.Token); !client.IsUnauthorized(err) {
+		t.Fatalf("Heartbeat(old token) error = %v, want unauthorized", err)
+	}
+	server.Fail(EndpointHeartbeat, Fault{EOF: true})
+	if err := c.Heartbeat(ctx, refreshed.Token); err == nil {
+		t.Fatal("Heartbeat() with EOF fault error = nil")
+	}
+	if err := c.Heartbeat(ctx, refreshed.Token); err != nil || server.Heartbeats() != 1 {
+		t.Fatalf("Heartbeat() error = %v, heartbeats = %d", err, server.Heartbeats())
+	}
+
+	server.Fail(EndpointHeartbeat, Fault{Delay: 50 * time.Millisecond})
+	slowCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := c.Heartbeat(slowCtx, refreshed.Token); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Heartbeat() with delay fault error = %v, want deadline exceeded", err)
+	}
+
+	server.Fail(EndpointConfig, Fault{Status: 429, RetryAfter: 7 * time.Second})
+	if _, err := c.FetchChannels(ctx, refreshed.Token); err == nil {
+		t.Fatal("FetchChannels() with 429 fault error = nil")
+	} else if delay, limited := client.RetryAfter(err); !limited || delay != 7*time.Second {
+		t.Fatalf("RetryAfter(%v) = %s, %t; want 7s, true", err, delay, limited)
+	}
+}
+
+func TestServer_RealtimePushAndStreamFaults(t *testing.T) {
+	server := New(Options{
+		Channels:   []client.ChannelConfig{{ID: "c1", Name: "Intel"}},
+		RetryDelay: 10 * time.Millisecond,
+	})
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The first stream ends right after PB_CONNECT; sync must reconnect.
+	server.Fail(EndpointStream, Fault{EOF: true})
+	c := newTestClient(t, server, "any")
+	session, err := c.FetchRealtimeSession(ctx)
+	if err != nil {
+		t.Fatalf("FetchRealtimeSession() error = %v", err)
+	}
+	connected := make(chan struct{}, 4)
+	commands := make(chan client.Command, 1)
+	initial := []client.ChannelConfig{{ID: "c1", Name: "Intel"}}
+	updates := c.StartChannelConfigSync(ctx, initial, client.SyncHooks{
+		OnConnected: func(string, pbrealtime.Session, uint64) { connected <- struct{}{} },
+		OnCommand:   func(cmd client.Command) { commands <- cmd },
+	}, &session)
+
+	select {
+	case <-connected:
+	case <-time.After(15 * time.Second):
+		t.Fatal("realtime stream never connected")
+	}
+
+	next := []client.ChannelConfig{{ID: "c1", Name: "Intel"}, {ID: "c2", Name: "Staging"}}
+	server.SetChannels(next)
+	select {
+	case got := <-updates:
+		if len(got) != 2 {
+			t.Fatalf("pushed channels = %v, want 2", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("channel push not received")
+	}
+
+	server.PushCommand(client.Command{ID: "cmd-1", Name: config.RemoteCommandMessage})
+	select {
+	case cmd := <-commands:
+		if cmd.ID != "cmd-1" || cmd.Name != config.RemoteCommandMessage {
+			t.Fatalf("command = %+v", cmd)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("command push not received")
+	}
+}
@@ -0,0 +1,294 @@
+package outbox
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultMaxAge = 10 * time.Minute
+
+	opAdd = "add"
+	opAck = "ack"
+)
+
+var ErrClosed = errors.New("outbox journal closed")
+
+// ErrRejected, returned by a Drain submit func (possibly wrapped), means the
+// server refused the report for good: it is acknowledged and dropped, and
+// draining continues.
+var ErrRejected = errors.New("outbox report rejected")
+
+type Entry struct {
+	Seq         uint64 `json:"seq"`
+	ChannelID   string `json:"channel_id"`
+	Text        string `json:"text"`
+	CharacterID string `json:"character_id,omitempty"`
+	// ReporterSystem is the reporting character's system, when known.
+	ReporterSystem string    `json:"reporter_system,omitempty"`
+	ReportTime     time.Time `json:"report_time"`
+	QueuedAt       time.Time `json:"queued_at"`
+}
+
+type DrainResult struct {
+	Delivered int
+	Expired   int
+	Rejected  int
+	Remaining int
+}
+
+type record struct {
+	Op    string `json:"op"`
+	Seq   uint64 `json:"seq,omitempty"`
+	Entry *Entry `json:"entry,omitempty"`
+}
+
+// Journal is an append-only on-disk queue of reports that could not be
+// submitted. Every queued report is written as an "add" record and every
+// delivered or expired report as an "ack" record; the file is compacted on
+// open and whenever the queue becomes empty.
+type Journal struct {
+	mu      sync.Mutex
+	path    string
+	maxAge  time.Duration
+	file    *os.File
+	pending []Entry
+	nextSeq uint64
+	closed  bool
+}
+
+func DefaultPath() (string, error) {
+	root, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, "sentinel2", "uploader", "outbox.jsonl"), nil
+}
+
+func Open(path string, maxAge time.Duration) (*Journal, error) {
+	if maxAge <= 0 {
+		maxAge = DefaultMaxAge
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	pending, err := replay(path)
+	if err != nil {
+		return nil, err
+	}
+	j := &Journal{path: path, maxAge: maxAge, pending: pending}
+	for _, entry := range pending {
+		if entry.Seq >= j.nextSeq {
+			j.nextSeq = entry.Seq
+		}
+	}
+	if err := j.rewriteLocked(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func replay(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	pending := []Entry{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var rec record
+		// A torn trailing write from a crash decodes as garbage; skip it.
+		if json.Unmarshal(scanner.Bytes(), &rec) != nil {
+			continue
+		}
+		switch rec.Op {
+		case opAdd:
+			if rec.Entry != nil {
+				pending = append(pending, *rec.Entry)
+			}
+		case opAck:
+			pending = removeSeq(pending, rec.Seq)
+		}
+	}
+	return pending, scanner.Err()
+}
+
+func removeSeq(entries []Entry, seq uint64) []Entry {
+	for i, entry := range entries {
+		if entry.Seq == seq {
+			return append(entries[:i], entries[i+1:]...)
+		}
+	}
+	return entries
+}
+
+func (j *Journal) Path() string {
+	return j.path
+}
+
+func (j *Journal) Len() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.pending)
+}
+
+func (j *Journal) Append(entry Entry) (Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.closed {
+		return Entry{}, ErrClosed
+	}
+	j.nextSeq++
+	entry.Seq = j.nextSeq
+	if entry.QueuedAt.IsZero() {
+		entry.QueuedAt = time.Now().UTC()
+	}
+	if err := j.writeLocked(record{Op: opAdd, Entry: &entry}); err != nil {
+		return Entry{}, err
+	}
+	j.pending = append(j.pending, entry)
+	return entry, nil
+}
+
+// Drain submits queued reports oldest first and stops at the first submit
+// error so later reports are never delivered ahead of earlier ones. Reports
+// older than the journal max age are discarded without being submitted, and
+// reports whose submit fails with ErrRejected are discarded after it. Only
+// one Drain call should run at a time.
+func (j *Journal) Drain(now time.Time, submit func(Entry) error) (DrainResult, error) {
+	result := DrainResult{}
+	for {
+		entry, ok := j.head()
+		if !ok {
+			return result, nil
+		}
+		if j.expired(entry, now) {
+			if err := j.ack(entry.Seq); err != nil {
+				result.Remaining = j.Len()
+				return result, err
+			}
+			result.Expired++
+			continue
+		}
+		submitErr := submit(entry)
+		if submitErr != nil && !errors.Is(submitErr, ErrRejected) {
+			result.Remaining = j.Len()
+			return result, submitErr
+		}
+		if err := j.ack(entry.Seq); err != nil {
+			result.Remaining = j.Len()
+			return result, err
+		}
+		if submitErr != nil {
+			result.Rejected++
+			continue
+		}
+		result.Delivered++
+	}
+}
+
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.closed = true
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+func (j *Journal) head() (Entry, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.closed || len(j.pending) == 0 {
+		return Entry{}, false
+	}
+	return j.pending[0], true
+}
+
+func (j *Journal) expired(entry Entry, now time.Time) bool {
+	at := entry.ReportTime
+	if at.IsZero() {
+		at = entry.QueuedAt
+	}
+	return now.Sub(at) > j.maxAge
+}
+
+func (j *Journal) ack(seq uint64) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.closed {
+		return ErrClosed
+	}
+	j.pending = removeSeq(j.pending, seq)
+	if len(j.pending) == 0 {
+		return j.rewriteLocked()
+	}
+	return j.writeLocked(record{Op: opAck, Seq: seq})
+}
+
+func (j *Journal) writeLocked(rec record) error {
+	if j.file == nil {
+		return ErrClosed
+	}
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err := j.file.Write(append(payload, '\n')); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+// rewriteLocked replaces the journal file with add records for the reports
+// still pending, dropping acknowledged history.
+func (j *Journal) rewriteLocked() error {
+	if j.file != nil {
+		_ = j.file.Close()
+		j.file = nil
+	}
+	tmp := j.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(f)
+	for i := range j.pending {
+		payload, marshalErr := json.Marshal(record{Op: opAdd, Entry: &j.pending[i]})
+		if marshalErr != nil {
+			_ = f.Close()
+			return marshalErr
+		}
+		_, _ = writer.Write(append(payload, '\n'))
+	}
+	if err := writer.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(j.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	j.file = file
+	return nil
+}
@@ -0,0 +1,137 @@
+package outbox
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJournal_ReopenReplaysPendingInOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	now := time.Now().UTC()
+
+	j, err := Open(path, time.Hour)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	for _, text := range []string{"first", "second", "third"} {
+		if _, err := j.Append(Entry{ChannelID: "intel", Text: text, ReportTime: now}); err != nil {
+			t.Fatalf("Append(%q) error = %v", text, err)
+		}
+	}
+	delivered := []string{}
+	_, err = j.Drain(now, func(entry Entry) error {
+		if entry.Text == "second" {
+			return errors.New("server unavailable")
+		}
+		delivered = append(delivered, entry.Text)
+		return nil
+	})
+	if err == nil {
+		t.Fatalf("Drain() error = nil, want submit error")
+	}
+	if err := j.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	reopened, err := Open(path, time.Hour)
+	if err != nil {
+		t.Fatalf("Open() after close error = %v", err)
+	}
+	defer reopened.Close()
+	if got := reopened.Len(); got != 2 {
+		t.Fatalf("pending after reopen = %d, want 2", got)
+	}
+	result, err := reopened.Drain(now, func(entry Entry) error {
+		delivered = append(delivered, entry.Text)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Drain() after reopen error = %v", err)
+	}
+	if result.Delivered != 2 || result.Remaining != 0 {
+		t.Fatalf("Drain() result = %#v", result)
+	}
+	want := []string{"first", "second", "third"}
+	if len(delivered) != len(want) {
+		t.Fatalf("delivered = %v, want %v", delivered, want)
+	}
+	for i := range want {
+		if delivered[i] != want[i] {
+			t.Fatalf("delivered = %v, want %v", delivered, want)
+		}
+	}
+
+	if _, err := reopened.Append(Entry{ChannelID: "intel", Text: "fourth", ReportTime: now}); err != nil {
+		t.Fatalf("Append() after drain error = %v", err)
+	}
+	if got := reopened.Len(); got != 1 {
+		t.Fatalf("pending after append = %d, want 1", got)
+	}
+}
+
+func TestJournal_DrainDiscardsExpiredReports(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	now := time.Now().UTC()
+
+	j, err := Open(path, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer j.Close()
+	if _, err := j.Append(Entry{ChannelID: "intel", Text: "stale", ReportTime: now.Add(-time.Hour)}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if _, err := j.Append(Entry{ChannelID: "intel", Text: "fresh", ReportTime: now.Add(-time.Minute)}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	submitted := []string{}
+	result, err := j.Drain(now, func(entry Entry) error {
+		submitted = append(submitted, entry.Text)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	if result.Expired != 1 || result.Delivered != 1 {
+		t.Fatalf("Drain() result = %#v, want 1 expired and 1 delivered", result)
+	}
+	if len(submitted) != 1 || submitted[0] != "fresh" {
+		t.Fatalf("submitted = %v, want [fresh]", submitted)
+	}
+}
+
+func TestJournal_DrainSkipsRejectedReports(t *testing.T) {
+	j, err := Open(filepath.Join(t.TempDir(), "outbox.jsonl"), time.Hour)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer j.Close()
+	now := time.Now().UTC()
+	for _, text := range []string{"bad", "good"} {
+		if _, err := j.Append(Entry{ChannelID: "intel", Text: text, ReportTime: now}); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	submitted := []string{}
+	result, err := j.Drain(now, func(entry Entry) error {
+		submitted = append(submitted, entry.Text)
+		if entry.Text == "bad" {
+			return fmt.Errorf("%w: 422 Unprocessable Entity", ErrRejected)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	if result.Rejected != 1 || result.Delivered != 1 || j.Len() != 0 {
+		t.Fatalf("Drain() result = %#v, pending %d; want 1 rejected, 1 delivered, none pending", result, j.Len())
+	}
+	if len(submitted) != 2 || submitted[1] != "good" {
+		t.Fatalf("submitted = %v, want [bad good]", submitted)
+	}
+}
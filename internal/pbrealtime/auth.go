@@ -49,7 +49,7 @@ func (a AuthClient) FetchSession(ctx context.Context) (Session, error) {
 				logging.Field("response", body),
 			)
 		}
-		return Session{}, &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+		return Session{}, statusError(resp)
 	}
 
 	session := Session{}
@@ -114,7 +114,7 @@ func (a AuthClient) Subscribe(ctx context.Context, clientID string, sessionToken
 				logging.Field("response", body),
 			)
 		}
-		return &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+		return statusError(resp)
 	}
 
 	if a.Logger != nil {
@@ -144,11 +144,53 @@ func TestAuthClient_FetchSession_UnauthorizedTypedError(t *testing.T) {
 	}
 }
 
+func TestAuthClient_FetchSession_RateLimitedTypedError(t *testing.T) {
+	httpClient := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			header := make(http.Header)
+			header.Set("Retry-After", "30")
+			return &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Status:     "429 Too Many Requests",
+				Header:     header,
+				Body:       io.NopCloser(strings.NewReader("")),
+				Request:    r,
+			}, nil
+		}),
+	}
+
+	a := AuthClient{HTTP: httpClient, RealtimeTokenURL: "https://example.test/token"}
+	_, err := a.FetchSession(context.Background())
+	if delay, limited := RetryAfter(err); !limited || delay != 30*time.Second {
+		t.Fatalf("RetryAfter(%v) = %s, %t; want 30s, true", err, delay, limited)
+	}
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("FetchSession() error = %v, want a 429 status error", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 2, 14, 12, 0, 0, 0, time.UTC)
+	for value, want := range map[string]time.Duration{
+		"":                              0,
+		"90":                            90 * time.Second,
+		"-5":                            0,
+		"soon":                          0,
+		"Sat, 14 Feb 2026 12:00:45 GMT": 45 * time.Second,
+		"Sat, 14 Feb 2026 11:59:00 GMT": 0,
+	} {
+		if got := ParseRetryAfter(value, now); got != want {
+			t.Errorf("ParseRetryAfter(%q) = %s, want %s", value, got, want)
+		}
+	}
+}
+
 func TestReadSSEEvents_ParsesMultilineData(t *testing.T) {
 	in := strings.NewReader("event: test\ndata: line1\ndata: line2\n\n")
 	out := make(chan Event, 2)
 	errs := make(chan error, 1)
-	readSSEEvents(in, out, errs)
+	readSSEEvents(in, nil, out, errs)
 
 	ev := <-out
 	if ev.Name != "test" || string(ev.Data) != "line1\nline2" {
@@ -159,6 +201,73 @@ func TestReadSSEEvents_ParsesMultilineData(t *testing.T) {
 	}
 }
 
+func TestReadSSEEvents_IDRetryAndComments(t *testing.T) {
+	in := strings.NewReader("\ufeff: keepalive\r\nretry: 2500\r\nid: 7\revent: a\ndata\n\n" +
+		"event: skipped\n\n" +
+		"data: b\nid: 8\n\n" +
+		"retry: soon\nid\ndata: c\n\n" +
+		"data: partial")
+	cursor := &StreamCursor{}
+	out := make(chan Event, 4)
+	errs := make(chan error, 1)
+	readSSEEvents(in, cursor, out, errs)
+
+	var got []Event
+	for ev := range out {
+		got = append(got, ev)
+	}
+	want := []Event{
+		{Name: "a", ID: "7", Data: []byte{}},
+		{Name: "message", ID: "8", Data: []byte("b")},
+		{Name: "message", ID: "", Data: []byte("c")},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("events = %#v, want %#v", got, want)
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name || got[i].ID != want[i].ID || string(got[i].Data) != string(want[i].Data) {
+			t.Fatalf("event %d = %#v, want %#v", i, got[i], want[i])
+		}
+	}
+	if delay := cursor.RetryDelay(); delay != 2500*time.Millisecond {
+		t.Fatalf("RetryDelay() = %v, want 2.5s", delay)
+	}
+	if err := <-errs; err != io.EOF {
+		t.Fatalf("stream err = %v, want io.EOF", err)
+	}
+}
+
+func TestStreamClient_RunSession_ResumesWithLastEventID(t *testing.T) {
+	var lastEventIDs []string
+	httpClient := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+			body := "id: 41\nevent: PB_CONNECT\ndata: {\"clientId\":\"cid\"}\n\nid: 42\nevent: uploader.config\ndata: {}\n\n"
+			h := make(http.Header)
+			h.Set("Content-Type", "text/event-stream")
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Status:     "200 OK",
+				Header:     h,
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Request:    r,
+			}, nil
+		}),
+	}
+	cursor := &StreamCursor{}
+	stream := StreamClient{HTTP: httpClient, RealtimeURL: "https://example.test/realtime", Cursor: cursor}
+	subscribe := func(context.Context, string, string, []string) error { return nil }
+	for range 2 {
+		err := stream.RunSession(context.Background(), Session{Token: "tok", Topic: "uploader.config", RefreshAfterSeconds: 3600}, subscribe, SessionHandlers{})
+		if err != io.EOF {
+			t.Fatalf("RunSession() err = %v, want io.EOF", err)
+		}
+	}
+	if len(lastEventIDs) != 2 || lastEventIDs[0] != "" || lastEventIDs[1] != "42" {
+		t.Fatalf("Last-Event-ID headers = %q, want [\"\" \"42\"]", lastEventIDs)
+	}
+}
+
 func TestStreamClient_RunSession_ConnectMessageAndUnhandled(t *testing.T) {
 	httpClient := &http.Client{
 		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
@@ -251,3 +360,66 @@ func TestBuildSubscribeTopics_DedupesAndSkipsEmpty(t *testing.T) {
 		t.Fatalf("topics = %v", got)
 	}
 }
+
+func TestStreamClient_RunSession_IdleWatchdog(t *testing.T) {
+	httpClient := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			pr, pw := io.Pipe()
+			go func() {
+				_, _ = io.WriteString(pw, "event: PB_CONNECT\ndata: {\"clientId\":\"cid\"}\n\n")
+				for range 4 {
+					time.Sleep(20 * time.Millisecond)
+					_, _ = io.WriteString(pw, "event: realtime.keepalive\ndata: {}\n\n")
+				}
+				// Go silent without closing, like a half-open connection.
+				<-r.Context().Done()
+				pw.CloseWithError(r.Context().Err())
+			}()
+			h := make(http.Header)
+			h.Set("Content-Type", "text/event-stream")
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Status:     "200 OK",
+				Header:     h,
+				Body:       pr,
+				Request:    r,
+			}, nil
+		}),
+	}
+	stream := StreamClient{
+		HTTP:              httpClient,
+		RealtimeURL:       "https://example.test/realtime",
+		KeepaliveInterval: 20 * time.Millisecond,
+		IdleKeepalives:    3,
+	}
+	subscribe := func(context.Context, string, string, []string) error { return nil }
+	startedAt := time.Now()
+	err := stream.RunSession(context.Background(), Session{Token: "tok", Topic: "uploader.config", RefreshAfterSeconds: 3600}, subscribe, SessionHandlers{})
+	var idleErr *IdleError
+	if !errors.As(err, &idleErr) {
+		t.Fatalf("RunSession() err = %v, want *IdleError", err)
+	}
+	if idleErr.Idle != 60*time.Millisecond {
+		t.Fatalf("idle = %s, want 60ms", idleErr.Idle)
+	}
+	if !IsIdle(err) {
+		t.Fatal("IsIdle() = false, want true")
+	}
+	// Keepalives reset the watchdog, so the session outlives the idle limit.
+	if elapsed := time.Since(startedAt); elapsed < 120*time.Millisecond {
+		t.Fatalf("session ended after %s, want keepalives to hold it open", elapsed)
+	}
+}
+
+func TestStreamClient_IdleTimeout(t *testing.T) {
+	stream := StreamClient{KeepaliveInterval: 30 * time.Second, IdleKeepalives: 3}
+	if got := stream.idleTimeout(Session{}); got != 90*time.Second {
+		t.Fatalf("idleTimeout() = %s, want 90s", got)
+	}
+	if got := stream.idleTimeout(Session{KeepaliveSeconds: 10}); got != 30*time.Second {
+		t.Fatalf("idleTimeout() with server interval = %s, want 30s", got)
+	}
+	if got := (StreamClient{KeepaliveInterval: time.Second}).idleTimeout(Session{}); got != 0 {
+		t.Fatalf("idleTimeout() without IdleKeepalives = %s, want 0", got)
+	}
+}
@@ -3,6 +3,10 @@ package pbrealtime
 import (
 	"errors"
 	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type HTTPStatusError struct {
@@ -27,3 +31,86 @@ func IsUnauthorized(err error) bool {
 	}
 	return statusErr.StatusCode == 401 || statusErr.StatusCode == 403
 }
+
+// RateLimitError is a 429 response, or a request held back because its
+// endpoint is paused after one. RetryAfter is the delay the server asked
+// for in Retry-After, or zero when it gave none. It unwraps to the
+// HTTPStatusError of the response.
+type RateLimitError struct {
+	Status     string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e == nil {
+		return "rate limited"
+	}
+	status := e.Status
+	if status == "" {
+		status = "rate limited"
+	}
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s (retry after %s)", status, e.RetryAfter)
+	}
+	return status
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return &HTTPStatusError{StatusCode: http.StatusTooManyRequests, Status: e.Status}
+}
+
+// RetryAfter reports whether err is a 429 and returns the delay the server
+// advised.
+func RetryAfter(err error) (time.Duration, bool) {
+	var limitErr *RateLimitError
+	if !errors.As(err, &limitErr) {
+		return 0, false
+	}
+	return limitErr.RetryAfter, true
+}
+
+// ParseRetryAfter reads a Retry-After value, given either as seconds or as
+// an HTTP date. Missing, malformed and past values give zero.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0)
+	}
+	return 0
+}
+
+// statusError is the error for a failed response.
+func statusError(resp *http.Response) error {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return &RateLimitError{
+			Status:     resp.Status,
+			RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+	return &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+}
+
+// IdleError ends a session whose stream carried no events, keepalives
+// included, for longer than the idle limit. The connection is presumed
+// half-open and should be re-established.
+type IdleError struct {
+	Idle time.Duration
+}
+
+func (e *IdleError) Error() string {
+	if e == nil {
+		return "realtime stream idle"
+	}
+	return fmt.Sprintf("realtime stream idle for %s", e.Idle)
+}
+
+func IsIdle(err error) bool {
+	var idleErr *IdleError
+	return errors.As(err, &idleErr)
+}
@@ -3,53 +3,185 @@ package pbrealtime
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"io"
+	"net/http"
+	"strconv"
 	"strings"
+	"time"
+
+	"sentinel2-uploader/internal/logging"
 )
 
-func readSSEEvents(reader io.Reader, out chan<- Event, errs chan<- error) {
+// SSETransport reads the realtime stream as server-sent events over a
+// long-lived GET.
+type SSETransport struct {
+	HTTP   *http.Client
+	URL    string
+	Logger *logging.Logger
+}
+
+func (t SSETransport) Name() string {
+	return "sse"
+}
+
+func (t SSETransport) Open(ctx context.Context, cursor *StreamCursor) (*Stream, error) {
+	req, reqErr := http.NewRequestWithContext(ctx, "GET", t.URL, nil)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	if id := cursor.LastEventID(); id != "" {
+		req.Header.Set("Last-Event-ID", id)
+	}
+
+	// SSE is a long-lived stream; disable whole-request timeout so the body can
+	// stay open until server disconnect/reconnect boundaries.
+	streamHTTP := streamHTTPClient(t.HTTP)
+	resp, respErr := streamHTTP.Do(req)
+	if respErr != nil {
+		return nil, respErr
+	}
+	if t.Logger != nil {
+		t.Logger.Debug("realtime stream connected",
+			logging.Field("transport", t.Name()),
+			logging.Field("status", resp.Status),
+			logging.Field("proto", resp.Proto),
+			logging.Field("response_headers", streamDiagnosticHeaders(resp.Header)),
+		)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, connectFailed(t.Logger, resp)
+	}
+
+	events := make(chan Event, 16)
+	streamErrs := make(chan error, 1)
+	go readSSEEvents(resp.Body, cursor, events, streamErrs)
+	return &Stream{Events: events, Errs: streamErrs, close: resp.Body.Close}, nil
+}
+
+func streamHTTPClient(httpClient *http.Client) *http.Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	streamHTTP := *httpClient
+	streamHTTP.Timeout = 0
+	return &streamHTTP
+}
+
+// connectFailed logs a rejected stream request and returns its status error.
+func connectFailed(logger *logging.Logger, resp *http.Response) error {
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+	body := logging.FormatHTTPPayload(data)
+	if logger != nil {
+		logger.Warn("realtime connect failed",
+			logging.Field("status", resp.Status),
+			logging.Field("proto", resp.Proto),
+			logging.Field("response_headers", streamDiagnosticHeaders(resp.Header)),
+			logging.Field("response", body),
+		)
+	}
+	return statusError(resp)
+}
+
+// readSSEEvents parses an event stream as specified for EventSource:
+// comments are skipped, "id" sets the last event ID carried on every later
+// event, "retry" updates the cursor's reconnect delay, and an event is only
+// dispatched at a blank line when it carried data. A partial event at the
+// end of the stream is discarded.
+func readSSEEvents(reader io.Reader, cursor *StreamCursor, out chan<- Event, errs chan<- error) {
 	defer close(out)
 
 	scanner := bufio.NewScanner(reader)
 	buf := make([]byte, 0, 64*1024)
 	scanner.Buffer(buf, 4*1024*1024)
+	scanner.Split(scanSSELines)
 
 	name := ""
+	lastID := cursor.LastEventID()
+	hasData := false
 	var data bytes.Buffer
-	emit := func() {
-		if name == "" && data.Len() == 0 {
-			return
+	dispatch := func() {
+		if hasData {
+			eventName := strings.TrimSpace(name)
+			if eventName == "" {
+				eventName = "message"
+			}
+			out <- Event{Name: eventName, ID: lastID, Data: append([]byte{}, data.Bytes()...)}
 		}
-		out <- Event{Name: strings.TrimSpace(name), Data: append([]byte{}, data.Bytes()...)}
 		name = ""
+		hasData = false
 		data.Reset()
 	}
 
+	first := true
 	for scanner.Scan() {
 		line := scanner.Text()
+		if first {
+			line = strings.TrimPrefix(line, "\ufeff")
+			first = false
+		}
 		if line == "" {
-			emit()
+			dispatch()
 			continue
 		}
-		if rest, ok := strings.CutPrefix(line, "event:"); ok {
-			name = strings.TrimSpace(rest)
+		if strings.HasPrefix(line, ":") {
 			continue
 		}
-		if segment, ok := strings.CutPrefix(line, "data:"); ok {
-			if len(segment) > 0 && segment[0] == ' ' {
-				segment = segment[1:]
-			}
-			if data.Len() > 0 {
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			name = value
+		case "data":
+			if hasData {
 				data.WriteByte('\n')
 			}
-			data.WriteString(segment)
+			data.WriteString(value)
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				lastID = value
+			}
+		case "retry":
+			if millis, err := strconv.ParseUint(value, 10, 32); err == nil {
+				cursor.setRetryDelay(time.Duration(millis) * time.Millisecond)
+			}
 		}
 	}
 
-	emit()
 	if scanErr := scanner.Err(); scanErr != nil {
 		errs <- scanErr
 		return
 	}
 	errs <- io.EOF
 }
+
+// scanSSELines splits on CRLF, LF or a lone CR.
+func scanSSELines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		// A trailing CR may be the first half of CRLF.
+		return 0, nil, nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
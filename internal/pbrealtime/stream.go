@@ -25,9 +25,21 @@ type SessionHandlers struct {
 type StreamClient struct {
 	HTTP        *http.Client
 	RealtimeURL string
+	// Transport carries the event stream. Nil means SSE over HTTP and
+	// RealtimeURL.
+	Transport Transport
+	// Cursor, when set, resumes from the last event ID seen in an earlier
+	// session and collects the server's advised reconnect delay.
+	Cursor      *StreamCursor
 	RefreshLead time.Duration
-	ExtraTopics []string
-	Logger      *logging.Logger
+	// KeepaliveInterval is how often the server is expected to send a
+	// keepalive. With IdleKeepalives > 0, a session that sees no event for
+	// IdleKeepalives intervals ends with an *IdleError. Zero disables the
+	// watchdog.
+	KeepaliveInterval time.Duration
+	IdleKeepalives    int
+	ExtraTopics       []string
+	Logger            *logging.Logger
 }
 
 var ErrSessionRefreshDue = errors.New("realtime session refresh due")
@@ -48,63 +60,71 @@ func (s StreamClient) RunSession(ctx context.Context, session Session, subscribe
 	if refreshAfter <= 0 {
 		refreshAfter = time.Minute
 	}
+	idleAfter := s.idleTimeout(session)
+	transport := s.transport()
 	if s.Logger != nil {
 		s.Logger.Debug("starting realtime stream session",
 			logging.Field("topic", session.Topic),
+			logging.Field("transport", transport.Name()),
 			logging.Field("refresh_after", refreshAfter.String()),
+			logging.Field("idle_after", idleAfter.String()),
 		)
 	}
 
-	req, reqErr := http.NewRequestWithContext(ctx, "GET", s.RealtimeURL, nil)
-	if reqErr != nil {
-		return reqErr
+	startedAt := time.Now()
+	runErr := s.runStream(ctx, transport, session, refreshAfter, idleAfter, subscribe, handlers)
+	if observer, ok := transport.(SessionObserver); ok {
+		observer.SessionEnded(runErr, time.Since(startedAt))
 	}
-	req.Header.Set("Accept", "text/event-stream")
+	return runErr
+}
 
-	httpClient := s.HTTP
-	if httpClient == nil {
-		httpClient = http.DefaultClient
+// idleTimeout returns how long the session may go without events, or zero
+// when the watchdog is off.
+func (s StreamClient) idleTimeout(session Session) time.Duration {
+	if s.IdleKeepalives <= 0 {
+		return 0
 	}
-	// SSE is a long-lived stream; disable whole-request timeout so the body can
-	// stay open until server disconnect/reconnect boundaries.
-	streamHTTP := *httpClient
-	streamHTTP.Timeout = 0
-
-	resp, respErr := streamHTTP.Do(req)
-	if respErr != nil {
-		return respErr
+	interval := s.KeepaliveInterval
+	if session.KeepaliveSeconds > 0 {
+		interval = time.Duration(session.KeepaliveSeconds) * time.Second
 	}
-	connectedAt := time.Now()
-	if s.Logger != nil {
-		s.Logger.Debug("realtime stream connected",
-			logging.Field("status", resp.Status),
-			logging.Field("proto", resp.Proto),
-			logging.Field("response_headers", streamDiagnosticHeaders(resp.Header)),
-		)
+	if interval <= 0 {
+		return 0
 	}
-	if resp.StatusCode >= 400 {
-		defer resp.Body.Close()
-		data, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
-		body := logging.FormatHTTPPayload(data)
-		if s.Logger != nil {
-			s.Logger.Warn("realtime connect failed",
-				logging.Field("status", resp.Status),
-				logging.Field("proto", resp.Proto),
-				logging.Field("response_headers", streamDiagnosticHeaders(resp.Header)),
-				logging.Field("response", body),
-			)
-		}
-		return &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	return interval * time.Duration(s.IdleKeepalives)
+}
+
+// transport returns the configured transport, defaulting to SSE.
+func (s StreamClient) transport() Transport {
+	if s.Transport != nil {
+		return s.Transport
 	}
-	defer resp.Body.Close()
+	return SSETransport{HTTP: s.HTTP, URL: s.RealtimeURL, Logger: s.Logger}
+}
 
-	events := make(chan Event, 16)
-	streamErrs := make(chan error, 1)
-	go readSSEEvents(resp.Body, events, streamErrs)
+func (s StreamClient) runStream(ctx context.Context, transport Transport, session Session, refreshAfter time.Duration, idleAfter time.Duration, subscribe SubscribeFunc, handlers SessionHandlers) error {
+	stream, openErr := transport.Open(ctx, s.Cursor)
+	if openErr != nil {
+		return openErr
+	}
+	defer stream.Close()
+	connectedAt := time.Now()
 
 	refreshTimer := time.NewTimer(refreshAfter)
 	defer refreshTimer.Stop()
 
+	// idleC stays nil, and never fires, when the watchdog is off.
+	var idleTimer *time.Timer
+	var idleC <-chan time.Time
+	if idleAfter > 0 {
+		idleTimer = time.NewTimer(idleAfter)
+		defer idleTimer.Stop()
+		idleC = idleTimer.C
+	}
+
+	streamErrs := stream.Errs
+	var endErr error
 	var currentClientID string
 	var eventCount int
 	var pbConnectEvents int
@@ -115,6 +135,7 @@ func (s StreamClient) RunSession(ctx context.Context, session Session, subscribe
 			return
 		}
 		s.Logger.Debug("realtime stream ended",
+			logging.Field("transport", transport.Name()),
 			logging.Field("error", err),
 			logging.Field("duration", time.Since(connectedAt).String()),
 			logging.Field("events_total", eventCount),
@@ -135,15 +156,34 @@ func (s StreamClient) RunSession(ctx context.Context, session Session, subscribe
 				s.Logger.Debug("realtime stream refresh boundary reached")
 			}
 			return ErrSessionRefreshDue
+		case <-idleC:
+			idleErr := &IdleError{Idle: idleAfter}
+			if s.Logger != nil {
+				s.Logger.Debug("realtime stream idle; tearing down session", logging.Field("idle", idleAfter.String()))
+			}
+			logEnd(idleErr)
+			return idleErr
 		case streamErr := <-streamErrs:
-			logEnd(streamErr)
-			return streamErr
-		case event, ok := <-events:
+			// Handle events still buffered before the error; the reader
+			// closes Events once it has sent the error.
+			endErr = streamErr
+			streamErrs = nil
+		case event, ok := <-stream.Events:
 			if !ok {
-				logEnd(io.EOF)
-				return io.EOF
+				if endErr == nil {
+					endErr = io.EOF
+				}
+				logEnd(endErr)
+				return endErr
 			}
 			eventCount++
+			if idleTimer != nil {
+				idleTimer.Reset(idleAfter)
+			}
+			// Advance as events are taken off the channel rather than as they
+			// are parsed, so a resume replays anything still buffered when the
+			// session ended.
+			s.Cursor.setLastEventID(event.ID)
 
 			switch event.Name {
 			case "PB_CONNECT":
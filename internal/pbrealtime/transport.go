@@ -0,0 +1,171 @@
+package pbrealtime
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"sentinel2-uploader/internal/logging"
+)
+
+const (
+	// autoSwitchThreshold is how many short-lived sessions in a row make
+	// AutoTransport try the other transport.
+	autoSwitchThreshold = 3
+	// healthySessionDuration is how long a session must stay up to count as
+	// working rather than as connection churn.
+	healthySessionDuration = time.Minute
+)
+
+// Transport opens the realtime event stream. Implementations only move
+// events; PB_CONNECT, subscriptions and dispatch stay in StreamClient.
+type Transport interface {
+	Name() string
+	Open(ctx context.Context, cursor *StreamCursor) (*Stream, error)
+}
+
+// StreamCursor carries reconnect state from one session to the next: the
+// last event ID to resume from and the reconnect delay the server advised.
+// A nil cursor is valid and remembers nothing.
+type StreamCursor struct {
+	mu          sync.Mutex
+	lastEventID string
+	retryDelay  time.Duration
+}
+
+func (c *StreamCursor) LastEventID() string {
+	if c == nil {
+		return ""
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastEventID
+}
+
+// RetryDelay returns the server-advised reconnect delay, or zero.
+func (c *StreamCursor) RetryDelay() time.Duration {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.retryDelay
+}
+
+func (c *StreamCursor) setLastEventID(id string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.lastEventID = id
+	c.mu.Unlock()
+}
+
+func (c *StreamCursor) setRetryDelay(delay time.Duration) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.retryDelay = delay
+	c.mu.Unlock()
+}
+
+// SessionObserver is implemented by transports that adapt to how sessions
+// end. RunSession calls it once per session with the error it returns.
+type SessionObserver interface {
+	SessionEnded(err error, lasted time.Duration)
+}
+
+// Stream is an open realtime connection. Events is closed when the
+// connection ends and the cause is then sent on Errs.
+type Stream struct {
+	Events <-chan Event
+	Errs   <-chan error
+	close  func() error
+}
+
+func (s *Stream) Close() error {
+	if s == nil || s.close == nil {
+		return nil
+	}
+	return s.close()
+}
+
+// AutoTransport starts on SSE and switches to WebSocket after repeated
+// short-lived SSE sessions, such as those caused by proxies that buffer or
+// cut event streams. It switches back the same way if WebSocket fares no
+// better.
+type AutoTransport struct {
+	SSE       Transport
+	WebSocket Transport
+	// WebSocketSupported reports whether the server offers the WebSocket
+	// stream. Without it returning true AutoTransport stays on SSE.
+	WebSocketSupported func() bool
+	Logger             *logging.Logger
+
+	mu           sync.Mutex
+	useWebSocket bool
+	failures     int
+}
+
+func (a *AutoTransport) Name() string {
+	return a.current().Name()
+}
+
+func (a *AutoTransport) Open(ctx context.Context, cursor *StreamCursor) (*Stream, error) {
+	return a.current().Open(ctx, cursor)
+}
+
+func (a *AutoTransport) current() Transport {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.useWebSocket {
+		return a.WebSocket
+	}
+	return a.SSE
+}
+
+func (a *AutoTransport) SessionEnded(err error, lasted time.Duration) {
+	if err == nil || errors.Is(err, ErrSessionRefreshDue) || errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded) || IsUnauthorized(err) {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if lasted >= healthySessionDuration {
+		a.failures = 0
+		return
+	}
+	a.failures++
+	if a.failures < autoSwitchThreshold {
+		return
+	}
+	if !a.useWebSocket && (a.WebSocketSupported == nil || !a.WebSocketSupported()) {
+		a.failures = 0
+		if a.Logger != nil {
+			a.Logger.Debug("realtime transport keeps failing; server does not offer websocket",
+				logging.Field("transport", a.SSE.Name()),
+				logging.Field("last_error", err),
+			)
+		}
+		return
+	}
+	from := a.SSE
+	if a.useWebSocket {
+		from = a.WebSocket
+	}
+	a.useWebSocket = !a.useWebSocket
+	a.failures = 0
+	to := a.SSE
+	if a.useWebSocket {
+		to = a.WebSocket
+	}
+	if a.Logger != nil {
+		a.Logger.Warn("realtime transport keeps failing; switching",
+			logging.Field("from", from.Name()),
+			logging.Field("to", to.Name()),
+			logging.Field("last_error", err),
+		)
+	}
+}
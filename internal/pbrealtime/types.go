@@ -5,10 +5,15 @@ type Session struct {
 	Topic               string `json:"topic"`
 	ExpiresAt           int64  `json:"expires_at"`
 	RefreshAfterSeconds int64  `json:"refresh_after_seconds"`
+	// KeepaliveSeconds, when the server sets it, overrides
+	// StreamClient.KeepaliveInterval for this session.
+	KeepaliveSeconds int64 `json:"keepalive_seconds,omitempty"`
 }
 
 type Event struct {
 	Name string
+	// ID is the last event ID the server set at or before this event.
+	ID   string
 	Data []byte
 }
 
@@ -0,0 +1,308 @@
+package pbrealtime
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"sentinel2-uploader/internal/logging"
+)
+
+const (
+	websocketGUID       = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	maxWebSocketMessage = 4 * 1024 * 1024
+
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xA
+)
+
+// WebSocketTransport reads the realtime stream over a WebSocket. Each text
+// message is a JSON object {"event": "<name>", "id": "<optional event id>",
+// "data": <payload>}, with ids resumed through Last-Event-ID as for SSE. URL uses
+// the http or https scheme; the upgrade goes through HTTP so proxy settings
+// of the client apply. It is always sent over HTTP/1.1, as an HTTP/2
+// connection negotiated through TLS ALPN cannot be upgraded.
+type WebSocketTransport struct {
+	HTTP   *http.Client
+	URL    string
+	Logger *logging.Logger
+}
+
+type websocketMessage struct {
+	Event string          `json:"event"`
+	ID    *string         `json:"id,omitempty"`
+	Data  json.RawMessage `json:"data"`
+}
+
+func (t WebSocketTransport) Name() string {
+	return "websocket"
+}
+
+func (t WebSocketTransport) Open(ctx context.Context, cursor *StreamCursor) (*Stream, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	req, reqErr := http.NewRequestWithContext(ctx, "GET", t.URL, nil)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	if id := cursor.LastEventID(); id != "" {
+		req.Header.Set("Last-Event-ID", id)
+	}
+
+	resp, respErr := websocketHTTPClient(t.HTTP).Do(req)
+	if respErr != nil {
+		return nil, respErr
+	}
+	if t.Logger != nil {
+		t.Logger.Debug("realtime stream connected",
+			logging.Field("transport", t.Name()),
+			logging.Field("status", resp.Status),
+			logging.Field("proto", resp.Proto),
+			logging.Field("response_headers", streamDiagnosticHeaders(resp.Header)),
+		)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return nil, connectFailed(t.Logger, resp)
+	}
+	conn, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		resp.Body.Close()
+		return nil, errors.New("websocket upgrade returned a read-only body")
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != websocketAccept(key) {
+		conn.Close()
+		return nil, errors.New("websocket upgrade returned an invalid accept key")
+	}
+
+	ws := newWebSocketConn(conn, cursor.LastEventID())
+	events := make(chan Event, 16)
+	streamErrs := make(chan error, 1)
+	go ws.readEvents(events, streamErrs)
+	return &Stream{Events: events, Errs: streamErrs, close: ws.close}, nil
+}
+
+// RoundTripperWrapper is implemented by RoundTrippers layered over another,
+// such as a rate limiter. WithNext returns the same layer over next, so the
+// WebSocket upgrade can swap in an HTTP/1.1-only transport underneath.
+type RoundTripperWrapper interface {
+	http.RoundTripper
+	Next() http.RoundTripper
+	WithNext(next http.RoundTripper) http.RoundTripper
+}
+
+func websocketHTTPClient(httpClient *http.Client) *http.Client {
+	wsHTTP := streamHTTPClient(httpClient)
+	wsHTTP.Transport = http1Only(wsHTTP.Transport)
+	return wsHTTP
+}
+
+// http1Only returns rt with its *http.Transport cloned to never negotiate
+// HTTP/2. Other transports are returned unchanged.
+func http1Only(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	switch base := rt.(type) {
+	case *http.Transport:
+		h1 := base.Clone()
+		h1.ForceAttemptHTTP2 = false
+		h1.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		return h1
+	case RoundTripperWrapper:
+		return base.WithNext(http1Only(base.Next()))
+	default:
+		return rt
+	}
+}
+
+func websocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+type websocketConn struct {
+	conn   io.ReadWriteCloser
+	reader *bufio.Reader
+	lastID string
+	// done is closed by close, so the reader stops once nobody reads the
+	// stream any more.
+	done chan struct{}
+
+	writeMu   sync.Mutex
+	closeOnce sync.Once
+}
+
+func newWebSocketConn(conn io.ReadWriteCloser, lastID string) *websocketConn {
+	return &websocketConn{conn: conn, reader: bufio.NewReader(conn), lastID: lastID, done: make(chan struct{})}
+}
+
+func (c *websocketConn) close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+		_ = c.writeFrame(wsOpClose, binary.BigEndian.AppendUint16(nil, 1000))
+		err = c.conn.Close()
+	})
+	return err
+}
+
+// readEvents decodes messages until the connection ends or is closed. A
+// close frame from the server ends the stream with io.EOF, like the end of
+// an SSE body.
+func (c *websocketConn) readEvents(out chan<- Event, errs chan<- error) {
+	defer close(out)
+	fail := func(err error) {
+		select {
+		case errs <- err:
+		case <-c.done:
+		}
+	}
+	for {
+		payload, err := c.readMessage()
+		if err != nil {
+			fail(err)
+			return
+		}
+		msg := websocketMessage{}
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			fail(fmt.Errorf("invalid websocket message: %w", err))
+			return
+		}
+		if msg.ID != nil {
+			c.lastID = *msg.ID
+		}
+		select {
+		case out <- Event{Name: strings.TrimSpace(msg.Event), ID: c.lastID, Data: []byte(msg.Data)}:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// readMessage returns the next complete data message, answering pings and
+// reassembling fragments on the way.
+func (c *websocketConn) readMessage() ([]byte, error) {
+	var message []byte
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case wsOpPing:
+			if err := c.writeFrame(wsOpPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case wsOpPong:
+			continue
+		case wsOpClose:
+			_ = c.close()
+			return nil, io.EOF
+		case wsOpText, wsOpBinary, wsOpContinuation:
+		default:
+			return nil, fmt.Errorf("unexpected websocket opcode %#x", opcode)
+		}
+		if len(message)+len(payload) > maxWebSocketMessage {
+			return nil, errors.New("websocket message too large")
+		}
+		message = append(message, payload...)
+		if fin {
+			return message, nil
+		}
+	}
+}
+
+func (c *websocketConn) readFrame() (bool, byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin := header[0]&0x80 != 0
+	opcode := header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.reader, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.reader, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > maxWebSocketMessage {
+		return false, 0, nil, errors.New("websocket frame too large")
+	}
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+// writeFrame sends a single masked frame, as required for clients.
+func (c *websocketConn) writeFrame(opcode byte, payload []byte) error {
+	frame := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, 0x80|byte(n))
+	case n <= 0xFFFF:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, 0x80|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	var mask [4]byte
+	if _, err := rand.Read(mask[:]); err != nil {
+		return err
+	}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err := c.conn.Write(frame)
+	return err
+}
@@ -0,0 +1,245 @@
+package pbrealtime
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// writeServerFrame writes an unmasked frame as a server would.
+func writeServerFrame(w io.Writer, opcode byte, payload string) error {
+	frame := []byte{0x80 | opcode, byte(len(payload))}
+	_, err := w.Write(append(frame, payload...))
+	return err
+}
+
+func TestWebSocketTransport_RunSession(t *testing.T) {
+	pong := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "websocket" {
+			http.Error(w, "upgrade required", http.StatusUpgradeRequired)
+			return
+		}
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("hijack: %v", err)
+			return
+		}
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: " + websocketAccept(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+		_ = writeServerFrame(rw, wsOpText, `{"event":"PB_CONNECT","data":{"clientId":"cid-1"}}`)
+		_ = writeServerFrame(rw, wsOpPing, "hi")
+		_ = rw.Flush()
+
+		client := &websocketConn{conn: conn, reader: bufio.NewReader(rw)}
+		_, opcode, payload, err := client.readFrame()
+		if err != nil || opcode != wsOpPong {
+			t.Errorf("read pong: opcode=%#x err=%v", opcode, err)
+			return
+		}
+		pong <- payload
+
+		// A fragmented config message followed by a close frame.
+		first, rest := `{"event":"upl`, `oader.config","data":{"channels":[1]}}`
+		_, _ = rw.Write(append([]byte{wsOpText, byte(len(first))}, first...))
+		_ = writeServerFrame(rw, wsOpContinuation, rest)
+		_ = writeServerFrame(rw, wsOpClose, string(binary.BigEndian.AppendUint16(nil, 1000)))
+		_ = rw.Flush()
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	var messages []string
+	stream := StreamClient{
+		Transport: WebSocketTransport{HTTP: server.Client(), URL: server.URL + "/realtime/ws"},
+	}
+	err := stream.RunSession(context.Background(), Session{Token: "tok", RefreshAfterSeconds: 3600},
+		func(_ context.Context, clientID string, _ string, _ []string) error {
+			if clientID != "cid-1" {
+				t.Fatalf("clientID = %q", clientID)
+			}
+			return nil
+		},
+		SessionHandlers{OnMessage: func(ev Event) { messages = append(messages, string(ev.Data)) }},
+	)
+	if err != io.EOF {
+		t.Fatalf("RunSession() err = %v, want io.EOF on close frame", err)
+	}
+	if got := string(<-pong); got != "hi" {
+		t.Fatalf("pong payload = %q, want hi", got)
+	}
+	if len(messages) != 1 || messages[0] != `{"channels":[1]}` {
+		t.Fatalf("messages = %q", messages)
+	}
+}
+
+func TestWebSocketConn_ReaderStopsOnceClosed(t *testing.T) {
+	serverEnd, clientEnd := net.Pipe()
+	defer serverEnd.Close()
+	go func() { _, _ = io.Copy(io.Discard, serverEnd) }()
+	go func() {
+		for {
+			if err := writeServerFrame(serverEnd, wsOpText, `{"event":"tick","data":{}}`); err != nil {
+				return
+			}
+		}
+	}()
+
+	ws := newWebSocketConn(clientEnd, "")
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		// Nobody reads the events, as after a reconnect.
+		ws.readEvents(make(chan Event, 1), make(chan error, 1))
+	}()
+	time.Sleep(20 * time.Millisecond)
+	_ = ws.close()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("readEvents() still blocked after close()")
+	}
+}
+
+func TestWebSocketTransport_RejectedUpgradeIsStatusError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	_, err := WebSocketTransport{HTTP: server.Client(), URL: server.URL}.Open(context.Background(), nil)
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("Open() err = %v, want 404 status error", err)
+	}
+}
+
+// layeredTransport is a RoundTripperWrapper like the client's rate limiter.
+type layeredTransport struct{ next http.RoundTripper }
+
+func (l layeredTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	return l.next.RoundTrip(r)
+}
+
+func (l layeredTransport) Next() http.RoundTripper { return l.next }
+
+func (l layeredTransport) WithNext(next http.RoundTripper) http.RoundTripper {
+	return layeredTransport{next: next}
+}
+
+func TestWebSocketTransport_UpgradesOverHTTP1WhenServerOffersHTTP2(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 1 {
+			http.Error(w, "websocket needs HTTP/1.1", http.StatusHTTPVersionNotSupported)
+			return
+		}
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("hijack: %v", err)
+			return
+		}
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: " + websocketAccept(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+		_ = writeServerFrame(rw, wsOpText, `{"event":"PB_CONNECT","data":{"clientId":"cid-1"}}`)
+		_ = rw.Flush()
+		time.Sleep(20 * time.Millisecond)
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	// Other requests on the same client do negotiate HTTP/2.
+	resp, err := server.Client().Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Fatalf("GET proto = %s, want HTTP/2 from the test server", resp.Proto)
+	}
+
+	wrapped, ok := http1Only(layeredTransport{next: server.Client().Transport}).(layeredTransport)
+	if !ok {
+		t.Fatal("http1Only() dropped the wrapping transport")
+	}
+	if h1, ok := wrapped.next.(*http.Transport); !ok || h1.ForceAttemptHTTP2 || h1.TLSNextProto == nil || len(h1.TLSNextProto) != 0 {
+		t.Fatalf("http1Only() inner transport = %#v, want HTTP/2 disabled", wrapped.next)
+	}
+
+	for name, httpClient := range map[string]*http.Client{
+		"transport": server.Client(),
+		"wrapped":   {Transport: layeredTransport{next: server.Client().Transport}},
+	} {
+		stream, err := WebSocketTransport{HTTP: httpClient, URL: server.URL + "/realtime/ws"}.Open(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("%s: Open() error = %v", name, err)
+		}
+		ev, ok := <-stream.Events
+		if !ok || ev.Name != "PB_CONNECT" {
+			t.Fatalf("%s: first event = %#v, %v; want PB_CONNECT", name, ev, ok)
+		}
+		_ = stream.Close()
+	}
+}
+
+type namedTransport string
+
+func (n namedTransport) Name() string { return string(n) }
+
+func (n namedTransport) Open(context.Context, *StreamCursor) (*Stream, error) {
+	return nil, errors.New("not dialled in tests")
+}
+
+func TestAutoTransport_SwitchesAfterRepeatedShortSessions(t *testing.T) {
+	auto := &AutoTransport{
+		SSE:                namedTransport("sse"),
+		WebSocket:          namedTransport("websocket"),
+		WebSocketSupported: func() bool { return true },
+	}
+
+	for range autoSwitchThreshold - 1 {
+		auto.SessionEnded(io.EOF, time.Second)
+	}
+	auto.SessionEnded(io.EOF, 2*healthySessionDuration)
+	auto.SessionEnded(ErrSessionRefreshDue, time.Second)
+	if got := auto.Name(); got != "sse" {
+		t.Fatalf("Name() = %q after healthy session, want sse", got)
+	}
+
+	for range autoSwitchThreshold {
+		auto.SessionEnded(io.EOF, time.Second)
+	}
+	if got := auto.Name(); got != "websocket" {
+		t.Fatalf("Name() = %q after repeated failures, want websocket", got)
+	}
+}
+
+func TestAutoTransport_StaysOnSSEWithoutWebSocketSupport(t *testing.T) {
+	supported := false
+	auto := &AutoTransport{
+		SSE:                namedTransport("sse"),
+		WebSocket:          namedTransport("websocket"),
+		WebSocketSupported: func() bool { return supported },
+	}
+	for range 2 * autoSwitchThreshold {
+		auto.SessionEnded(io.EOF, time.Second)
+	}
+	if got := auto.Name(); got != "sse" {
+		t.Fatalf("Name() = %q without websocket support, want sse", got)
+	}
+
+	supported = true
+	for range autoSwitchThreshold {
+		auto.SessionEnded(io.EOF, time.Second)
+	}
+	if got := auto.Name(); got != "websocket" {
+		t.Fatalf("Name() = %q once supported, want websocket", got)
+	}
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
+	"sentinel2-uploader/internal/app"
 	"sentinel2-uploader/internal/client"
 	"sentinel2-uploader/internal/config"
 	"sentinel2-uploader/internal/logging"
+	"sentinel2-uploader/internal/runstatus"
+	"sentinel2-uploader/internal/statusserver"
 )
 
 type Controller struct {
@@ -18,11 +22,19 @@ type Controller struct {
 	cancel  context.CancelFunc
 	running bool
 	wg      sync.WaitGroup
+	// service is the most recently started service; it is kept after exit
+	// so the status server can still report its final state.
+	service Service
+	status  *statusserver.Server
+	// statusAddr is the configured address, which may differ from
+	// status.Addr() when it asked for port 0.
+	statusAddr string
 }
 
 type StartHooks struct {
 	OnChannelsUpdate func([]client.ChannelConfig)
 	OnStatus         func(string)
+	OnServerMessage  func(string)
 	OnExit           func(error)
 }
 
@@ -56,6 +68,9 @@ func (c *Controller) Start(opts config.Options, logger *logging.Logger, hooks St
 	if err != nil {
 		return err
 	}
+	if err := c.updateStatusServer(opts.StatusAddr, logger); err != nil {
+		return err
+	}
 
 	parent := c.rootCtx
 	if parent == nil {
@@ -65,6 +80,7 @@ func (c *Controller) Start(opts config.Options, logger *logging.Logger, hooks St
 
 	c.cancel = cancel
 	c.running = true
+	c.service = service
 	c.wg.Go(func() {
 		defer cancel()
 		runErr := service.RunContext(ctx)
@@ -127,3 +143,70 @@ func (c *Controller) IsRunning() bool {
 	defer c.mu.Unlock()
 	return c.running
 }
+
+// Status reports the state of the current or last service.
+func (c *Controller) Status() app.Status {
+	c.mu.Lock()
+	service := c.service
+	c.mu.Unlock()
+	if service == nil {
+		return app.Status{Status: runstatus.Disconnected}
+	}
+	return service.Status()
+}
+
+// Metrics reports the counters of the current or last service.
+func (c *Controller) Metrics() app.Metrics {
+	c.mu.Lock()
+	service := c.service
+	c.mu.Unlock()
+	if service == nil {
+		return app.Metrics{}
+	}
+	return service.Metrics()
+}
+
+// StatusAddr is the address the status server listens on, or empty.
+func (c *Controller) StatusAddr() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.status == nil {
+		return ""
+	}
+	return c.status.Addr()
+}
+
+// updateStatusServer starts, moves or stops the status server to match addr.
+// The server outlives individual runs and closes with the root context.
+// c.mu must be held.
+func (c *Controller) updateStatusServer(addr string, logger *logging.Logger) error {
+	addr = strings.TrimSpace(addr)
+	if c.status != nil && c.statusAddr == addr {
+		return nil
+	}
+	if c.status != nil {
+		_ = c.status.Close()
+		c.status = nil
+		c.statusAddr = ""
+	}
+	if addr == "" {
+		return nil
+	}
+	server, err := statusserver.Listen(addr, c, logger)
+	if err != nil {
+		return err
+	}
+	c.status = server
+	c.statusAddr = addr
+	go func() {
+		<-c.rootCtx.Done()
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.status == server {
+			_ = server.Close()
+			c.status = nil
+			c.statusAddr = ""
+		}
+	}()
+	return nil
+}
@@ -15,6 +15,8 @@ const defaultHTTPTimeout = 10 * time.Second
 
 type Service interface {
 	RunContext(ctx context.Context) error
+	Status() app.Status
+	Metrics() app.Metrics
 }
 
 func NewService(opts config.Options, logger *logging.Logger) (Service, error) {
@@ -28,7 +30,46 @@ func NewServiceWithHooks(opts config.Options, logger *logging.Logger, hooks Star
 	if err := config.ValidateRequired(opts); err != nil {
 		return nil, err
 	}
+	sentinelClient, err := newSentinelClient(opts, logger)
+	if err != nil {
+		return nil, err
+	}
+	return app.New(opts, sentinelClient, logger, app.Callbacks{
+		OnChannelsUpdate: hooks.OnChannelsUpdate,
+		OnStatusChange:   hooks.OnStatus,
+		OnServerMessage:  hooks.OnServerMessage,
+	}), nil
+}
+
+// Replay backfills historical chat logs. Only the server URL and token are
+// required; the logs to read come from replay.Paths.
+func Replay(ctx context.Context, opts config.Options, replay app.ReplayOptions, logger *logging.Logger) (app.ReplayResult, error) {
+	if logger == nil {
+		panic("runtime.Replay: logger must not be nil")
+	}
+	sentinelClient, err := newSentinelClient(opts, logger)
+	if err != nil {
+		return app.ReplayResult{}, err
+	}
+	return app.New(opts, sentinelClient, logger, app.Callbacks{}).Replay(ctx, replay)
+}
 
+// FetchChannels authenticates with opts and returns the server's channels.
+func FetchChannels(ctx context.Context, opts config.Options, logger *logging.Logger) ([]client.ChannelConfig, error) {
+	if logger == nil {
+		panic("runtime.FetchChannels: logger must not be nil")
+	}
+	sentinelClient, err := newSentinelClient(opts, logger)
+	if err != nil {
+		return nil, err
+	}
+	return app.New(opts, sentinelClient, logger, app.Callbacks{}).FetchChannels(ctx)
+}
+
+func newSentinelClient(opts config.Options, logger *logging.Logger) (*client.SentinelClient, error) {
+	if err := config.ValidateServer(opts); err != nil {
+		return nil, err
+	}
 	endpoints, err := config.BuildEndpoints(opts.BaseURL)
 	if err != nil {
 		return nil, err
@@ -38,14 +79,15 @@ func NewServiceWithHooks(opts config.Options, logger *logging.Logger, hooks Star
 		logging.Field("heartbeat_url", endpoints.HeartbeatURL),
 		logging.Field("session_refresh_url", endpoints.SessionRefreshURL),
 		logging.Field("submit_url", endpoints.SubmitURL),
+		logging.Field("submit_batch_url", endpoints.SubmitBatchURL),
+		logging.Field("diagnostics_url", endpoints.DiagnosticsURL),
 		logging.Field("realtime_token_url", endpoints.RealtimeTokenURL),
 		logging.Field("realtime_url", endpoints.RealtimeURL),
+		logging.Field("realtime_websocket_url", endpoints.RealtimeWebSocketURL),
 	)
 
 	httpClient := &http.Client{Timeout: defaultHTTPTimeout}
 	sentinelClient := client.New(httpClient, opts.Token, endpoints, logger)
-	return app.New(opts, sentinelClient, logger, app.Callbacks{
-		OnChannelsUpdate: hooks.OnChannelsUpdate,
-		OnStatusChange:   hooks.OnStatus,
-	}), nil
+	sentinelClient.UseRealtimeTransport(opts.RealtimeTransport)
+	return sentinelClient, nil
 }
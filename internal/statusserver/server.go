@@ -0,0 +1,205 @@
+// Package statusserver serves uploader health over HTTP for monitoring
+// headless installs: /healthz for liveness checks, /status as JSON and
+// /metrics in the Prometheus text format. It only listens on loopback
+// addresses.
+package statusserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"sentinel2-uploader/internal/app"
+	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/config"
+	"sentinel2-uploader/internal/evelogs"
+	"sentinel2-uploader/internal/logging"
+	"sentinel2-uploader/internal/runstatus"
+	"sentinel2-uploader/internal/ui/headless/health"
+)
+
+// Source reports the state of the uploader being served.
+type Source interface {
+	IsRunning() bool
+	Status() app.Status
+	Metrics() app.Metrics
+}
+
+type Server struct {
+	source  Source
+	http    *http.Server
+	addr    string
+	now     func() time.Time
+	headers *evelogs.HeaderCache
+
+	healthMu sync.Mutex
+	health   healthSnapshot
+}
+
+// healthSnapshot is the channel health last computed for /status, reused
+// for health.RefreshRate while the log dirs and channels stay the same.
+type healthSnapshot struct {
+	at       time.Time
+	logDirs  []string
+	channels []client.ChannelConfig
+	rows     []health.Row
+	logErr   string
+}
+
+// Listen validates addr and starts serving source on it.
+func Listen(addr string, source Source, logger *logging.Logger) (*Server, error) {
+	if err := config.ValidateStatusAddr(addr); err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", strings.TrimSpace(addr))
+	if err != nil {
+		return nil, fmt.Errorf("status server: %w", err)
+	}
+	s := &Server{source: source, addr: listener.Addr().String(), now: time.Now, headers: evelogs.NewHeaderCache()}
+	s.http = &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := s.http.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Warn("status server stopped", logging.Field("error", err))
+		}
+	}()
+	logger.Info("status server listening", logging.Field("addr", s.addr))
+	return s, nil
+}
+
+// Addr is the address the server listens on, with the resolved port.
+func (s *Server) Addr() string {
+	return s.addr
+}
+
+// Close stops listening and drops open connections without waiting for
+// in-flight requests.
+func (s *Server) Close() error {
+	return s.http.Close()
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.serveHealthz)
+	mux.HandleFunc("GET /status", s.serveStatus)
+	mux.HandleFunc("GET /metrics", s.serveMetrics)
+	return mux
+}
+
+// serveHealthz answers 200 while the uploader runs with a live realtime
+// connection and 503 otherwise.
+func (s *Server) serveHealthz(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	status := s.source.Status()
+	if !s.source.IsRunning() || runstatus.Key(status.Status) != runstatus.KeyConnected {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = fmt.Fprintln(w, "unavailable")
+		return
+	}
+	_, _ = fmt.Fprintln(w, "ok")
+}
+
+type statusReport struct {
+	Running        bool            `json:"running"`
+	Status         string          `json:"status"`
+	StatusKey      string          `json:"status_key"`
+	RealtimeEpoch  uint64          `json:"realtime_epoch"`
+	LastAPISuccess *time.Time      `json:"last_api_success,omitempty"`
+	LogDirs        []string        `json:"log_dirs"`
+	LogError       string          `json:"log_error,omitempty"`
+	Channels       []channelHealth `json:"channels"`
+}
+
+type channelHealth struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Health string `json:"health"`
+	Detail string `json:"detail"`
+}
+
+func (s *Server) serveStatus(w http.ResponseWriter, _ *http.Request) {
+	status := s.source.Status()
+	report := statusReport{
+		Running:       s.source.IsRunning(),
+		Status:        status.Status,
+		StatusKey:     runstatus.Key(status.Status),
+		RealtimeEpoch: status.RealtimeEpoch,
+		LogDirs:       status.LogDirs,
+		Channels:      []channelHealth{},
+	}
+	if report.LogDirs == nil {
+		report.LogDirs = []string{}
+	}
+	if !status.LastAPISuccess.IsZero() {
+		last := status.LastAPISuccess.UTC()
+		report.LastAPISuccess = &last
+	}
+	rows, logErr := s.channelHealth(status)
+	report.LogError = logErr
+	for i, row := range rows {
+		report.Channels = append(report.Channels, channelHealth{
+			ID:     status.Channels[i].ID,
+			Name:   row.Name,
+			Health: row.Kind.String(),
+			Detail: row.Reason,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(report)
+}
+
+// channelHealth scans the log dirs at most once per health.RefreshRate, so
+// clients polling /status do not each walk the chat log folders. Scans run
+// one at a time, so one never prunes headers another has just read.
+func (s *Server) channelHealth(status app.Status) ([]health.Row, string) {
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+	now := s.now()
+	cached := s.health
+	if !cached.at.IsZero() && now.Sub(cached.at) < health.RefreshRate &&
+		slices.Equal(cached.logDirs, status.LogDirs) && slices.Equal(cached.channels, status.Channels) {
+		return cached.rows, cached.logErr
+	}
+	rows, logErr := health.Compute(status.LogDirs, status.Channels, s.headers, now)
+	s.health = healthSnapshot{
+		at:       now,
+		logDirs:  slices.Clone(status.LogDirs),
+		channels: slices.Clone(status.Channels),
+		rows:     rows,
+		logErr:   logErr,
+	}
+	return rows, logErr
+}
+
+func (s *Server) serveMetrics(w http.ResponseWriter, _ *http.Request) {
+	metrics := s.source.Metrics()
+	up := 0
+	if s.source.IsRunning() && runstatus.Key(s.source.Status().Status) == runstatus.KeyConnected {
+		up = 1
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	var b strings.Builder
+	writeMetric(&b, "sentinel_uploader_connected", "gauge", "Whether the uploader has a live realtime connection.")
+	fmt.Fprintf(&b, "sentinel_uploader_connected %d\n", up)
+	writeMetric(&b, "sentinel_uploader_submits_total", "counter", "Reports submitted, by result.")
+	fmt.Fprintf(&b, "sentinel_uploader_submits_total{result=\"ok\"} %d\n", metrics.SubmitsOK)
+	fmt.Fprintf(&b, "sentinel_uploader_submits_total{result=\"failed\"} %d\n", metrics.SubmitsFailed)
+	writeMetric(&b, "sentinel_uploader_reconnects_total", "counter", "Realtime connections after the first one.")
+	fmt.Fprintf(&b, "sentinel_uploader_reconnects_total %d\n", metrics.Reconnects)
+	writeMetric(&b, "sentinel_uploader_lines_parsed_total", "counter", "Chat log lines parsed.")
+	fmt.Fprintf(&b, "sentinel_uploader_lines_parsed_total %d\n", metrics.LinesParsed)
+	writeMetric(&b, "sentinel_uploader_dedup_hits_total", "counter", "Reports skipped as duplicates seen by another character.")
+	fmt.Fprintf(&b, "sentinel_uploader_dedup_hits_total %d\n", metrics.DedupHits)
+	_, _ = w.Write([]byte(b.String()))
+}
+
+func writeMetric(b *strings.Builder, name, kind, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
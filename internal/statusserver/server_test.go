@@ -0,0 +1,178 @@
+package statusserver
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sentinel2-uploader/internal/app"
+	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/logging"
+	"sentinel2-uploader/internal/runstatus"
+	"sentinel2-uploader/internal/ui/headless/health"
+)
+
+type fakeSource struct {
+	running bool
+	status  app.Status
+	metrics app.Metrics
+}
+
+func (f *fakeSource) IsRunning() bool      { return f.running }
+func (f *fakeSource) Status() app.Status   { return f.status }
+func (f *fakeSource) Metrics() app.Metrics { return f.metrics }
+
+func get(t *testing.T, handler http.Handler, path string) (int, string) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	body, _ := io.ReadAll(recorder.Result().Body)
+	return recorder.Code, string(body)
+}
+
+func TestHealthz_FollowsRealtimeConnection(t *testing.T) {
+	source := &fakeSource{running: true, status: app.Status{Status: runstatus.Connected}}
+	server := &Server{source: source, now: time.Now}
+
+	if code, _ := get(t, server.Handler(), "/healthz"); code != http.StatusOK {
+		t.Fatalf("/healthz while connected = %d, want 200", code)
+	}
+	source.status.Status = runstatus.Reconnecting
+	if code, _ := get(t, server.Handler(), "/healthz"); code != http.StatusServiceUnavailable {
+		t.Fatalf("/healthz while reconnecting = %d, want 503", code)
+	}
+	source.status.Status = runstatus.Connected
+	source.running = false
+	if code, _ := get(t, server.Handler(), "/healthz"); code != http.StatusServiceUnavailable {
+		t.Fatalf("/healthz when stopped = %d, want 503", code)
+	}
+}
+
+func TestStatus_ReportsChannelHealth(t *testing.T) {
+	logDir := t.TempDir()
+	now := time.Date(2026, 2, 14, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(logDir, "Intel_20260214_100000_90000001.txt")
+	if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write log: %v", err)
+	}
+	if err := os.Chtimes(path, now.Add(-time.Minute), now.Add(-time.Minute)); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	lastSuccess := now.Add(-30 * time.Second)
+	source := &fakeSource{running: true, status: app.Status{
+		Status:         runstatus.Connected,
+		RealtimeEpoch:  3,
+		LastAPISuccess: lastSuccess,
+		LogDirs:        []string{logDir},
+		Channels: []client.ChannelConfig{
+			{ID: "intel", Name: "Intel"},
+			{ID: "corp", Name: "Corp"},
+		},
+	}}
+	server := &Server{source: source, now: func() time.Time { return now }}
+
+	code, body := get(t, server.Handler(), "/status")
+	if code != http.StatusOK {
+		t.Fatalf("/status = %d", code)
+	}
+	var report statusReport
+	if err := json.Unmarshal([]byte(body), &report); err != nil {
+		t.Fatalf("decode %s: %v", body, err)
+	}
+	if report.StatusKey != runstatus.KeyConnected || report.RealtimeEpoch != 3 || !report.Running {
+		t.Fatalf("report = %+v", report)
+	}
+	if report.LastAPISuccess == nil || !report.LastAPISuccess.Equal(lastSuccess) {
+		t.Fatalf("last_api_success = %v, want %s", report.LastAPISuccess, lastSuccess)
+	}
+	want := []channelHealth{
+		{ID: "intel", Name: "Intel", Health: "active"},
+		{ID: "corp", Name: "Corp", Health: "missing"},
+	}
+	if len(report.Channels) != len(want) {
+		t.Fatalf("channels = %+v", report.Channels)
+	}
+	for i, channel := range report.Channels {
+		if channel.ID != want[i].ID || channel.Name != want[i].Name || channel.Health != want[i].Health {
+			t.Fatalf("channels[%d] = %+v, want %+v", i, channel, want[i])
+		}
+	}
+
+	// The scan is reused until health.RefreshRate has passed.
+	corpPath := filepath.Join(logDir, "Corp_20260214_100000_90000001.txt")
+	if err := os.WriteFile(corpPath, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write log: %v", err)
+	}
+	if err := os.Chtimes(corpPath, now, now); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	corpHealth := func() string {
+		t.Helper()
+		_, body := get(t, server.Handler(), "/status")
+		var report statusReport
+		if err := json.Unmarshal([]byte(body), &report); err != nil || len(report.Channels) != 2 {
+			t.Fatalf("decode %s: %v", body, err)
+		}
+		return report.Channels[1].Health
+	}
+	if got := corpHealth(); got != "missing" {
+		t.Fatalf("corp health before refresh = %q, want the cached missing", got)
+	}
+	now = now.Add(health.RefreshRate)
+	if got := corpHealth(); got != "active" {
+		t.Fatalf("corp health after refresh = %q, want active", got)
+	}
+}
+
+func TestMetrics_WritesPrometheusText(t *testing.T) {
+	source := &fakeSource{
+		running: true,
+		status:  app.Status{Status: runstatus.Connected},
+		metrics: app.Metrics{SubmitsOK: 7, SubmitsFailed: 2, Reconnects: 1, LinesParsed: 40, DedupHits: 3},
+	}
+	server := &Server{source: source, now: time.Now}
+
+	code, body := get(t, server.Handler(), "/metrics")
+	if code != http.StatusOK {
+		t.Fatalf("/metrics = %d", code)
+	}
+	for _, line := range []string{
+		"sentinel_uploader_connected 1",
+		`sentinel_uploader_submits_total{result="ok"} 7`,
+		`sentinel_uploader_submits_total{result="failed"} 2`,
+		"sentinel_uploader_reconnects_total 1",
+		"sentinel_uploader_lines_parsed_total 40",
+		"sentinel_uploader_dedup_hits_total 3",
+		"# TYPE sentinel_uploader_submits_total counter",
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Fatalf("metrics missing %q:\n%s", line, body)
+		}
+	}
+}
+
+func TestListen_RejectsNonLoopbackAndServes(t *testing.T) {
+	logger := logging.New(false)
+	if _, err := Listen("0.0.0.0:0", &fakeSource{}, logger); err == nil {
+		t.Fatal("Listen(0.0.0.0:0) error = nil, want error")
+	}
+	server, err := Listen("127.0.0.1:0", &fakeSource{}, logger)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer server.Close()
+	resp, err := http.Get("http://" + server.Addr() + "/healthz")
+	if err != nil {
+		t.Fatalf("GET /healthz: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("/healthz = %d, want 503", resp.StatusCode)
+	}
+}
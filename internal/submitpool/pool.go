@@ -0,0 +1,259 @@
+package submitpool
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"sentinel2-uploader/internal/evelogs"
+	"sentinel2-uploader/internal/logging"
+)
+
+const (
+	defaultQueueSize      = 128
+	defaultCoalesceWindow = 250 * time.Millisecond
+	defaultMaxBatchSize   = 50
+)
+
+var (
+	ErrQueueFull = errors.New("submit queue full")
+	ErrStopped   = errors.New("submit pool stopped")
+)
+
+// SubmitFunc delivers one coalesced batch of reports for a single channel.
+// It owns error handling; the pool never retries.
+type SubmitFunc func(ctx context.Context, channelID string, batch []evelogs.ReportEvent)
+
+type Options struct {
+	QueueSize      int
+	CoalesceWindow time.Duration
+	MaxBatchSize   int
+}
+
+// Pool runs one submit worker per channel, each fed by a bounded queue, so a
+// slow server never blocks the log monitor. Enqueue fails fast when a
+// channel's queue is full and callers decide where the overflow goes.
+type Pool struct {
+	opts   Options
+	submit SubmitFunc
+	logger *logging.Logger
+
+	mu      sync.Mutex
+	ctx     context.Context
+	queues  map[string]*channelQueue
+	workers sync.WaitGroup
+}
+
+type channelQueue struct {
+	channelID string
+	items     chan evelogs.ReportEvent
+
+	maxDepth     atomic.Int64
+	enqueued     atomic.Uint64
+	overflowed   atomic.Uint64
+	submitted    atomic.Uint64
+	batches      atomic.Uint64
+	inFlight     atomic.Bool
+	lastDuration atomic.Int64
+	overflowing  atomic.Bool
+}
+
+type ChannelStats struct {
+	ChannelID          string
+	Depth              int
+	Capacity           int
+	MaxDepth           int
+	Enqueued           uint64
+	Overflowed         uint64
+	Submitted          uint64
+	Batches            uint64
+	InFlight           bool
+	LastSubmitDuration time.Duration
+}
+
+type Stats struct {
+	Channels   []ChannelStats
+	Depth      int
+	Enqueued   uint64
+	Overflowed uint64
+	Submitted  uint64
+	Batches    uint64
+}
+
+func New(opts Options, submit SubmitFunc, logger *logging.Logger) *Pool {
+	if submit == nil {
+		panic("submitpool.New: submit must not be nil")
+	}
+	if logger == nil {
+		panic("submitpool.New: logger must not be nil")
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaultQueueSize
+	}
+	if opts.CoalesceWindow <= 0 {
+		opts.CoalesceWindow = defaultCoalesceWindow
+	}
+	if opts.MaxBatchSize <= 0 {
+		opts.MaxBatchSize = defaultMaxBatchSize
+	}
+	return &Pool{opts: opts, submit: submit, logger: logger, queues: map[string]*channelQueue{}}
+}
+
+// Start binds the pool to ctx. Workers are started lazily per channel and
+// stop when ctx is canceled.
+func (p *Pool) Start(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.ctx = ctx
+}
+
+func (p *Pool) Enqueue(event evelogs.ReportEvent) error {
+	q, err := p.queueFor(strings.TrimSpace(event.Channel.ID))
+	if err != nil {
+		return err
+	}
+	select {
+	case q.items <- event:
+		q.enqueued.Add(1)
+		depth := int64(len(q.items))
+		if depth > q.maxDepth.Load() {
+			q.maxDepth.Store(depth)
+		}
+		if depth == 1 && q.overflowing.CompareAndSwap(true, false) {
+			p.logger.Info("submit queue recovered", logging.Field("channel_id", q.channelID))
+		}
+		return nil
+	default:
+		q.overflowed.Add(1)
+		if q.overflowing.CompareAndSwap(false, true) {
+			p.logger.Warn("submit queue full; server is not keeping up",
+				logging.Field("channel_id", q.channelID),
+				logging.Field("capacity", cap(q.items)),
+			)
+		}
+		return ErrQueueFull
+	}
+}
+
+func (p *Pool) queueFor(channelID string) (*channelQueue, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ctx == nil || p.ctx.Err() != nil {
+		return nil, ErrStopped
+	}
+	if q, ok := p.queues[channelID]; ok {
+		return q, nil
+	}
+	q := &channelQueue{channelID: channelID, items: make(chan evelogs.ReportEvent, p.opts.QueueSize)}
+	p.queues[channelID] = q
+	ctx := p.ctx
+	p.workers.Go(func() {
+		p.runWorker(ctx, q)
+	})
+	p.logger.Debug("started submit worker", logging.Field("channel_id", channelID))
+	return q, nil
+}
+
+func (p *Pool) runWorker(ctx context.Context, q *channelQueue) {
+	for {
+		var first evelogs.ReportEvent
+		select {
+		case <-ctx.Done():
+			return
+		case first = <-q.items:
+		}
+		batch := p.collectBatch(ctx, q, first)
+		q.inFlight.Store(true)
+		started := time.Now()
+		p.submit(ctx, q.channelID, batch)
+		q.lastDuration.Store(int64(time.Since(started)))
+		q.inFlight.Store(false)
+		q.submitted.Add(uint64(len(batch)))
+		q.batches.Add(1)
+	}
+}
+
+func (p *Pool) collectBatch(ctx context.Context, q *channelQueue, first evelogs.ReportEvent) []evelogs.ReportEvent {
+	batch := []evelogs.ReportEvent{first}
+	timer := time.NewTimer(p.opts.CoalesceWindow)
+	defer timer.Stop()
+	for len(batch) < p.opts.MaxBatchSize {
+		select {
+		case <-ctx.Done():
+			return batch
+		case <-timer.C:
+			return batch
+		case event := <-q.items:
+			batch = append(batch, event)
+		}
+	}
+	return batch
+}
+
+// Wait blocks until every worker has exited after the Start context ends.
+func (p *Pool) Wait() {
+	p.workers.Wait()
+}
+
+// Drain removes and returns reports still queued. Call it after Wait so no
+// worker is racing for the same items.
+func (p *Pool) Drain() []evelogs.ReportEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := []evelogs.ReportEvent{}
+	for _, q := range p.queues {
+		out = append(out, drainQueue(q.items)...)
+	}
+	return out
+}
+
+func drainQueue(items chan evelogs.ReportEvent) []evelogs.ReportEvent {
+	out := []evelogs.ReportEvent{}
+	for {
+		select {
+		case event := <-items:
+			out = append(out, event)
+		default:
+			return out
+		}
+	}
+}
+
+func (p *Pool) Stats() Stats {
+	p.mu.Lock()
+	queues := make([]*channelQueue, 0, len(p.queues))
+	for _, q := range p.queues {
+		queues = append(queues, q)
+	}
+	p.mu.Unlock()
+
+	stats := Stats{Channels: make([]ChannelStats, 0, len(queues))}
+	for _, q := range queues {
+		row := ChannelStats{
+			ChannelID:          q.channelID,
+			Depth:              len(q.items),
+			Capacity:           cap(q.items),
+			MaxDepth:           int(q.maxDepth.Load()),
+			Enqueued:           q.enqueued.Load(),
+			Overflowed:         q.overflowed.Load(),
+			Submitted:          q.submitted.Load(),
+			Batches:            q.batches.Load(),
+			InFlight:           q.inFlight.Load(),
+			LastSubmitDuration: time.Duration(q.lastDuration.Load()),
+		}
+		stats.Channels = append(stats.Channels, row)
+		stats.Depth += row.Depth
+		stats.Enqueued += row.Enqueued
+		stats.Overflowed += row.Overflowed
+		stats.Submitted += row.Submitted
+		stats.Batches += row.Batches
+	}
+	sort.Slice(stats.Channels, func(i, j int) bool {
+		return stats.Channels[i].ChannelID < stats.Channels[j].ChannelID
+	})
+	return stats
+}
@@ -0,0 +1,124 @@
+package submitpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/evelogs"
+	"sentinel2-uploader/internal/logging"
+)
+
+func testLogger() *logging.Logger {
+	logger := logging.New(false)
+	logger.SetTerminalOutputEnabled(false)
+	return logger
+}
+
+func report(channelID string, line string) evelogs.ReportEvent {
+	return evelogs.ReportEvent{Line: line, Channel: client.ChannelConfig{ID: channelID, Name: channelID}}
+}
+
+func TestPool_EnqueueNeverBlocksOnSlowSubmit(t *testing.T) {
+	release := make(chan struct{})
+	pool := New(Options{QueueSize: 2, CoalesceWindow: time.Millisecond, MaxBatchSize: 1}, func(ctx context.Context, _ string, _ []evelogs.ReportEvent) {
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+	}, testLogger())
+	ctx, cancel := context.WithCancel(context.Background())
+	pool.Start(ctx)
+
+	if err := pool.Enqueue(report("intel", "first")); err != nil {
+		t.Fatalf("Enqueue(first) error = %v", err)
+	}
+	// Wait for the worker to pick up the first report and block in submit.
+	deadline := time.Now().Add(time.Second)
+	for pool.Stats().Depth != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	done := make(chan []error, 1)
+	go func() {
+		errs := []error{}
+		for _, line := range []string{"second", "third", "fourth"} {
+			errs = append(errs, pool.Enqueue(report("intel", line)))
+		}
+		done <- errs
+	}()
+	var errs []error
+	select {
+	case errs = <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Enqueue blocked while submit was stalled")
+	}
+	if errs[0] != nil || errs[1] != nil || !errors.Is(errs[2], ErrQueueFull) {
+		t.Fatalf("Enqueue errors = %v, want [nil nil ErrQueueFull]", errs)
+	}
+	if other := pool.Enqueue(report("other", "unaffected")); other != nil {
+		t.Fatalf("Enqueue on another channel error = %v", other)
+	}
+
+	stats := pool.Stats()
+	if stats.Overflowed != 1 || stats.Enqueued != 4 {
+		t.Fatalf("stats = %#v, want 1 overflowed and 4 enqueued", stats)
+	}
+
+	cancel()
+	close(release)
+	pool.Wait()
+	if err := pool.Enqueue(report("intel", "late")); !errors.Is(err, ErrStopped) {
+		t.Fatalf("Enqueue after stop error = %v, want ErrStopped", err)
+	}
+	// Every accepted report is either submitted or handed back by Drain.
+	left := pool.Drain()
+	if got := pool.Stats().Submitted + uint64(len(left)); got != 4 {
+		t.Fatalf("submitted+drained = %d, want 4", got)
+	}
+}
+
+func TestPool_CoalescesPerChannelInOrder(t *testing.T) {
+	var mu sync.Mutex
+	batches := map[string][][]string{}
+	pool := New(Options{CoalesceWindow: 50 * time.Millisecond}, func(_ context.Context, channelID string, batch []evelogs.ReportEvent) {
+		lines := []string{}
+		for _, event := range batch {
+			lines = append(lines, event.Line)
+		}
+		mu.Lock()
+		batches[channelID] = append(batches[channelID], lines)
+		mu.Unlock()
+	}, testLogger())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool.Start(ctx)
+
+	for _, event := range []evelogs.ReportEvent{
+		report("intel", "a1"), report("other", "b1"), report("intel", "a2"), report("intel", "a3"),
+	} {
+		if err := pool.Enqueue(event); err != nil {
+			t.Fatalf("Enqueue(%q) error = %v", event.Line, err)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if pool.Stats().Submitted == 4 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got := batches["intel"]; len(got) != 1 || len(got[0]) != 3 || got[0][0] != "a1" || got[0][2] != "a3" {
+		t.Fatalf("intel batches = %v, want one ordered batch [a1 a2 a3]", got)
+	}
+	if got := batches["other"]; len(got) != 1 || len(got[0]) != 1 {
+		t.Fatalf("other batches = %v, want one batch", got)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -68,10 +69,18 @@ type controller struct {
 	win      fyne.Window
 	logger   *logging.Logger
 	runner   *runtime.Controller
-
-	baseURL *widget.Entry
-	token   *widget.Entry
-	logDir  *widget.Entry
+	// logHeaders caches log headers between channel health scans.
+	logHeaders     *evelogs.HeaderCache
+	settingsWriter config.SettingsWriter
+
+	baseURL      *widget.Entry
+	token        *widget.Entry
+	logDir       *widget.Entry
+	extraLogDirs *widget.Entry
+	charMode     *widget.Select
+	characters   *widget.Entry
+	// remoteCommands ticks the server commands the uploader acts on.
+	remoteCommands *widget.CheckGroup
 
 	debugLogs      *widget.Check
 	connectOnStart *sliderToggle
@@ -110,6 +119,9 @@ type controller struct {
 	dirPickerCurrent string
 	dirPickerItems   []string
 	dirPickerList    *widget.List
+	// dirPickerDetected backs dirPickerDetect, the discovered log folders.
+	dirPickerDetected []config.LogDirCandidate
+	dirPickerDetect   *widget.Select
 
 	cleanupOnce    sync.Once
 	quitOnce       sync.Once
@@ -145,8 +157,17 @@ func newController(rootCtx context.Context, uiApp fyne.App, defaults config.Opti
 	settings.BaseURL = defaults.BaseURL
 	settings.Token = defaults.Token
 	settings.LogDir = defaults.LogDir
+	settings.ExtraLogDirs = config.NormalizeLogDirs(defaults.ExtraLogDirs)
 	settings.AutoConnect = defaults.AutoConnect
 	settings.Debug = defaults.Debug
+	settings.FilterDryRun = defaults.FilterDryRun
+	settings.RedactURLs = defaults.RedactURLs
+	settings.RedactEmails = defaults.RedactEmails
+	settings.ResumeMaxAge = config.FormatDurationSetting(defaults.ResumeMaxAge)
+	settings.OutboxMaxAge = config.FormatDurationSetting(defaults.OutboxMaxAge)
+	settings.RealtimeTransport = defaults.RealtimeTransport
+	settings.RemoteCommands = config.NormalizeRemoteCommands(defaults.RemoteCommands)
+	settings.StatusAddr = defaults.StatusAddr
 
 	logger := logging.New(false)
 	if logger == nil {
@@ -162,16 +183,18 @@ func newController(rootCtx context.Context, uiApp fyne.App, defaults config.Opti
 	appCtx, appCancel := context.WithCancel(rootCtx)
 
 	c := &controller{
-		app:          uiApp,
-		version:      strings.TrimSpace(buildVersion),
-		settings:     settings,
-		draft:        settings,
-		logger:       logger,
-		runner:       runtime.NewController(appCtx),
-		appCtx:       appCtx,
-		appCancel:    appCancel,
-		appStopped:   make(chan struct{}),
-		dismissedTag: strings.TrimSpace(settings.LastDismissedUpdateTag),
+		app:            uiApp,
+		version:        strings.TrimSpace(buildVersion),
+		settings:       settings,
+		draft:          settings,
+		logger:         logger,
+		runner:         runtime.NewController(appCtx),
+		logHeaders:     evelogs.NewHeaderCache(),
+		settingsWriter: config.NewSettingsWriter(defaults),
+		appCtx:         appCtx,
+		appCancel:      appCancel,
+		appStopped:     make(chan struct{}),
+		dismissedTag:   strings.TrimSpace(settings.LastDismissedUpdateTag),
 	}
 
 	uiApp.SetIcon(uploaderIconResource())
@@ -192,6 +215,8 @@ func newController(rootCtx context.Context, uiApp fyne.App, defaults config.Opti
 }
 
 func (c *controller) run() {
+	// Warm the log folder discovery so the folder picker does not wait.
+	go config.DiscoverLogDirs()
 	c.setRunningState(false)
 	c.startChannelHealthLoop()
 	c.startUpdateCheckLoop()
@@ -273,6 +298,20 @@ func (c *controller) buildUI(defaults config.Options) {
 	c.logDir = widget.NewEntry()
 	c.logDir.SetText(c.draft.LogDir)
 
+	c.extraLogDirs = widget.NewEntry()
+	c.extraLogDirs.SetPlaceHolder("Optional, separated by " + string(os.PathListSeparator))
+	c.extraLogDirs.SetText(config.JoinLogDirs(c.draft.ExtraLogDirs))
+
+	c.charMode = widget.NewSelect(charModeLabels, nil)
+	c.charMode.SetSelected(charModeLabel(c.draft.CharacterFilter.Mode))
+	c.characters = widget.NewEntry()
+	c.characters.SetPlaceHolder("Character IDs, e.g. 90000001=Scout, 90000002")
+	c.characters.SetText(config.FormatCharacterList(c.draft.CharacterFilter.Characters))
+
+	c.remoteCommands = widget.NewCheckGroup(config.RemoteCommandNames(), nil)
+	c.remoteCommands.Horizontal = true
+	c.remoteCommands.SetSelected(config.AllowedRemoteCommands(c.draft.RemoteCommands))
+
 	c.debugLogs = widget.NewCheck("Debug level", func(v bool) {
 		c.draft.Debug = v
 		c.logger.SetDebugEnabled(v)
@@ -342,6 +381,28 @@ func (c *controller) buildUI(defaults config.Options) {
 		c.refreshSettingsActions()
 		c.refreshChannelHealth()
 	}
+	c.extraLogDirs.OnChanged = func(v string) {
+		c.draft.ExtraLogDirs = config.SplitLogDirs(v)
+		c.refreshSettingsActions()
+		c.refreshChannelHealth()
+	}
+	c.charMode.OnChanged = func(v string) {
+		c.draft.CharacterFilter.Mode = charModeFromLabel(v)
+		c.refreshSettingsActions()
+	}
+	c.characters.OnChanged = func(v string) {
+		c.draft.CharacterFilter.Characters = config.ParseCharacterList(v)
+		c.refreshSettingsActions()
+	}
+	c.remoteCommands.OnChanged = func(selected []string) {
+		// SetSelected also lands here; keep an unchanged draft as saved so
+		// an empty allowlist is not rewritten as the defaults.
+		if sameRemoteCommands(selected, config.AllowedRemoteCommands(c.draft.RemoteCommands)) {
+			return
+		}
+		c.draft.RemoteCommands = config.RemoteCommandsFromSelection(selected)
+		c.refreshSettingsActions()
+	}
 
 	browseLogDir := widget.NewButton("Browse...", c.selectLogDir)
 	logDirRow := container.NewBorder(nil, nil, nil, container.NewHBox(c.horizontalGap(tightPad), browseLogDir), c.logDir)
@@ -355,6 +416,16 @@ func (c *controller) buildUI(defaults config.Options) {
 		c.verticalGap(8),
 		widget.NewLabel("Log Directory"),
 		logDirRow,
+		c.verticalGap(8),
+		widget.NewLabel("Additional Log Directories"),
+		c.extraLogDirs,
+		c.verticalGap(8),
+		widget.NewLabel("Uploading Characters"),
+		c.charMode,
+		c.characters,
+		c.verticalGap(8),
+		widget.NewLabel("Remote Commands"),
+		c.remoteCommands,
 	)
 
 	settingsRow := container.NewVBox(
@@ -423,11 +494,15 @@ func (c *controller) buildUI(defaults config.Options) {
 }
 
 func (c *controller) persistSettings() {
-	_ = config.SaveSettings(c.settings)
+	_ = c.saveSettingsFile()
+}
+
+func (c *controller) saveSettingsFile() error {
+	return c.settingsWriter.Save(c.settings)
 }
 
 func (c *controller) settingsDirty() bool {
-	return c.draft != c.settings
+	return !c.draft.Equal(c.settings)
 }
 
 func (c *controller) refreshSettingsActions() {
@@ -460,6 +535,10 @@ func (c *controller) cancelDraftSettings() {
 	c.baseURL.SetText(c.draft.BaseURL)
 	c.token.SetText(c.draft.Token)
 	c.logDir.SetText(c.draft.LogDir)
+	c.extraLogDirs.SetText(config.JoinLogDirs(c.draft.ExtraLogDirs))
+	c.charMode.SetSelected(charModeLabel(c.draft.CharacterFilter.Mode))
+	c.characters.SetText(config.FormatCharacterList(c.draft.CharacterFilter.Characters))
+	c.remoteCommands.SetSelected(config.AllowedRemoteCommands(c.draft.RemoteCommands))
 	c.debugLogs.SetChecked(c.draft.Debug)
 	c.connectOnStart.SetChecked(c.draft.AutoConnect)
 	c.minimizeToTray.SetChecked(c.draft.MinimizeToTray)
@@ -474,6 +553,34 @@ func (c *controller) toggleRow(label string, sw *sliderToggle) fyne.CanvasObject
 	return container.NewBorder(nil, nil, widget.NewLabel(label), sw, nil)
 }
 
+func sameRemoteCommands(a, b []string) bool {
+	return slices.Equal(slices.Sorted(slices.Values(a)), slices.Sorted(slices.Values(b)))
+}
+
+var charModeLabels = []string{"All characters", "Only listed characters", "All except listed characters"}
+
+func charModeLabel(mode string) string {
+	switch config.NormalizeCharacterFilterMode(mode) {
+	case config.CharacterFilterAllow:
+		return charModeLabels[1]
+	case config.CharacterFilterDeny:
+		return charModeLabels[2]
+	default:
+		return charModeLabels[0]
+	}
+}
+
+func charModeFromLabel(label string) string {
+	switch label {
+	case charModeLabels[1]:
+		return config.CharacterFilterAllow
+	case charModeLabels[2]:
+		return config.CharacterFilterDeny
+	default:
+		return config.CharacterFilterOff
+	}
+}
+
 func (c *controller) verticalGap(height float32) fyne.CanvasObject {
 	spacer := canvas.NewRectangle(color.Transparent)
 	spacer.SetMinSize(fyne.NewSize(1, height))
@@ -617,7 +724,8 @@ func (c *controller) refreshChannelHealth() {
 	} else if !info.IsDir() {
 		scanErrText = "Log path is not a directory."
 	} else {
-		logs, findErr := evelogs.FindLogs(logDir, c.channels)
+		roots := append([]string{logDir}, config.SplitLogDirs(c.extraLogDirs.Text)...)
+		logs, findErr := evelogs.FindLogsInDirs(roots, c.channels, c.logHeaders)
 		if findErr != nil {
 			scanErrText = fmt.Sprintf("Failed to scan logs: %v", findErr)
 		} else {
@@ -918,3 +1026,39 @@ func (c *controller) refreshDirPickerList() {
 	c.dirPickerItems = items
 	c.dirPickerList.Refresh()
 }
+
+// refreshDirPickerDetected lists the discovered EVE log folders, the most
+// recently active first. Discovery globs many folders, so it runs off the UI
+// goroutine.
+func (c *controller) refreshDirPickerDetected() {
+	if len(c.dirPickerDetected) == 0 {
+		c.dirPickerDetect.PlaceHolder = "Looking for EVE log folders..."
+		c.dirPickerDetect.Disable()
+		c.dirPickerDetect.Refresh()
+	}
+	go func() {
+		detected := config.DiscoverLogDirs()
+		fyne.Do(func() {
+			c.showDirPickerDetected(detected)
+		})
+	}()
+}
+
+func (c *controller) showDirPickerDetected(detected []config.LogDirCandidate) {
+	c.dirPickerDetected = detected
+	now := time.Now()
+	options := make([]string, 0, len(c.dirPickerDetected))
+	for _, candidate := range c.dirPickerDetected {
+		options = append(options, candidate.Label(now))
+	}
+	c.dirPickerDetect.SetOptions(options)
+	c.dirPickerDetect.ClearSelected()
+	if len(options) == 0 {
+		c.dirPickerDetect.PlaceHolder = "No EVE log folders found"
+		c.dirPickerDetect.Disable()
+	} else {
+		c.dirPickerDetect.PlaceHolder = "Jump to a detected EVE log folder"
+		c.dirPickerDetect.Enable()
+	}
+	c.dirPickerDetect.Refresh()
+}
@@ -130,13 +130,20 @@ func (c *controller) currentOptions() config.Options {
 	if c.debugLogs != nil {
 		debugEnabled = c.debugLogs.Checked
 	}
-	return config.Options{
-		BaseURL: strings.TrimSpace(c.baseURL.Text),
-		Token:   strings.TrimSpace(c.token.Text),
-		LogFile: "",
-		LogDir:  strings.TrimSpace(c.logDir.Text),
-		Debug:   debugEnabled,
+	opts := config.Options{
+		BaseURL:      strings.TrimSpace(c.baseURL.Text),
+		Token:        strings.TrimSpace(c.token.Text),
+		LogFile:      "",
+		LogDir:       strings.TrimSpace(c.logDir.Text),
+		ExtraLogDirs: config.SplitLogDirs(c.extraLogDirs.Text),
+		CharacterFilter: config.CharacterFilter{
+			Mode:       charModeFromLabel(c.charMode.Selected),
+			Characters: config.ParseCharacterList(c.characters.Text),
+		},
+		RemoteCommands: c.draft.RemoteCommands,
+		Debug:          debugEnabled,
 	}
+	return opts.WithFileOnlySettings(c.draft)
 }
 
 func (c *controller) startUploader() {
@@ -174,6 +181,11 @@ func (c *controller) startUploaderWithContext(auto bool) {
 				c.applyRuntimeStatus(status)
 			})
 		},
+		OnServerMessage: func(text string) {
+			fyne.Do(func() {
+				dialog.ShowInformation("Message from Sentinel", text, c.win)
+			})
+		},
 		OnExit: func(runErr error) {
 			fyne.Do(func() {
 				c.setRunningState(false)
@@ -292,13 +304,28 @@ func (c *controller) selectLogDir() {
 			c.refreshDirPickerList()
 		}
 
-		header := container.NewBorder(nil, nil, upButton, nil, c.dirPickerPath)
+		c.dirPickerDetect = widget.NewSelect(nil, nil)
+		c.dirPickerDetect.OnChanged = func(string) {
+			id := c.dirPickerDetect.SelectedIndex()
+			if id < 0 || id >= len(c.dirPickerDetected) {
+				return
+			}
+			c.dirPickerCurrent = c.ensureDirPickerStartPath(c.dirPickerDetected[id].Path)
+			c.dirPickerPath.SetText(c.dirPickerCurrent)
+			c.refreshDirPickerList()
+		}
+
+		header := container.NewVBox(
+			container.NewBorder(nil, nil, widget.NewLabel("Detected"), nil, c.dirPickerDetect),
+			container.NewBorder(nil, nil, upButton, nil, c.dirPickerPath),
+		)
 		actions := container.NewHBox(useCurrent, closeButton)
 		c.dirPickerWindow.SetContent(container.NewBorder(header, actions, nil, nil, c.dirPickerList))
 	}
 
 	c.dirPickerPath.SetText(c.dirPickerCurrent)
 	c.refreshDirPickerList()
+	c.refreshDirPickerDetected()
 	c.dirPickerWindow.Show()
 	c.dirPickerWindow.RequestFocus()
 }
@@ -17,7 +17,6 @@ import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/dialog"
 
-	"sentinel2-uploader/internal/config"
 	"sentinel2-uploader/internal/logging"
 )
 
@@ -274,7 +273,7 @@ func (c *controller) rememberDismissedUpdateTag(tag string) {
 	c.updatePrompted = tag
 	c.settings.LastDismissedUpdateTag = tag
 	c.draft.LastDismissedUpdateTag = tag
-	if err := config.SaveSettings(c.settings); err != nil {
+	if err := c.saveSettingsFile(); err != nil {
 		c.logger.Warn("failed to persist dismissed update tag", logging.Field("tag", tag), logging.Field("error", err))
 	}
 }
@@ -73,10 +73,11 @@ func newHeadlessModel(rootCtx context.Context, buildVersion string, opts config.
 	m := &headlessModel{
 		buildVersion: buildVersion,
 		modelDeps: modelDeps{
-			runner:     runtime.NewController(runCtx),
-			logger:     logger,
-			rootCtx:    runCtx,
-			rootCancel: runCancel,
+			runner:         runtime.NewController(runCtx),
+			logger:         logger,
+			settingsWriter: config.NewSettingsWriter(opts),
+			rootCtx:        runCtx,
+			rootCancel:     runCancel,
 		},
 		modelChannels: modelChannels{
 			logCh:    make(chan string, logChannelBufferSize),
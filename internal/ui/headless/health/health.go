@@ -27,15 +27,34 @@ const (
 	Stale
 )
 
+func (k Kind) String() string {
+	switch k {
+	case Active:
+		return "active"
+	case Warn:
+		return "warn"
+	case Stale:
+		return "stale"
+	default:
+		return "missing"
+	}
+}
+
 type Row struct {
 	Name   string
 	Kind   Kind
 	Reason string
 }
 
-func Compute(logDir string, channels []client.ChannelConfig, now time.Time) ([]Row, string) {
+// Compute reports per-channel log activity across logDirs. The first entry is
+// the primary log directory and must be usable; further roots are scanned
+// when readable. headers, which may be nil, caches log headers between calls.
+func Compute(logDirs []string, channels []client.ChannelConfig, headers *evelogs.HeaderCache, now time.Time) ([]Row, string) {
 	rows := make([]Row, 0, len(channels))
-	logDir = strings.TrimSpace(logDir)
+	logDir := ""
+	if len(logDirs) > 0 {
+		logDir = strings.TrimSpace(logDirs[0])
+	}
 	if logDir == "" {
 		return rows, "Log directory is not configured."
 	}
@@ -50,7 +69,7 @@ func Compute(logDir string, channels []client.ChannelConfig, now time.Time) ([]R
 
 	latestByChannel := map[string]time.Time{}
 	latestFileByChannel := map[string]string{}
-	logs, findErr := evelogs.FindLogs(logDir, channels)
+	logs, findErr := evelogs.FindLogsInDirs(logDirs, channels, headers)
 	if findErr != nil {
 		return rows, "Failed to scan logs: " + findErr.Error()
 	}
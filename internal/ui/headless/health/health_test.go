@@ -35,7 +35,7 @@ func TestCompute_ClassifiesChannelHealthByFileAge(t *testing.T) {
 		{ID: "4", Name: "Missing"},
 	}
 
-	rows, msg := Compute(dir, channels, now)
+	rows, msg := Compute([]string{dir}, channels, nil, now)
 	if msg != "" {
 		t.Fatalf("Compute() message = %q, want empty", msg)
 	}
@@ -53,7 +53,7 @@ func TestCompute_ClassifiesChannelHealthByFileAge(t *testing.T) {
 }
 
 func TestCompute_ReportsMissingOrInvalidDirectory(t *testing.T) {
-	rows, msg := Compute("", nil, time.Now())
+	rows, msg := Compute(nil, nil, nil, time.Now())
 	if len(rows) != 0 || !strings.Contains(msg, "not configured") {
 		t.Fatalf("Compute(empty) rows=%d msg=%q", len(rows), msg)
 	}
@@ -62,7 +62,7 @@ func TestCompute_ReportsMissingOrInvalidDirectory(t *testing.T) {
 	if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
 		t.Fatalf("write file: %v", err)
 	}
-	rows, msg = Compute(path, nil, time.Now())
+	rows, msg = Compute([]string{path}, nil, nil, time.Now())
 	if len(rows) != 0 || !strings.Contains(msg, "not a directory") {
 		t.Fatalf("Compute(file) rows=%d msg=%q", len(rows), msg)
 	}
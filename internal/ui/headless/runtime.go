@@ -7,6 +7,7 @@ import (
 
 	"sentinel2-uploader/internal/client"
 	"sentinel2-uploader/internal/config"
+	"sentinel2-uploader/internal/evelogs"
 	"sentinel2-uploader/internal/runstatus"
 	"sentinel2-uploader/internal/runtime"
 	"sentinel2-uploader/internal/ui/headless/health"
@@ -15,15 +16,22 @@ import (
 )
 
 func (m *headlessModel) currentOptions() config.Options {
-	return config.Options{
-		BaseURL:     strings.TrimSpace(m.ui.Inputs[0].Value()),
-		Token:       strings.TrimSpace(m.ui.Inputs[1].Value()),
-		AutoConnect: m.ui.AutoConn,
-		ImGay:       m.ui.ImGay,
-		LogFile:     "",
-		LogDir:      strings.TrimSpace(m.ui.Inputs[2].Value()),
-		Debug:       m.ui.DebugOn,
+	opts := config.Options{
+		BaseURL:      strings.TrimSpace(m.ui.Inputs[0].Value()),
+		Token:        strings.TrimSpace(m.ui.Inputs[1].Value()),
+		AutoConnect:  m.ui.AutoConn,
+		ImGay:        m.ui.ImGay,
+		LogFile:      "",
+		LogDir:       strings.TrimSpace(m.ui.Inputs[2].Value()),
+		ExtraLogDirs: config.SplitLogDirs(m.ui.Inputs[3].Value()),
+		CharacterFilter: config.CharacterFilter{
+			Mode:       m.ui.CharMode,
+			Characters: config.ParseCharacterList(m.ui.Inputs[4].Value()),
+		},
+		RemoteCommands: config.ParseRemoteCommandList(m.ui.Inputs[5].Value()),
+		Debug:          m.ui.DebugOn,
 	}
+	return opts.WithFileOnlySettings(m.ui.DraftSettings)
 }
 
 func (m *headlessModel) canConnect() bool {
@@ -61,6 +69,7 @@ func (m *headlessModel) startUploaderCmd(auto bool) tea.Cmd {
 		err := m.runner.Start(opts, m.logger, runtime.StartHooks{
 			OnChannelsUpdate: m.onRuntimeChannelsUpdate,
 			OnStatus:         m.onRuntimeStatus,
+			OnServerMessage:  m.onRuntimeServerMessage,
 			OnExit:           m.onRuntimeExit,
 		})
 
@@ -77,7 +86,7 @@ func (m *headlessModel) onRuntimeChannelsUpdate(channels []client.ChannelConfig)
 			continue
 		}
 
-		normalized = append(normalized, client.ChannelConfig{ID: id, Name: name})
+		normalized = append(normalized, client.ChannelConfig{ID: id, Name: name, EVEChannelID: channel.EVEChannelID})
 	}
 
 	select {
@@ -103,6 +112,14 @@ func (m *headlessModel) onRuntimeStatus(status string) {
 	}
 }
 
+func (m *headlessModel) onRuntimeServerMessage(text string) {
+	if m.program == nil {
+		return
+	}
+
+	m.program.Send(serverMessageMsg(text))
+}
+
 func (m *headlessModel) onRuntimeExit(runErr error) {
 	if m.program == nil {
 		return
@@ -150,8 +167,11 @@ func (m *headlessModel) startErrorText(auto bool, message string) string {
 }
 
 func (m *headlessModel) refreshChannelHealth() {
+	if m.healthHeaders == nil {
+		m.healthHeaders = evelogs.NewHeaderCache()
+	}
 	m.lastHealthRefresh = time.Now()
-	m.channelHealth, m.healthDetail = health.Compute(m.ui.Inputs[2].Value(), m.channels, m.lastHealthRefresh)
+	m.channelHealth, m.healthDetail = health.Compute(append([]string{m.ui.Inputs[2].Value()}, config.SplitLogDirs(m.ui.Inputs[3].Value())...), m.channels, m.healthHeaders, m.lastHealthRefresh)
 }
 
 func (m *headlessModel) cleanup() {
@@ -8,6 +8,8 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 
 	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/config"
+	"sentinel2-uploader/internal/evelogs"
 	"sentinel2-uploader/internal/logging"
 	"sentinel2-uploader/internal/runtime"
 	"sentinel2-uploader/internal/ui/headless/health"
@@ -23,6 +25,7 @@ const (
 
 type logMsg string
 type statusMsg string
+type serverMessageMsg string
 type tickMsg struct{}
 type channelsUpdatedMsg []client.ChannelConfig
 type updateAvailableMsg struct {
@@ -44,6 +47,8 @@ type startResultMsg struct {
 
 type quitNowMsg struct{}
 
+type logDirsDetectedMsg []config.LogDirCandidate
+
 type statusKind int
 
 const (
@@ -55,12 +60,13 @@ const (
 )
 
 type modelDeps struct {
-	runner      *runtime.Controller
-	logger      *logging.Logger
-	rootCtx     context.Context
-	unsubscribe func()
-	rootCancel  context.CancelFunc
-	program     *tea.Program
+	runner         *runtime.Controller
+	logger         *logging.Logger
+	settingsWriter config.SettingsWriter
+	rootCtx        context.Context
+	unsubscribe    func()
+	rootCancel     context.CancelFunc
+	program        *tea.Program
 }
 
 type modelChannels struct {
@@ -81,6 +87,7 @@ type modelRuntime struct {
 	channelHealth     []health.Row
 	healthDetail      string
 	lastHealthRefresh time.Time
+	healthHeaders     *evelogs.HeaderCache
 }
 
 type headlessModel struct {
@@ -65,12 +65,19 @@ func (m *headlessModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.ui.UpdateModalChoice = headlessview.UpdateChoiceLater
 		m.ui.UpdateModalOpen = true
 		return m, waitForUpdate(m.updateCh)
+	case logDirsDetectedMsg:
+		m.ui.DetectedLogDirs = msg
+		m.ui.ResizeFilePicker()
+		return m, nil
 	case openReleaseResultMsg:
 		if msg.err != nil {
 			m.ui.ErrorModalText = "Failed to open release page: " + msg.err.Error()
 			m.logger.Warn("failed to open release url", logging.Field("url", msg.url), logging.Field("error", msg.err))
 		}
 		return m, nil
+	case serverMessageMsg:
+		m.ui.NoticeModalText = string(msg)
+		return m, nil
 	case statusMsg:
 		m.applyRuntimeStatus(string(msg))
 		return m, waitForStatus(m.statusCh)
@@ -214,7 +221,15 @@ func (m *headlessModel) openBrowseCmd() tea.Cmd {
 	m.ui.FilePicker.Path = ""
 	m.ui.FilePickerOpen = true
 	m.ui.ResizeFilePicker()
-	return m.ui.FilePicker.Init()
+	return tea.Batch(m.ui.FilePicker.Init(), discoverLogDirsCmd())
+}
+
+// discoverLogDirsCmd runs log folder discovery, which globs many folders,
+// outside the update loop.
+func discoverLogDirsCmd() tea.Cmd {
+	return func() tea.Msg {
+		return logDirsDetectedMsg(config.DiscoverLogDirs())
+	}
 }
 
 func (m *headlessModel) requestQuitCmd() tea.Cmd {
@@ -287,6 +302,13 @@ func (m *headlessModel) updateFilePickerMsg(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, m.ui.FilePicker.Init()
 		case "enter":
 			return m.selectCurrentFilePickerDir()
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			index := int(keyMsg.String()[0] - '1')
+			if index >= len(m.ui.DetectedLogDirs) {
+				return m, nil
+			}
+			m.ui.FilePicker.CurrentDirectory = m.ui.DetectedLogDirs[index].Path
+			return m, m.ui.FilePicker.Init()
 		}
 	}
 	var cmd tea.Cmd
@@ -330,7 +352,7 @@ func (m *headlessModel) saveSettingsDraft() tea.Cmd {
 	} else {
 		settings.LastDismissedUpdateTag = m.dismissedTag
 	}
-	if err := config.SaveSettings(settings); err != nil {
+	if err := m.settingsWriter.Save(settings); err != nil {
 		m.ui.ErrorModalText = err.Error()
 		return nil
 	}
@@ -369,7 +391,7 @@ func (m *headlessModel) rememberDismissedUpdateTag(tag string) {
 		settings = config.SettingsFromOptions(m.currentOptions())
 	}
 	settings.LastDismissedUpdateTag = tag
-	if saveErr := config.SaveSettings(settings); saveErr != nil {
+	if saveErr := m.settingsWriter.Save(settings); saveErr != nil {
 		m.logger.Warn("failed to persist dismissed update tag", logging.Field("tag", tag), logging.Field("error", saveErr))
 	}
 }
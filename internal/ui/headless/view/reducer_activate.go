@@ -1,5 +1,7 @@
 package view
 
+import "sentinel2-uploader/internal/config"
+
 type ActivateEffect int
 
 const (
@@ -42,7 +44,7 @@ func ReduceActivate(state State, canConnect bool, running bool, connecting bool)
 		case state.LogsDebugIndex():
 			state.DebugOn = !state.DebugOn
 			state.DraftSettings.Debug = state.DebugOn
-			state.SettingsDirty = state.DraftSettings != state.SavedSettings
+			state.SettingsDirty = !state.DraftSettings.Equal(state.SavedSettings)
 			return state, ActivateEffectDebugLevelChanged
 		default:
 			return state, ActivateEffectNone
@@ -55,7 +57,12 @@ func ReduceActivate(state State, canConnect bool, running bool, connecting bool)
 	case state.AutoConnectIndex():
 		state.AutoConn = !state.AutoConn
 		state.DraftSettings.AutoConnect = state.AutoConn
-		state.SettingsDirty = state.DraftSettings != state.SavedSettings
+		state.SettingsDirty = !state.DraftSettings.Equal(state.SavedSettings)
+		return state, ActivateEffectNone
+	case state.CharModeIndex():
+		state.CharMode = config.NextCharacterFilterMode(state.CharMode)
+		state.DraftSettings.CharacterFilter.Mode = state.CharMode
+		state.SettingsDirty = !state.DraftSettings.Equal(state.SavedSettings)
 		return state, ActivateEffectNone
 	case state.SaveIndex():
 		return state, ActivateEffectSaveSettings
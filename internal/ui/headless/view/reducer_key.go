@@ -29,6 +29,13 @@ func ReduceKey(state State, msg tea.KeyMsg) (State, KeyEffect) {
 		return state, KeyEffectNone
 	}
 
+	if state.NoticeModalText != "" {
+		if msg.String() == "esc" || key.Matches(msg, state.Keys.Activate) {
+			state.NoticeModalText = ""
+		}
+		return state, KeyEffectNone
+	}
+
 	if state.UpdateModalOpen {
 		switch {
 		case msg.String() == "esc":
@@ -133,6 +133,10 @@ func ReduceMouse(state State, msg tea.MouseMsg) (State, tea.Cmd, MouseEffect) {
 		state.Focus = state.AutoConnectIndex()
 		state.ApplyFocus()
 		return state, tea.Batch(cmds...), MouseEffectActivateFocused
+	case inBounds(zoneSettingsCharMode, msg):
+		state.Focus = state.CharModeIndex()
+		state.ApplyFocus()
+		return state, tea.Batch(cmds...), MouseEffectActivateFocused
 	case inBounds(zoneSettingsSave, msg):
 		state.Focus = state.SaveIndex()
 		state.ApplyFocus()
@@ -209,6 +213,9 @@ func hoveredZone(state State, msg tea.MouseMsg) string {
 	if inBounds(zoneSettingsAutoConnect, msg) {
 		return zoneSettingsAutoConnect
 	}
+	if inBounds(zoneSettingsCharMode, msg) {
+		return zoneSettingsCharMode
+	}
 	if inBounds(zoneSettingsSave, msg) {
 		return zoneSettingsSave
 	}
@@ -1,13 +1,16 @@
 package view
 
 import (
+	"fmt"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
 	zone "github.com/lrstanley/bubblezone"
 
+	"sentinel2-uploader/internal/config"
 	"sentinel2-uploader/internal/ui/headless/health"
 	"sentinel2-uploader/internal/ui/headless/render"
 	"sentinel2-uploader/internal/ui/headless/theme"
@@ -33,7 +36,7 @@ const (
 	channelPaneMinWidth        = 8
 	channelPaneMinHeight       = 3
 	channelListMinWidth        = 10
-	settingsLabelWidth         = 9
+	settingsLabelWidth         = 11
 	settingsRowExtraCapacity   = 5
 	settingsControlMinWidth    = 16
 	settingsBrowsePaddingLeft  = settingsLabelWidth + 1
@@ -70,6 +73,10 @@ func RenderApp(state *State, rt Runtime) string {
 		return zone.Scan(renderModalOverlay(state, base, renderErrorDialog(state)))
 	}
 
+	if state.NoticeModalText != "" {
+		return zone.Scan(renderModalOverlay(state, base, renderNoticeDialog(state)))
+	}
+
 	if state.UpdateModalOpen {
 		return zone.Scan(renderModalOverlay(state, base, renderUpdateDialog(state, rt)))
 	}
@@ -316,7 +323,7 @@ func renderChannelPanelBody(state *State, rt Runtime, width int, height int) str
 
 func renderSettings(state *State) string {
 	panelWidth := settingsPanelWidth(state)
-	labels := []string{"Base URL", "Token", "Log Dir"}
+	labels := []string{"Base URL", "Token", "Log Dir", "Extra Dirs", "Characters", "Commands"}
 	labelWidth := settingsLabelWidth
 	rows := make([]string, 0, len(state.Inputs)+settingsRowExtraCapacity)
 	// Keep one extra column of headroom for cursor/styled edge cases to avoid
@@ -362,6 +369,7 @@ func renderSettings(state *State) string {
 	}
 	rows = append(rows, renderSettingsLabelRow(autoLabel+":", "", labelWidth))
 	rows = append(rows, lipgloss.NewStyle().PaddingLeft(settingsBrowsePaddingLeft).Render(zone.Mark(zoneSettingsAutoConnect, autoControl)))
+	rows = append(rows, renderCharModeRows(state, labelWidth)...)
 	saveLabel := "Save"
 	cancelLabel := "Cancel"
 	if state.SettingsDirty {
@@ -417,6 +425,29 @@ func renderSettings(state *State) string {
 	return renderFrame(state, state.SettingsView.View(), panelWidth)
 }
 
+func renderCharModeRows(state *State, labelWidth int) []string {
+	mode := "[ ] Upload from every character"
+	switch state.CharMode {
+	case config.CharacterFilterAllow:
+		mode = "[x] Only listed characters"
+	case config.CharacterFilterDeny:
+		mode = "[x] All except listed characters"
+	}
+
+	modeLabel := "Filter"
+	modeControl := theme.ButtonStyle.Render(mode)
+	if state.Focus == state.CharModeIndex() {
+		modeLabel = theme.FocusStyle.Render("-> Filter")
+		modeControl = theme.ButtonFocusedStyle.Render(mode)
+	} else if state.HoverZone == zoneSettingsCharMode {
+		modeControl = theme.ButtonHoverStyle.Render(mode)
+	}
+	return []string{
+		renderSettingsLabelRow(modeLabel+":", "", labelWidth),
+		lipgloss.NewStyle().PaddingLeft(settingsBrowsePaddingLeft).Render(zone.Mark(zoneSettingsCharMode, modeControl)),
+	}
+}
+
 func renderSettingsLabelRow(label string, control string, labelWidth int) string {
 	labelCell := lipgloss.NewStyle().Width(labelWidth).Render(label)
 	if control == "" {
@@ -526,6 +557,16 @@ func renderErrorDialog(state *State) string {
 	return renderFrame(state, body, min(state.ContentWidth()-dialogHorizontalInset, errorDialogWidth))
 }
 
+func renderNoticeDialog(state *State) string {
+	body := strings.Join([]string{
+		theme.TitleStyle.Render("Message from Sentinel"),
+		state.NoticeModalText,
+		theme.HelpStyle.Render("Press Enter or Esc to close"),
+	}, "\n")
+
+	return renderFrame(state, body, min(state.ContentWidth()-dialogHorizontalInset, errorDialogWidth))
+}
+
 func renderUpdateDialog(state *State, rt Runtime) string {
 	laterButton := theme.ButtonStyle.Render("Later")
 	openButton := theme.ButtonStyle.Render("Open Release")
@@ -571,11 +612,22 @@ func renderUpdateDialog(state *State, rt Runtime) string {
 
 func renderFilePickerDialog(state *State) string {
 	title := theme.TitleStyle.Render("Select Log Directory")
-	picker := state.FilePicker.View()
-	help := theme.HelpStyle.Render("up/down move • space open • enter select • left/backspace up • esc close")
-	body := strings.Join([]string{title, picker, help}, "\n")
+	width := min(state.PageWidth(), filePickerDialogMaxWidth)
+	lines := []string{title}
+	helpText := "up/down move • space open • enter select • left/backspace up • esc close"
+	if len(state.DetectedLogDirs) > 0 {
+		lines = append(lines, theme.HelpStyle.Render("Detected EVE log folders:"))
+		now := time.Now()
+		for i, candidate := range state.DetectedLogDirs[:min(len(state.DetectedLogDirs), MaxDetectedLogDirs)] {
+			line := fmt.Sprintf("%d %s", i+1, candidate.Label(now))
+			lines = append(lines, render.TruncateDisplayWidth(line, width-panelFrameOverhead))
+		}
+		helpText = "1-9 jump to detected • " + helpText
+	}
+	lines = append(lines, state.FilePicker.View(), theme.HelpStyle.Render(helpText))
+	body := strings.Join(lines, "\n")
 
-	return renderFrame(state, body, min(state.PageWidth(), filePickerDialogMaxWidth))
+	return renderFrame(state, body, width)
 }
 
 func renderModalOverlay(state *State, base string, dialog string) string {
@@ -1,6 +1,7 @@
 package view
 
 import (
+	"os"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/filepicker"
@@ -15,20 +16,23 @@ import (
 )
 
 const (
-	inputCount            = 3
-	defaultInputCharLimit = 2048
-	defaultInputWidth     = 80
-	baseURLInputIndex     = 0
-	tokenInputIndex       = 1
-	logDirInputIndex      = 2
-	defaultTab            = TabOverview
-	defaultAnimPhase      = 0
-	defaultLogViewWidth   = 80
-	defaultLogViewHeight  = 20
-	defaultPaneWidth      = 24
-	defaultPaneHeight     = 8
-	defaultSettingsHeight = 12
-	maxAnimPhaseValue     = 1_000_000_000
+	inputCount             = 6
+	defaultInputCharLimit  = 2048
+	defaultInputWidth      = 80
+	baseURLInputIndex      = 0
+	tokenInputIndex        = 1
+	logDirInputIndex       = 2
+	extraLogDirsInputIndex = 3
+	charactersInputIndex   = 4
+	remoteCommandsIndex    = 5
+	defaultTab             = TabOverview
+	defaultAnimPhase       = 0
+	defaultLogViewWidth    = 80
+	defaultLogViewHeight   = 20
+	defaultPaneWidth       = 24
+	defaultPaneHeight      = 8
+	defaultSettingsHeight  = 12
+	maxAnimPhaseValue      = 1_000_000_000
 )
 
 type State struct {
@@ -41,6 +45,7 @@ type State struct {
 
 	ShowLogs      bool
 	AutoConn      bool
+	CharMode      string
 	SettingsDirty bool
 	FollowLogs    bool
 	DebugOn       bool
@@ -59,13 +64,17 @@ type State struct {
 	ConfirmQuit       bool
 	ConfirmQuitChoice int
 	ErrorModalText    string
+	NoticeModalText   string
 	UpdateModalOpen   bool
 	UpdateModalChoice int
 	UpdateLatestTag   string
 	UpdateReleaseURL  string
 	FilePickerOpen    bool
 	FilePicker        filepicker.Model
-	HoverZone         string
+	// DetectedLogDirs are shortcuts shown above the file picker, most
+	// recently active first.
+	DetectedLogDirs []config.LogDirCandidate
+	HoverZone       string
 
 	SavedSettings config.UploaderSettings
 	DraftSettings config.UploaderSettings
@@ -87,6 +96,12 @@ func NewState(opts config.Options, defaultLogDir string) State {
 	inputs[tokenInputIndex].SetValue(strings.TrimSpace(opts.Token))
 	inputs[logDirInputIndex].Placeholder = defaultLogDir
 	inputs[logDirInputIndex].SetValue(strings.TrimSpace(opts.LogDir))
+	inputs[extraLogDirsInputIndex].Placeholder = "Optional, separated by " + string(os.PathListSeparator)
+	inputs[extraLogDirsInputIndex].SetValue(config.JoinLogDirs(opts.ExtraLogDirs))
+	inputs[charactersInputIndex].Placeholder = "Character IDs, e.g. 90000001=Scout, 90000002"
+	inputs[charactersInputIndex].SetValue(config.FormatCharacterList(opts.CharacterFilter.Characters))
+	inputs[remoteCommandsIndex].Placeholder = config.FormatRemoteCommandList(config.DefaultRemoteCommands()) + " (none disables)"
+	inputs[remoteCommandsIndex].SetValue(config.FormatRemoteCommandList(opts.RemoteCommands))
 	inputs[baseURLInputIndex].Focus()
 
 	picker := filepicker.New()
@@ -113,6 +128,7 @@ func NewState(opts config.Options, defaultLogDir string) State {
 		HelpView:      helpView,
 		Keys:          keyboard.New(),
 		AutoConn:      opts.AutoConnect,
+		CharMode:      config.NormalizeCharacterFilterMode(opts.CharacterFilter.Mode),
 		DebugOn:       opts.Debug,
 		FollowLogs:    true,
 		ImGay:         opts.ImGay,
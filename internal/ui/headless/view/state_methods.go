@@ -5,6 +5,7 @@ import (
 	"runtime"
 	"strings"
 
+	"sentinel2-uploader/internal/config"
 	"sentinel2-uploader/internal/ui/headless/theme"
 
 	"github.com/charmbracelet/lipgloss"
@@ -33,7 +34,16 @@ const (
 const (
 	overviewFocusCountWithoutLogs = 3
 	overviewFocusCountWithLogs    = 4
-	settingsExtraFocusSlots       = 4
+)
+
+// Settings controls that follow the text inputs, in focus order.
+const (
+	settingsBrowseSlot = iota
+	settingsAutoConnectSlot
+	settingsCharModeSlot
+	settingsSaveSlot
+	settingsCancelSlot
+	settingsExtraFocusSlots
 )
 
 const (
@@ -76,10 +86,11 @@ func (s State) FocusCount() int {
 func (s State) LogsIndex() int        { return logsControlIndex }
 func (s State) QuitIndex() int        { return quitControlIndex }
 func (s State) LogsDebugIndex() int   { return logsDebugControlIndex }
-func (s State) AutoConnectIndex() int { return len(s.Inputs) + logsControlIndex }
-func (s State) BrowseIndex() int      { return len(s.Inputs) }
-func (s State) SaveIndex() int        { return len(s.Inputs) + quitControlIndex }
-func (s State) CancelIndex() int      { return len(s.Inputs) + logsDebugControlIndex }
+func (s State) AutoConnectIndex() int { return len(s.Inputs) + settingsAutoConnectSlot }
+func (s State) BrowseIndex() int      { return len(s.Inputs) + settingsBrowseSlot }
+func (s State) CharModeIndex() int    { return len(s.Inputs) + settingsCharModeSlot }
+func (s State) SaveIndex() int        { return len(s.Inputs) + settingsSaveSlot }
+func (s State) CancelIndex() int      { return len(s.Inputs) + settingsCancelSlot }
 func (s State) ConnectIndex() int     { return connectControlIndex }
 
 func (s State) ContentWidth() int {
@@ -132,17 +143,36 @@ func (s *State) FitLogViewportHeight(nonLogSections []string, nonLogLayoutReserv
 }
 
 func (s *State) ResizeFilePicker() {
-	h := max(s.Height-filePickerHeightOffset, minFilePickerHeight)
+	h := max(s.Height-filePickerHeightOffset-s.detectedLogDirRows(), minFilePickerHeight)
 	s.FilePicker.SetHeight(h)
 }
 
+// MaxDetectedLogDirs caps the shortcuts in the file picker, which are picked
+// with the number keys.
+const MaxDetectedLogDirs = 9
+
+// detectedLogDirRows is the height of the shortcut list, including its
+// heading.
+func (s *State) detectedLogDirRows() int {
+	if len(s.DetectedLogDirs) == 0 {
+		return 0
+	}
+	return min(len(s.DetectedLogDirs), MaxDetectedLogDirs) + 1
+}
+
 func (s State) WithDraftFromControls() State {
 	s.DraftSettings.BaseURL = strings.TrimSpace(s.Inputs[0].Value())
 	s.DraftSettings.Token = strings.TrimSpace(s.Inputs[1].Value())
 	s.DraftSettings.LogDir = strings.TrimSpace(s.Inputs[2].Value())
+	s.DraftSettings.ExtraLogDirs = config.SplitLogDirs(s.Inputs[3].Value())
+	s.DraftSettings.CharacterFilter = config.CharacterFilter{
+		Mode:       s.CharMode,
+		Characters: config.ParseCharacterList(s.Inputs[4].Value()),
+	}
+	s.DraftSettings.RemoteCommands = config.ParseRemoteCommandList(s.Inputs[5].Value())
 	s.DraftSettings.AutoConnect = s.AutoConn
 	s.DraftSettings.Debug = s.DebugOn
-	s.SettingsDirty = s.DraftSettings != s.SavedSettings
+	s.SettingsDirty = !s.DraftSettings.Equal(s.SavedSettings)
 	return s
 }
 
@@ -150,6 +180,10 @@ func (s State) WithDraftAppliedToControls() State {
 	s.Inputs[0].SetValue(strings.TrimSpace(s.DraftSettings.BaseURL))
 	s.Inputs[1].SetValue(strings.TrimSpace(s.DraftSettings.Token))
 	s.Inputs[2].SetValue(strings.TrimSpace(s.DraftSettings.LogDir))
+	s.Inputs[3].SetValue(config.JoinLogDirs(s.DraftSettings.ExtraLogDirs))
+	s.Inputs[4].SetValue(config.FormatCharacterList(s.DraftSettings.CharacterFilter.Characters))
+	s.Inputs[5].SetValue(config.FormatRemoteCommandList(s.DraftSettings.RemoteCommands))
+	s.CharMode = s.DraftSettings.CharacterFilter.Mode
 	s.AutoConn = s.DraftSettings.AutoConnect
 	return s
 }
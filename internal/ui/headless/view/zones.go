@@ -13,6 +13,7 @@ const (
 
 	zoneSettingsBrowse      = "settings-browse"
 	zoneSettingsAutoConnect = "settings-auto-connect"
+	zoneSettingsCharMode    = "settings-character-mode"
 	zoneSettingsSave        = "settings-save"
 	zoneSettingsCancel      = "settings-cancel"
 
@@ -8,6 +8,7 @@ import (
 	"os/signal"
 	"syscall"
 
+	"sentinel2-uploader/internal/cli"
 	"sentinel2-uploader/internal/config"
 	"sentinel2-uploader/internal/ui/gui"
 	"sentinel2-uploader/internal/ui/headless"
@@ -21,7 +22,7 @@ func main() {
 	rootCtx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stopSignals()
 
-	opts, err := config.ParseOptions(nil)
+	commandLine, err := config.ParseCommandLine(nil)
 	if err != nil {
 		var flagErr *flags.Error
 		if errors.As(err, &flagErr) && flagErr.Type == flags.ErrHelp {
@@ -30,6 +31,30 @@ func main() {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(2)
 	}
+	opts := commandLine.Options
+	switch commandLine.Command {
+	case config.CommandCheck:
+		os.Exit(cli.Check(rootCtx, opts, commandLine.JSON(), os.Stdout))
+	case config.CommandChannels:
+		os.Exit(cli.Channels(rootCtx, opts, commandLine.JSON(), os.Stdout, os.Stderr))
+	case config.CommandConfigGet:
+		os.Exit(cli.ConfigGet(commandLine.ConfigGet.Args.Key, commandLine.ConfigGet.ShowSecrets, commandLine.JSON(), os.Stdout, os.Stderr))
+	case config.CommandConfigSet:
+		os.Exit(cli.ConfigSet(commandLine.ConfigSet.Args.Key, commandLine.ConfigSet.Args.Value, commandLine.JSON(), os.Stdout, os.Stderr))
+	case config.CommandReplay:
+		// Replay does not take the instance lock so it can run alongside
+		// the uploader.
+		os.Exit(cli.Replay(rootCtx, opts, commandLine.Replay, os.Stdout, os.Stderr))
+	}
+
+	// Deferred before the lock is taken so the exit happens after every
+	// release below.
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
 
 	lock, lockedByOther, lockErr := acquireInstanceLock()
 	if lockErr != nil {
@@ -37,7 +62,7 @@ func main() {
 		os.Exit(2)
 	}
 	if lockedByOther {
-		if !gui.Available() || opts.Headless {
+		if !gui.Available() || opts.Headless || opts.Daemon || commandLine.Run.JSON {
 			fmt.Fprintln(os.Stderr, "Sentinel2 Uploader is already running.")
 		} else {
 			hideAndDetachConsoleForGUI()
@@ -49,6 +74,29 @@ func main() {
 		_ = lock.Release()
 	}()
 
+	if opts.MockServer != "" {
+		mockOpts, stopMock, mockErr := startMockServer(opts)
+		if mockErr != nil {
+			fmt.Fprintln(os.Stderr, "failed to start mock server:", mockErr)
+			os.Exit(2)
+		}
+		defer stopMock()
+		opts = mockOpts
+	}
+
+	if opts.Daemon || commandLine.Run.JSON {
+		reload := make(chan os.Signal, 1)
+		signal.Notify(reload, syscall.SIGHUP)
+		defer signal.Stop(reload)
+		daemon := cli.DaemonOptions{Stdout: os.Stdout, Stderr: os.Stderr, Reload: reload}
+		if !opts.Daemon {
+			// run --json keeps every event on stdout.
+			daemon.Stderr = os.Stdout
+		}
+		exitCode = cli.Daemon(rootCtx, opts, daemon)
+		return
+	}
+
 	// Headless-tag builds always run headless; runtime UI selection is ignored.
 	if !gui.Available() {
 		headless.Run(rootCtx, BuildVersion, opts)
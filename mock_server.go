@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"sentinel2-uploader/internal/client"
+	"sentinel2-uploader/internal/config"
+	"sentinel2-uploader/internal/mockserver"
+)
+
+const mockUploaderToken = "mock-uploader-token"
+
+// startMockServer serves the mock Sentinel backend on opts.MockServer and
+// returns opts pointed at it, so the UIs can be demoed without a real server.
+func startMockServer(opts config.Options) (config.Options, func(), error) {
+	server, err := mockserver.Listen(opts.MockServer, mockserver.Options{
+		UploaderToken: mockUploaderToken,
+		Channels: []client.ChannelConfig{
+			{ID: "mock-intel", Name: "Intel"},
+			{ID: "mock-corp", Name: "Corp"},
+		},
+		KeepaliveInterval: 30 * time.Second,
+	})
+	if err != nil {
+		return opts, nil, err
+	}
+	fmt.Fprintf(os.Stderr, "mock Sentinel server listening on %s\n", server.URL)
+	opts.BaseURL = server.URL
+	opts.Token = mockUploaderToken
+	return opts, server.Close, nil
+}